@@ -0,0 +1,29 @@
+package monitor
+
+import (
+	"strings"
+	"time"
+)
+
+// AlertEntry describes a single threshold breach found during a monitoring cycle
+type AlertEntry struct {
+	Metric    string  `json:"metric"` // e.g. cpu_usage, memory, disk
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Message   string  `json:"message"`
+	Unit      string  `json:"unit,omitempty"` // unit of Value and Threshold, e.g. °F; set by runChecks
+
+	Timestamp time.Time `json:"timestamp"`          // when the breach was found; set by runChecks
+	Severity  Severity  `json:"severity,omitempty"` // set by runChecks from time_based_priority; empty is warning
+	Immediate bool      `json:"-"`                  // sent right away instead of waiting for the alert group window
+}
+
+// FormatAlertMessage joins the alert messages into an email body, one alert per line
+func FormatAlertMessage(alerts []AlertEntry) string {
+	var b strings.Builder
+	for _, alert := range alerts {
+		b.WriteString(alert.Message)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultAlertTemplate renders the built-in alert message unchanged
+const defaultAlertTemplate = "{{.Message}}"
+
+// metricUnits holds the unit of the alert values of each metric. Temperatures are in the
+// configured temperature_unit instead of °C.
+var metricUnits = map[string]string{
+	"cpu_temperature":     "°C",
+	"ambient_temperature": "°C",
+	"gpu_temperature":     "°C",
+	"gpio_temperature":    "°C",
+	"thermal_sensors":     "°C",
+	"fan_speed":           "RPM",
+	"cpu_clock":           "GHz",
+	"cpu_usage":           "%",
+	"cpu_power":           "W",
+	"cpu_throttle":        "events/s",
+	"edac":                "errors/h",
+	"memory":              "%",
+	"ksm_saved":           "MiB",
+	"memory_bandwidth":    "GB/s",
+	"hugepages":           "%",
+	"disk":                "%",
+	"tmpfs":               "%",
+	"disk_latency":        "ms",
+	"file_size":           "MiB",
+	"disk_quota":          "MiB",
+	"dns":                 "ms",
+	"nic":                 "packets/s",
+	"cilium":              "packets/s",
+	"cron":                "s",
+}
+
+// AlertTemplateData is the data an alert_template is rendered with
+type AlertTemplateData struct {
+	Metric    string
+	Value     float64
+	Unit      string
+	Threshold float64
+	Hostname  string
+	Time      time.Time
+	Severity  string
+	Message   string // the built-in alert message
+}
+
+// RenderAlertMessage renders entry with the Go template tmpl, e.g.
+//
+//	[{{.Severity}}] {{.Hostname}}: {{.Metric}} is {{printf "%.1f" .Value}}{{.Unit}} (limit {{.Threshold}}{{.Unit}})
+//
+// An empty template renders the built-in message.
+func RenderAlertMessage(tmpl string, entry AlertEntry) (string, error) {
+	if tmpl == "" {
+		tmpl = defaultAlertTemplate
+	}
+	t, err := template.New("alert").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("Error parsing alert_template: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	unit := entry.Unit
+	if unit == "" {
+		unit = alertUnit(entry.Metric, Celsius)
+	}
+	data := AlertTemplateData{
+		Metric:    entry.Metric,
+		Value:     entry.Value,
+		Unit:      unit,
+		Threshold: entry.Threshold,
+		Hostname:  hostname,
+		Time:      entry.Timestamp,
+		Severity:  string(alertSeverity(entry)),
+		Message:   entry.Message,
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("Error rendering alert_template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// alertUnit returns the unit of the alert values of metric, with temperatures in tempUnit
+func alertUnit(metric string, tempUnit TempUnit) string {
+	name, _, _ := strings.Cut(metric, ".")
+	unit := metricUnits[name]
+	if unit == "°C" {
+		unit = tempUnit.Symbol()
+	}
+	return unit
+}
+
+// validateAlertTemplate checks that the alert_template parses
+func validateAlertTemplate(tmpl string) error {
+	if _, err := template.New("alert").Parse(tmpl); err != nil {
+		return fmt.Errorf("invalid alert_template: %w", err)
+	}
+	return nil
+}
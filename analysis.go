@@ -0,0 +1,192 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	correlationWindow     = 10 * time.Minute // history used by AnalyzeCorrelation
+	minCorrelation        = 0.7              // Pearson coefficient from which two metrics are reported as related
+	minCorrelationSamples = 3
+)
+
+// metricDisplayNames are the names used in the correlation narrative
+var metricDisplayNames = map[string]string{
+	"cpu_temperature":  "CPU temperature",
+	"cpu_usage":        "CPU usage",
+	"cpu_power":        "CPU power draw",
+	"cpu_throttle":     "CPU throttling",
+	"memory":           "memory pressure",
+	"memory_bandwidth": "memory bandwidth",
+	"disk":             "disk usage",
+}
+
+// correlationHints suggest a likely cause for pairs of related metrics, keyed by the sorted metric names
+var correlationHints = map[[2]string]string{
+	{"cpu_usage", "memory"}:             "possible runaway process",
+	{"disk", "memory"}:                  "possible swapping or runaway logging",
+	{"cpu_temperature", "cpu_usage"}:    "sustained load is heating the CPU",
+	{"cpu_temperature", "cpu_throttle"}: "the CPU is throttling to shed heat",
+}
+
+// metricHistory is the recent history of one alerting metric, reduced to one value per collection cycle
+type metricHistory struct {
+	metric string
+	unit   string
+	values map[time.Time]float64 // highest value across instances (e.g. cores) per cycle
+	peak   float64
+	onset  time.Time // start of the current breach of the alert threshold
+}
+
+// AnalyzeCorrelation describes how the alerting metrics moved together during the last 10 minutes,
+// e.g. "CPU usage spike to 94.00% preceded memory pressure by 12 seconds (correlation 0.91) — possible
+// runaway process". It returns an empty string with fewer than two alerting metrics or without history.
+func AnalyzeCorrelation(alerts []AlertEntry, history *MetricStore) string {
+	if history == nil {
+		return ""
+	}
+
+	// Only threshold alerts can be compared; availability alerts have no threshold
+	var metrics []string
+	thresholds := make(map[string]float64)
+	for _, alert := range alerts {
+		if _, seen := thresholds[alert.Metric]; seen || alert.Threshold == 0 {
+			continue
+		}
+		thresholds[alert.Metric] = alert.Threshold
+		metrics = append(metrics, alert.Metric)
+	}
+	if len(metrics) < 2 {
+		return ""
+	}
+
+	now := time.Now()
+	var histories []metricHistory
+	for _, metric := range metrics {
+		samples, err := history.QueryMetric(metric, now.Add(-correlationWindow), now.Add(time.Millisecond))
+		if err != nil {
+			log.Printf("Error reading metric history: %v\n", err)
+			return ""
+		}
+		if len(samples) > 0 {
+			histories = append(histories, newMetricHistory(metric, samples, thresholds[metric]))
+		}
+	}
+
+	var findings []string
+	for i := 0; i < len(histories); i++ {
+		for j := i + 1; j < len(histories); j++ {
+			if finding := describeCorrelation(histories[i], histories[j]); finding != "" {
+				findings = append(findings, finding)
+			}
+		}
+	}
+	if len(findings) == 0 {
+		return ""
+	}
+	return "Correlation analysis (last 10 minutes):\n" + strings.Join(findings, "\n") + "\n"
+}
+
+// newMetricHistory reduces the samples to one value per cycle and finds when the current breach started
+func newMetricHistory(metric string, samples []MetricSample, threshold float64) metricHistory {
+	h := metricHistory{metric: metric, unit: samples[0].Unit, values: make(map[time.Time]float64), peak: math.Inf(-1)}
+	for _, sample := range samples {
+		if current, ok := h.values[sample.Timestamp]; !ok || sample.Value > current {
+			h.values[sample.Timestamp] = sample.Value
+		}
+		h.peak = math.Max(h.peak, sample.Value)
+	}
+
+	timestamps := h.timestamps()
+	h.onset = timestamps[len(timestamps)-1]
+	for i := len(timestamps) - 1; i >= 0 && h.values[timestamps[i]] > threshold; i-- {
+		h.onset = timestamps[i]
+	}
+	return h
+}
+
+// timestamps returns the collection times of the history, oldest first
+func (h metricHistory) timestamps() []time.Time {
+	timestamps := make([]time.Time, 0, len(h.values))
+	for t := range h.values {
+		timestamps = append(timestamps, t)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i].Before(timestamps[j]) })
+	return timestamps
+}
+
+// displayName returns the narrative name of the metric
+func (h metricHistory) displayName() string {
+	if name, ok := metricDisplayNames[h.metric]; ok {
+		return name
+	}
+	return strings.ReplaceAll(h.metric, "_", " ")
+}
+
+// describeCorrelation returns a sentence on how a and b are related, or "" if they are not
+func describeCorrelation(a, b metricHistory) string {
+	var xs, ys []float64
+	for _, t := range a.timestamps() {
+		if y, ok := b.values[t]; ok {
+			xs = append(xs, a.values[t])
+			ys = append(ys, y)
+		}
+	}
+	if len(xs) < minCorrelationSamples {
+		return ""
+	}
+	r, ok := pearson(xs, ys)
+	if !ok || r < minCorrelation {
+		return ""
+	}
+
+	first, second := a, b
+	if b.onset.Before(a.onset) {
+		first, second = b, a
+	}
+	var finding string
+	if lead := second.onset.Sub(first.onset); lead > 0 {
+		finding = fmt.Sprintf("%s spike to %.2f%s preceded %s by %d seconds (correlation %.2f)",
+			first.displayName(), first.peak, first.unit, second.displayName(), int(lead.Seconds()), r)
+	} else {
+		finding = fmt.Sprintf("%s spike to %.2f%s rose together with %s (correlation %.2f)",
+			first.displayName(), first.peak, first.unit, second.displayName(), r)
+	}
+
+	key := [2]string{a.metric, b.metric}
+	if key[0] > key[1] {
+		key[0], key[1] = key[1], key[0]
+	}
+	if hint, ok := correlationHints[key]; ok {
+		finding += " — " + hint
+	}
+	return finding
+}
+
+// pearson returns the Pearson correlation coefficient of xs and ys; ok is false if either series is constant
+func pearson(xs, ys []float64) (r float64, ok bool) {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varX*varY), true
+}
@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ApacheCheck is a single entry of the apache_checks config
+type ApacheCheck struct {
+	StatusURL            string  `json:"status_url"`             // e.g. http://web1/server-status
+	MaxWorkerUtilization float64 `json:"max_worker_utilization"` // busy share of all workers, e.g. 0.9
+}
+
+// ApacheStat holds the values of the mod_status page
+type ApacheStat struct {
+	URL           string  `json:"url"`
+	TotalAccesses int64   `json:"total_accesses"` // cumulative since httpd started
+	TotalKBytes   int64   `json:"total_kbytes"`
+	BusyWorkers   int     `json:"busy_workers"`
+	IdleWorkers   int     `json:"idle_workers"`
+	CPULoad       float64 `json:"cpu_load"` // percent of one CPU
+}
+
+// WorkerUtilization returns the share of busy workers, between 0 and 1
+func (s ApacheStat) WorkerUtilization() float64 {
+	if s.BusyWorkers+s.IdleWorkers == 0 {
+		return 0
+	}
+	return float64(s.BusyWorkers) / float64(s.BusyWorkers+s.IdleWorkers)
+}
+
+// CheckApacheStatus fetches and parses the machine-readable mod_status page ({url}?auto)
+func CheckApacheStatus(ctx context.Context, cfg ApacheCheck) (ApacheStat, error) {
+	statusURL, err := url.Parse(cfg.StatusURL)
+	if err != nil {
+		return ApacheStat{}, fmt.Errorf("invalid Apache status URL %q: %w", cfg.StatusURL, err)
+	}
+	statusURL.RawQuery = "auto"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL.String(), nil)
+	if err != nil {
+		return ApacheStat{}, fmt.Errorf("Error creating Apache request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent())
+
+	client := &http.Client{Timeout: serviceCheckTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ApacheStat{}, fmt.Errorf("Error fetching Apache status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ApacheStat{}, fmt.Errorf("Apache returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ApacheStat{}, fmt.Errorf("Error reading Apache status: %w", err)
+	}
+
+	stat, err := parseApacheStatus(string(body))
+	stat.URL = cfg.StatusURL
+	return stat, err
+}
+
+// parseApacheStatus parses the "key: value" lines of the mod_status page:
+//
+//	Total Accesses: 131
+//	Total kBytes: 138
+//	CPULoad: .0104
+//	BusyWorkers: 1
+//	IdleWorkers: 74
+//
+// Total Accesses, Total kBytes and CPULoad require ExtendedStatus and may be missing.
+func parseApacheStatus(page string) (ApacheStat, error) {
+	var stat ApacheStat
+	var haveBusy, haveIdle bool
+	scanner := bufio.NewScanner(strings.NewReader(page))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "Total Accesses":
+			stat.TotalAccesses, err = strconv.ParseInt(value, 10, 64)
+		case "Total kBytes":
+			stat.TotalKBytes, err = strconv.ParseInt(value, 10, 64)
+		case "CPULoad":
+			stat.CPULoad, err = strconv.ParseFloat(value, 64)
+		case "BusyWorkers":
+			stat.BusyWorkers, err = strconv.Atoi(value)
+			haveBusy = true
+		case "IdleWorkers":
+			stat.IdleWorkers, err = strconv.Atoi(value)
+			haveIdle = true
+		}
+		if err != nil {
+			return stat, fmt.Errorf("could not parse Apache %s: %w", key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return stat, fmt.Errorf("could not parse Apache status: %w", err)
+	}
+	if !haveBusy || !haveIdle {
+		return stat, fmt.Errorf("could not parse Apache status: BusyWorkers or IdleWorkers missing")
+	}
+	return stat, nil
+}
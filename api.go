@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// StartAPIServer serves the latest metric snapshot as JSON on GET /metrics and as an
+// HTML page on GET /status, and accepts cron job heartbeats on POST /heartbeat/{name}.
+// The GET /livez and /readyz probes are served without authentication.
+func StartAPIServer(config Config, snapshot *SafeSnapshot) error {
+	cronJobs := make(map[string]bool)
+	for _, check := range config.CronChecks {
+		cronJobs[check.Name] = true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		snap := snapshot.Get()
+		if snap.Timestamp.IsZero() {
+			http.Error(w, "no metrics collected yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(snap)
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := WriteStatusPage(w, snapshot.Get()); err != nil {
+			log.Printf("Error rendering status page: %v\n", err)
+		}
+	})
+	mux.HandleFunc("POST /heartbeat/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if !cronJobs[name] {
+			http.Error(w, "unknown cron check", http.StatusNotFound)
+			return
+		}
+		apiHeartbeats.Record(name, time.Now())
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// Orchestrators probe without credentials
+	probes := http.NewServeMux()
+	probes.HandleFunc("GET /livez", func(w http.ResponseWriter, r *http.Request) {
+		writeProbe(w, http.StatusOK, probeStatus{Status: "ok"})
+	})
+	probes.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if reason := notReadyReason(config, snapshot.Get(), time.Now()); reason != "" {
+			writeProbe(w, http.StatusServiceUnavailable, probeStatus{Status: "not ready", Reason: reason})
+			return
+		}
+		writeProbe(w, http.StatusOK, probeStatus{Status: "ready"})
+	})
+	probes.Handle("/", requireAuth(config, mux))
+
+	return http.ListenAndServe(config.APIAddr, probes)
+}
+
+// probeStatus is the body of the /livez and /readyz responses
+type probeStatus struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// writeProbe writes a probe response
+func writeProbe(w http.ResponseWriter, code int, status probeStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(status)
+}
+
+// notReadyReason returns why the monitor is not ready, or "" when it is. The monitor is ready
+// once a collection cycle completed, and stays ready as long as the last one is no older than
+// twice the collection_interval.
+func notReadyReason(config Config, snap MetricSnapshot, now time.Time) string {
+	if snap.Timestamp.IsZero() {
+		return "no_collection_yet"
+	}
+	interval := time.Duration(config.CollectionInterval) * time.Second
+	if interval > 0 && now.Sub(snap.Timestamp) > 2*interval {
+		return "last_collection_stale"
+	}
+	return ""
+}
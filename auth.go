@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"crypto/subtle"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Failed API logins allowed per client IP within authFailureWindow
+const (
+	maxAuthFailures   = 3
+	authFailureWindow = 1 * time.Minute
+)
+
+// authLimiter tracks failed logins per client IP
+type authLimiter struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	prunedAt time.Time
+}
+
+// blocked reports whether ip has used up its failed attempts. Once per window it also drops
+// the expired failures of every other IP, so clients that never return don't pile up.
+func (l *authLimiter) blocked(ip string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.prunedAt) >= authFailureWindow {
+		for other := range l.failures {
+			l.prune(other, now)
+		}
+		l.prunedAt = now
+	}
+	return l.prune(ip, now) >= maxAuthFailures
+}
+
+// prune drops the failures of ip older than authFailureWindow and returns how many are left
+func (l *authLimiter) prune(ip string, now time.Time) int {
+	recent := l.failures[ip][:0]
+	for _, at := range l.failures[ip] {
+		if now.Sub(at) < authFailureWindow {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) == 0 {
+		delete(l.failures, ip)
+		return 0
+	}
+	l.failures[ip] = recent
+	return len(recent)
+}
+
+// fail records a failed login of ip
+func (l *authLimiter) fail(ip string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures[ip] = append(l.failures[ip], now)
+}
+
+// requireAuth protects next with HTTP basic auth. Credentials are checked against
+// the static api_username/api_password and then against LDAP, whichever are configured.
+// Without any configured credentials the API stays open.
+func requireAuth(config Config, next http.Handler) http.Handler {
+	if config.APIUsername == "" && config.LDAP == nil {
+		return next
+	}
+
+	limiter := &authLimiter{failures: make(map[string][]time.Time)}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		now := time.Now()
+
+		if limiter.blocked(ip, now) {
+			http.Error(w, "too many failed login attempts", http.StatusTooManyRequests)
+			return
+		}
+
+		username, password, ok := r.BasicAuth()
+		if ok && checkAPICredentials(config, username, password) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// A request without credentials is how browsers ask for the login prompt, not a failed login
+		if ok {
+			limiter.fail(ip, now)
+			log.Printf("Failed API login for user %q from %s\n", username, ip)
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="go-system-monitor"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// checkAPICredentials verifies a username and password against the configured credential sources
+func checkAPICredentials(config Config, username, password string) bool {
+	if config.APIUsername != "" &&
+		subtle.ConstantTimeCompare([]byte(username), []byte(config.APIUsername)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(config.APIPassword)) == 1 {
+		return true
+	}
+
+	if config.LDAP != nil {
+		ok, err := AuthenticateLDAP(*config.LDAP, username, password)
+		if err != nil {
+			log.Printf("Error authenticating API user %q with LDAP: %v\n", username, err)
+			return false
+		}
+		return ok
+	}
+	return false
+}
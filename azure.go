@@ -0,0 +1,172 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+const (
+	azureMonitorScope      = "https://monitoring.azure.com/.default"
+	azureMetricNamespace   = "go-system-monitor"
+	azureDefaultType       = "Microsoft.Compute/virtualMachines"
+	azureTokenExpiryMargin = 5 * time.Minute
+)
+
+// AzureMonitorConfig holds the Azure resource metrics are attached to and the
+// service principal used to publish them
+type AzureMonitorConfig struct {
+	SubscriptionID string `json:"subscription_id"`
+	ResourceGroup  string `json:"resource_group"`
+	ResourceName   string `json:"resource_name"`
+	ResourceType   string `json:"resource_type"` // defaults to Microsoft.Compute/virtualMachines
+	Region         string `json:"region"`        // region of the resource, e.g. westeurope
+
+	TenantID     string `json:"tenant_id"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// resourceID returns the ARM resource ID of the monitored resource
+func (c AzureMonitorConfig) resourceID() string {
+	resourceType := c.ResourceType
+	if resourceType == "" {
+		resourceType = azureDefaultType
+	}
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s",
+		c.SubscriptionID, c.ResourceGroup, resourceType, c.ResourceName)
+}
+
+// azureTokens caches access tokens per service principal between collection cycles
+var azureTokens = struct {
+	sync.Mutex
+	tokens map[string]azcore.AccessToken
+}{tokens: make(map[string]azcore.AccessToken)}
+
+// azureToken returns a cached access token for the monitoring endpoint, requesting
+// a new one when it is about to expire or when refresh is set
+func azureToken(ctx context.Context, cfg AzureMonitorConfig, refresh bool) (string, error) {
+	key := cfg.TenantID + "/" + cfg.ClientID
+
+	azureTokens.Lock()
+	defer azureTokens.Unlock()
+	if token, ok := azureTokens.tokens[key]; ok && !refresh && time.Until(token.ExpiresOn) > azureTokenExpiryMargin {
+		return token.Token, nil
+	}
+
+	// A new credential does not share the token cache of the previous one
+	credential, err := azidentity.NewClientSecretCredential(cfg.TenantID, cfg.ClientID, cfg.ClientSecret, nil)
+	if err != nil {
+		return "", fmt.Errorf("Error creating Azure credential: %w", err)
+	}
+	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureMonitorScope}})
+	if err != nil {
+		return "", fmt.Errorf("Error fetching Azure access token: %w", err)
+	}
+	azureTokens.tokens[key] = token
+	return token.Token, nil
+}
+
+// azureMetricBody is a custom metric in the Azure Monitor ingestion format
+type azureMetricBody struct {
+	Time time.Time `json:"time"`
+	Data struct {
+		BaseData struct {
+			Metric    string        `json:"metric"`
+			Namespace string        `json:"namespace"`
+			DimNames  []string      `json:"dimNames,omitempty"`
+			Series    []azureSeries `json:"series"`
+		} `json:"baseData"`
+	} `json:"data"`
+}
+
+type azureSeries struct {
+	DimValues []string `json:"dimValues,omitempty"`
+	Min       float64  `json:"min"`
+	Max       float64  `json:"max"`
+	Sum       float64  `json:"sum"`
+	Count     int      `json:"count"`
+}
+
+// WriteToAzureMonitor publishes the metrics as custom metrics of the configured resource.
+// Metrics with an instance label are sent as one series per instance.
+func WriteToAzureMonitor(ctx context.Context, cfg AzureMonitorConfig, metrics []MetricPoint) error {
+	byName := make(map[string][]MetricPoint)
+	for _, metric := range metrics {
+		byName[metric.Name] = append(byName[metric.Name], metric)
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	url := fmt.Sprintf("https://%s.monitoring.azure.com%s/metrics", cfg.Region, cfg.resourceID())
+	for _, name := range names {
+		var body azureMetricBody
+		body.Time = byName[name][0].Timestamp.UTC()
+		body.Data.BaseData.Metric = name
+		body.Data.BaseData.Namespace = azureMetricNamespace
+		for _, metric := range byName[name] {
+			series := azureSeries{Min: metric.Value, Max: metric.Value, Sum: metric.Value, Count: 1}
+			if instance, ok := metric.Labels["instance"]; ok {
+				body.Data.BaseData.DimNames = []string{"instance"}
+				series.DimValues = []string{instance}
+			}
+			body.Data.BaseData.Series = append(body.Data.BaseData.Series, series)
+		}
+
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("Error encoding Azure Monitor metric: %w", err)
+		}
+		if err := postAzureMetric(ctx, cfg, url, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postAzureMetric sends a single metric, retrying once with a new token when the current one is rejected
+func postAzureMetric(ctx context.Context, cfg AzureMonitorConfig, url string, payload []byte) error {
+	client := &http.Client{Timeout: serviceCheckTimeout}
+	for attempt := 0; ; attempt++ {
+		token, err := azureToken(ctx, cfg, attempt > 0)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("Error creating Azure Monitor request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent())
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("Error sending metrics to Azure Monitor: %w", err)
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized && attempt == 0:
+			continue
+		case resp.StatusCode >= 300:
+			return fmt.Errorf("Azure Monitor returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collectAllTarget is the expected upper bound of a full parallel collection on a typical server
+const collectAllTarget = 100 * time.Millisecond
+
+func BenchmarkGetCPUUsage(b *testing.B) {
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetCPUUsage(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetMemoryStats(b *testing.B) {
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetMemoryStats(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetDiskUsage(b *testing.B) {
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetDiskUsage(ctx, "/"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCollectAll runs the gopsutil-based collectors concurrently, the way
+// independently scheduled metrics are collected by the MetricScheduler
+func BenchmarkCollectAll(b *testing.B) {
+	ctx := context.Background()
+	collectors := []func() error{
+		func() error { _, err := GetCPUUsage(ctx); return err },
+		func() error { _, err := GetMemoryStats(ctx); return err },
+		func() error { _, err := GetDiskUsage(ctx, "/"); return err },
+	}
+
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		errs := make([]error, len(collectors))
+		for j, collect := range collectors {
+			wg.Add(1)
+			go func(j int, collect func() error) {
+				defer wg.Done()
+				errs[j] = collect()
+			}(j, collect)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	if perOp := time.Since(start) / time.Duration(b.N); perOp > collectAllTarget {
+		b.Logf("full collection took %v, above the %v target", perOp, collectAllTarget)
+	}
+}
@@ -0,0 +1,126 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"sync"
+)
+
+// opsGenieAccountURL returns the account of the API key, which makes it a cheap credential check
+const opsGenieAccountURL = "https://api.opsgenie.com/v2/account"
+
+// ValidateAlertChannels checks concurrently that every configured alert channel is reachable.
+// It returns one error per failed channel; channels may only be temporarily unavailable,
+// so the caller should warn rather than abort.
+func ValidateAlertChannels(ctx context.Context, cfg Config) []error {
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+
+	checks := alertChannelChecks(cfg)
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) error) {
+			defer wg.Done()
+			if err := check(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s alert channel: %w", name, err))
+				mu.Unlock()
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return errs
+}
+
+// alertChannelChecks returns the reachability check of every configured alert channel by name.
+// None of the checks sends a notification.
+func alertChannelChecks(cfg Config) map[string]func(context.Context) error {
+	checks := map[string]func(context.Context) error{
+		"SMTP": func(ctx context.Context) error { return validateSMTPChannel(ctx, cfg.SMTPConfig) },
+	}
+	if cfg.Syslog != nil && cfg.Syslog.Network != "" {
+		checks["syslog"] = func(ctx context.Context) error { return validateSyslogChannel(ctx, *cfg.Syslog) }
+	}
+	if cfg.OpsGenie != nil {
+		checks["OpsGenie"] = func(ctx context.Context) error { return validateOpsGenieChannel(ctx, *cfg.OpsGenie) }
+	}
+	if cfg.Mattermost != nil {
+		checks["Mattermost"] = func(ctx context.Context) error { return validateMattermostChannel(*cfg.Mattermost) }
+	}
+	return checks
+}
+
+// validateSMTPChannel connects to the SMTP server and says hello, without authenticating
+func validateSMTPChannel(ctx context.Context, cfg SMTPConfig) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort))
+	if err != nil {
+		return fmt.Errorf("Error connecting to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("Error connecting to SMTP server: %w", err)
+	}
+	defer client.Close()
+	if err := client.Hello("localhost"); err != nil {
+		return fmt.Errorf("SMTP server rejected HELO: %w", err)
+	}
+	return client.Quit()
+}
+
+// validateSyslogChannel connects to the remote syslog server. UDP only resolves the address.
+func validateSyslogChannel(ctx context.Context, cfg SyslogConfig) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, cfg.Network, cfg.Address)
+	if err != nil {
+		return fmt.Errorf("Error connecting to syslog server: %w", err)
+	}
+	return conn.Close()
+}
+
+// validateOpsGenieChannel checks the API key against the OpsGenie account API
+func validateOpsGenieChannel(ctx context.Context, cfg OpsGenieConfig) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opsGenieAccountURL, nil)
+	if err != nil {
+		return fmt.Errorf("Error creating OpsGenie request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent())
+	req.Header.Set("Authorization", "GenieKey "+cfg.APIKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Error connecting to OpsGenie: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("OpsGenie returned %s", resp.Status)
+	}
+	return nil
+}
+
+// validateMattermostChannel checks the webhook URL. The webhook is not called, since every
+// request to it posts a message.
+func validateMattermostChannel(cfg MattermostConfig) error {
+	u, err := url.Parse(cfg.WebhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid Mattermost webhook_url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid Mattermost webhook_url %q: must be an http or https URL", cfg.WebhookURL)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ciliumMetricsURL   = "http://localhost:9090/metrics" // Prometheus endpoint of the local Cilium agent
+	ciliumDropMetric   = "cilium_drop_count_total"
+	ciliumRecentFlows  = 5 // dropped flows asked from Hubble for the addresses in alerts
+	ciliumErrorBodyLen = 512
+)
+
+// CiliumDropCount is the number of packets the agent dropped for one reason and direction
+type CiliumDropCount struct {
+	Reason    string  `json:"reason"`    // e.g. Policy denied
+	Direction string  `json:"direction"` // INGRESS or EGRESS
+	Count     float64 `json:"count"`
+}
+
+// CiliumDropStat holds the drop counters of the Cilium agent since it started
+type CiliumDropStat struct {
+	Drops     []CiliumDropCount `json:"drops"`
+	SampledAt time.Time         `json:"-"` // when the counters were read, for rates between samples
+}
+
+// Total returns the packets dropped for any reason
+func (s CiliumDropStat) Total() float64 {
+	var total float64
+	for _, drop := range s.Drops {
+		total += drop.Count
+	}
+	return total
+}
+
+// CiliumDropRate is the drop rate of one reason and direction between two samples
+type CiliumDropRate struct {
+	Reason      string
+	Direction   string
+	DropsPerSec float64
+}
+
+// GetCiliumDropStats reads the cilium_drop_count_total counters from the Prometheus metrics
+// of the Cilium agent on this node
+func GetCiliumDropStats() (CiliumDropStat, error) {
+	stat := CiliumDropStat{SampledAt: time.Now()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serviceCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ciliumMetricsURL, nil)
+	if err != nil {
+		return stat, fmt.Errorf("Error creating Cilium metrics request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return stat, fmt.Errorf("Error fetching Cilium metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, ciliumErrorBodyLen))
+		return stat, fmt.Errorf("Cilium agent returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	stat.Drops, err = parseCiliumDrops(resp.Body)
+	if err != nil {
+		return stat, fmt.Errorf("Error parsing Cilium metrics: %w", err)
+	}
+	return stat, nil
+}
+
+// parseCiliumDrops extracts the cilium_drop_count_total samples from the Prometheus text format:
+//
+//	cilium_drop_count_total{direction="INGRESS",reason="Policy denied"} 42
+func parseCiliumDrops(r io.Reader) ([]CiliumDropCount, error) {
+	var drops []CiliumDropCount
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		rest, ok := strings.CutPrefix(line, ciliumDropMetric)
+		if !ok || (rest != "" && rest[0] != '{' && rest[0] != ' ') {
+			continue
+		}
+
+		var labels map[string]string
+		if strings.HasPrefix(rest, "{") {
+			end := strings.LastIndex(rest, "}")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated labels in %q", line)
+			}
+			var err error
+			labels, err = parsePromLabels(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("%w in %q", err, line)
+			}
+			rest = rest[end+1:]
+		}
+
+		// The value may be followed by a timestamp
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("missing value in %q", line)
+		}
+		count, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in %q: %w", line, err)
+		}
+		drops = append(drops, CiliumDropCount{Reason: labels["reason"], Direction: labels["direction"], Count: count})
+	}
+	return drops, scanner.Err()
+}
+
+// parsePromLabels parses the label pairs between the braces of a Prometheus sample,
+// e.g. direction="INGRESS",reason="Policy denied"
+func parsePromLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	s = strings.TrimSpace(s)
+	for s != "" {
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok || !strings.HasPrefix(rest, `"`) {
+			return nil, fmt.Errorf("invalid label %q", s)
+		}
+		// Find the closing quote, skipping escaped characters
+		end := 1
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(rest) {
+			return nil, fmt.Errorf("unterminated label value %q", rest)
+		}
+		value, err := strconv.Unquote(rest[:end+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid label value %q", rest[:end+1])
+		}
+		labels[strings.TrimSpace(name)] = value
+		s = strings.TrimSpace(rest[end+1:])
+		s = strings.TrimSpace(strings.TrimPrefix(s, ","))
+	}
+	return labels, nil
+}
+
+// CiliumDropRates returns the drop rate of every reason and direction that dropped packets
+// between prev and cur, highest first. Counters that went down, e.g. after an agent restart,
+// are skipped.
+func CiliumDropRates(prev, cur CiliumDropStat) []CiliumDropRate {
+	seconds := cur.SampledAt.Sub(prev.SampledAt).Seconds()
+	if seconds <= 0 {
+		return nil
+	}
+	previous := make(map[[2]string]float64, len(prev.Drops))
+	for _, drop := range prev.Drops {
+		previous[[2]string{drop.Reason, drop.Direction}] += drop.Count
+	}
+
+	var rates []CiliumDropRate
+	for _, drop := range cur.Drops {
+		delta := drop.Count - previous[[2]string{drop.Reason, drop.Direction}]
+		if delta <= 0 {
+			continue
+		}
+		rates = append(rates, CiliumDropRate{Reason: drop.Reason, Direction: drop.Direction, DropsPerSec: delta / seconds})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].DropsPerSec > rates[j].DropsPerSec })
+	return rates
+}
+
+// formatCiliumDropRates lists the drop rates, e.g. "Policy denied (INGRESS): 12.50/s"
+func formatCiliumDropRates(rates []CiliumDropRate) string {
+	parts := make([]string, len(rates))
+	for i, rate := range rates {
+		parts[i] = fmt.Sprintf("%s (%s): %.2f/s", rate.Reason, rate.Direction, rate.DropsPerSec)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hubbleFlow holds the fields of a dropped flow printed by 'hubble observe -o json'
+type hubbleFlow struct {
+	Flow struct {
+		DropReasonDesc string `json:"drop_reason_desc"`
+		IP             struct {
+			Source      string `json:"source"`
+			Destination string `json:"destination"`
+		} `json:"IP"`
+	} `json:"flow"`
+}
+
+// recentCiliumDrops returns the latest dropped flows as "reason: source -> destination", since
+// the drop counters carry no addresses. It needs the hubble CLI with access to Hubble Relay.
+func recentCiliumDrops(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "hubble", "observe", "--verdict", "DROPPED",
+		"--last", strconv.Itoa(ciliumRecentFlows), "-o", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("Error running hubble observe: %w", err)
+	}
+
+	var flows []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		var flow hubbleFlow
+		if err := json.Unmarshal(scanner.Bytes(), &flow); err != nil || flow.Flow.IP.Source == "" {
+			continue
+		}
+		flows = append(flows, fmt.Sprintf("%s: %s -> %s", flow.Flow.DropReasonDesc, flow.Flow.IP.Source, flow.Flow.IP.Destination))
+	}
+	return flows, nil
+}
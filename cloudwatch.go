@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+const (
+	cloudWatchDefaultNamespace = "GoSystemMonitor"
+	cloudWatchMaxBatchSize     = 20 // metrics per PutMetricData call
+)
+
+// CloudWatchConfig holds the CloudWatch region and namespace metrics are written to.
+// Credentials come from the standard AWS credential chain (environment, shared config, instance role).
+type CloudWatchConfig struct {
+	Region    string `json:"region"`
+	Namespace string `json:"namespace"` // defaults to GoSystemMonitor
+}
+
+// cloudWatchUnits maps the units of the metric report to CloudWatch units
+var cloudWatchUnits = map[string]types.StandardUnit{
+	"%":    types.StandardUnitPercent,
+	"s":    types.StandardUnitSeconds,
+	"ms":   types.StandardUnitMilliseconds,
+	"GB/s": types.StandardUnitGigabytesSecond,
+}
+
+// ec2InstanceID returns the ID of the EC2 instance, or "" when not running on EC2
+func ec2InstanceID() string {
+	meta, _ := ec2Metadata()
+	return meta.InstanceID
+}
+
+// WriteToCloudWatch writes the points as custom metrics with PutMetricData, 20 metrics per call
+func WriteToCloudWatch(ctx context.Context, cfg CloudWatchConfig, metrics []MetricPoint) error {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.Region))
+	if err != nil {
+		return fmt.Errorf("Error loading AWS configuration: %w", err)
+	}
+	client := cloudwatch.NewFromConfig(awsCfg)
+
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = cloudWatchDefaultNamespace
+	}
+	instanceID := ec2InstanceID()
+
+	data := make([]types.MetricDatum, 0, len(metrics))
+	for _, metric := range metrics {
+		datum := types.MetricDatum{
+			MetricName: aws.String(metric.Name),
+			Value:      aws.Float64(metric.Value),
+			Timestamp:  aws.Time(metric.Timestamp),
+			Unit:       types.StandardUnitNone,
+		}
+		if unit, ok := cloudWatchUnits[metric.Unit]; ok {
+			datum.Unit = unit
+		}
+		if instanceID != "" {
+			datum.Dimensions = append(datum.Dimensions, types.Dimension{Name: aws.String("InstanceId"), Value: aws.String(instanceID)})
+		}
+		if instance, ok := metric.Labels["instance"]; ok {
+			datum.Dimensions = append(datum.Dimensions, types.Dimension{Name: aws.String("Instance"), Value: aws.String(instance)})
+		}
+		data = append(data, datum)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+	for start := 0; start < len(data); start += cloudWatchMaxBatchSize {
+		end := min(start+cloudWatchMaxBatchSize, len(data))
+		_, err := client.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+			Namespace:  aws.String(namespace),
+			MetricData: data[start:end],
+		})
+		if err != nil {
+			return fmt.Errorf("Error writing metrics to CloudWatch: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+// Command go-system-monitor monitors system resources and sends alerts when they exceed
+// their thresholds. See the README for the configuration.
+package main
+
+import monitor "github.com/VedantamSravan/go-system-monitor"
+
+func main() {
+	monitor.Main()
+}
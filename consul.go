@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// ConsulCheck is a single entry of the consul_checks config
+type ConsulCheck struct {
+	Address  string   `json:"address"` // e.g. consul1:8500; empty uses CONSUL_HTTP_ADDR or the local agent
+	Token    string   `json:"token"`   // ACL token with read access to the services
+	Services []string `json:"services"`
+}
+
+// ConsulFailingCheck is a health check of a service instance that is not passing
+type ConsulFailingCheck struct {
+	Node      string `json:"node"`
+	ServiceID string `json:"service_id"`
+	CheckName string `json:"check_name"`
+	Status    string `json:"status"` // warning or critical
+	Output    string `json:"output"`
+}
+
+// ConsulServiceStat holds the health of all instances of a Consul service
+type ConsulServiceStat struct {
+	Service       string               `json:"service"`
+	Instances     int                  `json:"instances"`
+	FailingChecks []ConsulFailingCheck `json:"failing_checks,omitempty"`
+}
+
+// CheckConsulHealth queries the health of every instance of the configured services
+func CheckConsulHealth(ctx context.Context, cfg ConsulCheck) ([]ConsulServiceStat, error) {
+	consulConfig := api.DefaultConfig()
+	if cfg.Address != "" {
+		consulConfig.Address = cfg.Address
+	}
+	if cfg.Token != "" {
+		consulConfig.Token = cfg.Token
+	}
+	client, err := api.NewClient(consulConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating Consul client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+	query := (&api.QueryOptions{}).WithContext(ctx)
+
+	stats := make([]ConsulServiceStat, 0, len(cfg.Services))
+	for _, service := range cfg.Services {
+		entries, _, err := client.Health().Service(service, "", false, query)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching Consul health of %s: %w", service, err)
+		}
+
+		stat := ConsulServiceStat{Service: service, Instances: len(entries)}
+		for _, entry := range entries {
+			for _, check := range entry.Checks {
+				if check.Status == api.HealthPassing {
+					continue
+				}
+				stat.FailingChecks = append(stat.FailingChecks, ConsulFailingCheck{
+					Node:      check.Node,
+					ServiceID: check.ServiceID,
+					CheckName: check.Name,
+					Status:    check.Status,
+					Output:    check.Output,
+				})
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
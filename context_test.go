@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeSysfs points sysfsRoot at a temporary tree containing the given files
+func fakeSysfs(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orig := sysfsRoot
+	sysfsRoot = root
+	t.Cleanup(func() { sysfsRoot = orig })
+}
+
+// cancelAfter returns a context that is canceled after d
+func cancelAfter(t *testing.T, d time.Duration) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	time.AfterFunc(d, cancel)
+	return ctx
+}
+
+func TestGetRAPLPowerCanceled(t *testing.T) {
+	fakeSysfs(t, map[string]string{
+		"class/powercap/intel-rapl:0/energy_uj":           "1000000\n",
+		"class/powercap/intel-rapl:0/max_energy_range_uj": "262143328850\n",
+		"class/powercap/intel-rapl:0/name":                "package-0\n",
+	})
+
+	start := time.Now()
+	_, err := GetRAPLPower(cancelAfter(t, 50*time.Millisecond))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= raplSampleInterval {
+		t.Errorf("GetRAPLPower returned after %v, expected well before the %v sample interval", elapsed, raplSampleInterval)
+	}
+}
+
+func TestGetThrottleEventsCanceled(t *testing.T) {
+	fakeSysfs(t, map[string]string{
+		"devices/system/cpu/cpu0/thermal_throttle/core_throttle_count": "42\n",
+	})
+
+	start := time.Now()
+	_, err := GetThrottleEvents(cancelAfter(t, 50*time.Millisecond))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= throttleSampleInterval {
+		t.Errorf("GetThrottleEvents returned after %v, expected well before the %v sample interval", elapsed, throttleSampleInterval)
+	}
+}
+
+func TestCommandCollectorsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GetCPUTemperature(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetCPUTemperature: expected context.Canceled, got %v", err)
+	}
+	if _, err := GetFanSpeeds(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetFanSpeeds: expected context.Canceled, got %v", err)
+	}
+	if _, err := GetUSBTemperatureSensor(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetUSBTemperatureSensor: expected context.Canceled, got %v", err)
+	}
+	if _, err := GetMemoryBandwidth(ctx, memBandwidthSampleMs); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetMemoryBandwidth: expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCheckDNSResolutionCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// 192.0.2.1 (TEST-NET-1) is never reachable, so only cancellation can end the lookup early
+	start := time.Now()
+	_, err := CheckDNSResolution(ctx, "example.com", "192.0.2.1", dnsTimeout)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= dnsTimeout {
+		t.Errorf("CheckDNSResolution returned after %v, expected before the %v timeout", elapsed, dnsTimeout)
+	}
+}
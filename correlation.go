@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CorrelationGroup lists metrics that usually breach together because of a single cause,
+// such as a backup job driving CPU, memory and disk at the same time
+type CorrelationGroup struct {
+	Metrics            []string `json:"metrics"`
+	SuppressIndividual bool     `json:"suppress_individual"` // drop the per-metric alerts once the combined one is sent
+}
+
+// DetectCorrelation adds one combined alert for every group whose metrics all breached,
+// and removes the individual alerts of groups that suppress them
+func DetectCorrelation(alerts []AlertEntry, groups []CorrelationGroup) []AlertEntry {
+	byMetric := make(map[string][]AlertEntry)
+	for _, alert := range alerts {
+		byMetric[alert.Metric] = append(byMetric[alert.Metric], alert)
+	}
+
+	var combined []AlertEntry
+	suppressed := make(map[string]bool)
+	for _, group := range groups {
+		if len(group.Metrics) == 0 {
+			continue
+		}
+
+		allBreached := true
+		for _, metric := range group.Metrics {
+			if len(byMetric[metric]) == 0 {
+				allBreached = false
+				break
+			}
+		}
+		if !allBreached {
+			continue
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Alert: %s breached at the same time (possibly a single cause):", strings.Join(group.Metrics, ", "))
+		for _, metric := range group.Metrics {
+			for _, alert := range byMetric[metric] {
+				b.WriteString("\n  - ")
+				b.WriteString(alert.Message)
+			}
+			if group.SuppressIndividual {
+				suppressed[metric] = true
+			}
+		}
+		combined = append(combined, AlertEntry{
+			Metric:  strings.Join(group.Metrics, "+"),
+			Message: b.String(),
+		})
+	}
+
+	if len(combined) == 0 {
+		return alerts
+	}
+
+	result := make([]AlertEntry, 0, len(alerts)+len(combined))
+	for _, alert := range alerts {
+		if !suppressed[alert.Metric] {
+			result = append(result, alert)
+		}
+	}
+	return append(result, combined...)
+}
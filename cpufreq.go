@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GetCurrentCPUFrequency returns the current frequency of each core in GHz.
+// It reads the real-time cpufreq value from sysfs on Linux, since cpu.Info()
+// may only report the base clock, and falls back to cpu.Info() elsewhere.
+func GetCurrentCPUFrequency(ctx context.Context) ([]float64, error) {
+	paths, err := filepath.Glob(filepath.Join(sysfsRoot, "devices", "system", "cpu", "cpu[0-9]*", "cpufreq", "scaling_cur_freq"))
+	if err != nil || len(paths) == 0 {
+		return getCPUInfoFrequency(ctx)
+	}
+
+	type coreFreq struct {
+		cpu int
+		ghz float64
+	}
+	freqs := make([]coreFreq, 0, len(paths))
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		khz, err := readSysfsUint(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading CPU frequency: %w", err)
+		}
+
+		// path is /sys/devices/system/cpu/cpuN/cpufreq/scaling_cur_freq
+		cpuDir := filepath.Base(filepath.Dir(filepath.Dir(path)))
+		cpuIndex, err := strconv.Atoi(strings.TrimPrefix(cpuDir, "cpu"))
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing CPU index from %s: %w", path, err)
+		}
+
+		freqs = append(freqs, coreFreq{cpu: cpuIndex, ghz: float64(khz) / 1e6})
+	}
+
+	// Glob sorts lexically (cpu10 before cpu2)
+	sort.Slice(freqs, func(i, j int) bool { return freqs[i].cpu < freqs[j].cpu })
+
+	result := make([]float64, len(freqs))
+	for i, f := range freqs {
+		result[i] = f.ghz
+	}
+	return result, nil
+}
+
+// getCPUInfoFrequency returns the frequency reported by cpu.Info() for each CPU in GHz
+func getCPUInfoFrequency(ctx context.Context) ([]float64, error) {
+	infos, err := cpuProvider.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching CPU info: %w", err)
+	}
+
+	result := make([]float64, len(infos))
+	for i, info := range infos {
+		result[i] = info.Mhz / 1000.0
+	}
+	return result, nil
+}
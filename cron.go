@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// CronCheck is a dead man's switch for a cron job. The job reports success by
+// touching HeartbeatFile or by calling POST /heartbeat/{name} on the API server.
+type CronCheck struct {
+	Name                string `json:"name"`
+	MaxStalenessSeconds int    `json:"max_staleness_seconds"`
+	HeartbeatFile       string `json:"heartbeat_file"`
+}
+
+// CronStat holds the last known successful run of a cron job
+type CronStat struct {
+	Name      string        `json:"name"`
+	LastRun   time.Time     `json:"last_run"` // zero if the job never reported
+	Staleness time.Duration `json:"staleness"`
+	Stale     bool          `json:"stale"`
+}
+
+// heartbeatRegistry records heartbeats received through the API
+type heartbeatRegistry struct {
+	mu        sync.Mutex
+	last      map[string]time.Time
+	startedAt time.Time
+}
+
+// apiHeartbeats holds the heartbeats posted to the API server
+var apiHeartbeats = &heartbeatRegistry{last: make(map[string]time.Time), startedAt: time.Now()}
+
+// Record stores a heartbeat for the named job
+func (r *heartbeatRegistry) Record(name string, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last[name] = at
+}
+
+// Last returns the most recent heartbeat of the named job
+func (r *heartbeatRegistry) Last(name string) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	at, ok := r.last[name]
+	return at, ok
+}
+
+// CheckCronHeartbeat reports when the cron job last succeeded and whether that is longer ago than allowed
+func CheckCronHeartbeat(c CronCheck) (CronStat, error) {
+	stat := CronStat{Name: c.Name}
+
+	if c.HeartbeatFile != "" {
+		info, err := os.Stat(c.HeartbeatFile)
+		if err != nil && !os.IsNotExist(err) {
+			return stat, fmt.Errorf("Error reading heartbeat file of %s: %w", c.Name, err)
+		}
+		if err == nil {
+			stat.LastRun = info.ModTime()
+		}
+	}
+	if at, ok := apiHeartbeats.Last(c.Name); ok && at.After(stat.LastRun) {
+		stat.LastRun = at
+	}
+
+	// A job without any heartbeat yet gets one staleness period from monitor startup
+	since := stat.LastRun
+	if since.IsZero() {
+		since = apiHeartbeats.startedAt
+	}
+	stat.Staleness = time.Since(since)
+	stat.Stale = stat.Staleness > time.Duration(c.MaxStalenessSeconds)*time.Second
+
+	return stat, nil
+}
@@ -0,0 +1,206 @@
+package monitor
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// crontabSettle is how long a crontab must stay unchanged before its change is reported, so an
+// editor writing a file in several steps raises one alert
+const crontabSettle = 500 * time.Millisecond
+
+// defaultCrontabPaths are the user crontabs and the system crontab files read by cron
+var defaultCrontabPaths = []string{"/var/spool/cron", "/etc/cron.d", "/etc/crontab"}
+
+// CrontabChange is a crontab file that was created, modified or deleted
+type CrontabChange struct {
+	Path    string    `json:"path"`
+	Op      string    `json:"op"`       // created, modified or deleted
+	ModTime time.Time `json:"mod_time"` // zero when deleted
+	MD5     string    `json:"md5"`      // of the new content; empty when deleted
+}
+
+// crontabWatch tracks the watched directories and the pending changes of MonitorCrontabChanges
+type crontabWatch struct {
+	watcher *fsnotify.Watcher
+	files   map[string]bool // crontab files watched through their parent directory
+	dirs    map[string]bool // directories whose files are all crontabs
+	ch      chan<- CrontabChange
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer
+	created map[string]bool
+}
+
+// MonitorCrontabChanges watches the crontab files and directories in paths, e.g. defaultCrontabPaths,
+// and publishes every created, modified or deleted crontab to ch. Paths that don't exist are
+// skipped. It blocks until the watcher fails.
+func MonitorCrontabChanges(paths []string, ch chan<- CrontabChange) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("Error creating crontab watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	w := &crontabWatch{
+		watcher: watcher,
+		files:   make(map[string]bool),
+		dirs:    make(map[string]bool),
+		ch:      ch,
+		pending: make(map[string]*time.Timer),
+		created: make(map[string]bool),
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("Error watching crontab %s: %w", path, err)
+		}
+		if info.IsDir() {
+			err = w.addDir(path)
+		} else {
+			// Editors replace files by renaming, which would end a watch on the file itself
+			w.files[path] = true
+			err = watcher.Add(filepath.Dir(path))
+		}
+		if err != nil {
+			return fmt.Errorf("Error watching crontab %s: %w", path, err)
+		}
+	}
+	if len(w.files) == 0 && len(w.dirs) == 0 {
+		return fmt.Errorf("Error watching crontabs: none of %s exists", strings.Join(paths, ", "))
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			w.handle(event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("Error watching crontabs: %w", err)
+		}
+	}
+}
+
+// addDir watches dir and its subdirectories, like /var/spool/cron/crontabs on Debian
+func (w *crontabWatch) addDir(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return err
+		}
+		w.dirs[path] = true
+		return w.watcher.Add(path)
+	})
+}
+
+// handle schedules the report of a crontab event once the file settles
+func (w *crontabWatch) handle(event fsnotify.Event) {
+	path := event.Name
+	if !w.files[path] && !w.dirs[filepath.Dir(path)] {
+		return
+	}
+	// cron skips hidden files and editor backups, e.g. .crontab.swp and crontab~
+	if name := filepath.Base(path); strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+		return
+	}
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			if w.dirs[filepath.Dir(path)] {
+				if err := w.addDir(path); err != nil {
+					log.Printf("Error watching crontab directory %s: %v\n", path, err)
+				}
+			}
+			return
+		}
+	}
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) && !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if event.Has(fsnotify.Create) {
+		w.created[path] = true
+	}
+	if timer, ok := w.pending[path]; ok {
+		timer.Reset(crontabSettle)
+		return
+	}
+	w.pending[path] = time.AfterFunc(crontabSettle, func() { w.report(path) })
+}
+
+// report publishes the change of a crontab that settled
+func (w *crontabWatch) report(path string) {
+	w.mu.Lock()
+	created := w.created[path]
+	delete(w.pending, path)
+	delete(w.created, path)
+	w.mu.Unlock()
+
+	change, err := readCrontabChange(path, created)
+	if err != nil {
+		log.Printf("%v\n", err)
+		return
+	}
+	w.ch <- change
+}
+
+// readCrontabChange describes the crontab at path after a change; a missing file was deleted
+func readCrontabChange(path string, created bool) (CrontabChange, error) {
+	change := CrontabChange{Path: path, Op: "modified"}
+	if created {
+		change.Op = "created"
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		change.Op = "deleted"
+		return change, nil
+	}
+	if err != nil {
+		return change, fmt.Errorf("Error reading crontab %s: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return change, fmt.Errorf("Error reading crontab %s: %w", path, err)
+	}
+	change.ModTime = info.ModTime()
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return change, fmt.Errorf("Error reading crontab %s: %w", path, err)
+	}
+	change.MD5 = hex.EncodeToString(hash.Sum(nil))
+	return change, nil
+}
+
+// FormatCrontabAlert builds the alert body for a crontab change
+func FormatCrontabAlert(change CrontabChange) string {
+	if change.Op == "deleted" {
+		return fmt.Sprintf("Alert: Crontab %s was deleted\n", change.Path)
+	}
+	return fmt.Sprintf("Alert: Crontab %s was %s\n"+
+		"Modified: %s\n"+
+		"MD5: %s\n",
+		change.Path, change.Op, change.ModTime.Format(time.RFC3339), change.MD5)
+}
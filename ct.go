@@ -0,0 +1,215 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ctTimeout      = 60 * time.Second // crt.sh often takes tens of seconds for busy domains
+	ctLookback     = 24 * time.Hour   // certificates logged this recently are listed in CTInfo.Recent
+	ctErrorBodyLen = 512
+)
+
+// crtShURL is the crt.sh endpoint, a variable so tests can point it at a fake server
+var crtShURL = "https://crt.sh/"
+
+// CTCheck is a single entry of the ct_checks config
+type CTCheck struct {
+	Domain string `json:"domain"` // e.g. "example.com", or "%.example.com" for its subdomains
+	// IssuanceWindow is the local time of day new certificates are expected in, e.g. "02:00-04:00"
+	// when a renewal job runs at 2 AM. Empty alerts on every new certificate.
+	IssuanceWindow string `json:"issuance_window"`
+}
+
+// CTCertificate is a certificate found in the Certificate Transparency logs
+type CTCertificate struct {
+	ID         int64     `json:"id"` // crt.sh ID, increasing with every logged certificate
+	Issuer     string    `json:"issuer"`
+	CommonName string    `json:"common_name"`
+	Names      []string  `json:"names"`
+	LoggedAt   time.Time `json:"logged_at"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+}
+
+// CTInfo holds the certificates recently logged for a domain
+type CTInfo struct {
+	Domain    string          `json:"domain"`
+	Recent    []CTCertificate `json:"recent"`    // logged within the last day, newest first
+	LatestID  int64           `json:"latest_id"` // highest crt.sh ID of any unexpired certificate
+	Unexpired []CTCertificate `json:"-"`         // every unexpired certificate, newest first; Recent is the last day of it
+}
+
+// crtShEntry is one certificate of the crt.sh JSON output
+type crtShEntry struct {
+	ID             int64     `json:"id"`
+	IssuerName     string    `json:"issuer_name"`
+	CommonName     string    `json:"common_name"`
+	NameValue      string    `json:"name_value"` // the SANs, one per line
+	EntryTimestamp crtShTime `json:"entry_timestamp"`
+	NotBefore      crtShTime `json:"not_before"`
+	NotAfter       crtShTime `json:"not_after"`
+}
+
+// crtShTime parses the timestamps of crt.sh, which are in UTC without a zone, e.g. 2024-05-01T10:21:33.123
+type crtShTime struct{ time.Time }
+
+func (t *crtShTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil || s == "" {
+		return err
+	}
+	parsed, err := time.ParseInLocation("2006-01-02T15:04:05.999999999", s, time.UTC)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// CheckCertTransparency looks up the unexpired certificates of domain in the Certificate
+// Transparency logs through crt.sh
+func CheckCertTransparency(ctx context.Context, domain string) (CTInfo, error) {
+	info := CTInfo{Domain: domain}
+
+	ctx, cancel := context.WithTimeout(ctx, ctTimeout)
+	defer cancel()
+	query := url.Values{"q": {domain}, "output": {"json"}, "exclude": {"expired"}, "deduplicate": {"Y"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, crtShURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return info, fmt.Errorf("Error creating crt.sh request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return info, fmt.Errorf("Error querying crt.sh for %s: %w", domain, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, ctErrorBodyLen))
+		return info, fmt.Errorf("crt.sh returned %s for %s: %s", resp.Status, domain, bytes.TrimSpace(msg))
+	}
+
+	var entries []crtShEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return info, fmt.Errorf("Error parsing crt.sh response for %s: %w", domain, err)
+	}
+
+	since := time.Now().Add(-ctLookback)
+	for _, entry := range entries {
+		info.LatestID = max(info.LatestID, entry.ID)
+		info.Unexpired = append(info.Unexpired, CTCertificate{
+			ID:         entry.ID,
+			Issuer:     entry.IssuerName,
+			CommonName: entry.CommonName,
+			Names:      strings.Fields(entry.NameValue),
+			LoggedAt:   entry.EntryTimestamp.Time,
+			NotBefore:  entry.NotBefore.Time,
+			NotAfter:   entry.NotAfter.Time,
+		})
+	}
+	sort.Slice(info.Unexpired, func(i, j int) bool { return info.Unexpired[i].ID > info.Unexpired[j].ID })
+	for _, cert := range info.Unexpired {
+		if !cert.LoggedAt.Before(since) {
+			info.Recent = append(info.Recent, cert)
+		}
+	}
+	return info, nil
+}
+
+// findCTInfo returns the check of domain in infos, or nil when it has none
+func findCTInfo(infos []CTInfo, domain string) *CTInfo {
+	for i := range infos {
+		if infos[i].Domain == domain {
+			return &infos[i]
+		}
+	}
+	return nil
+}
+
+// NewCertificates returns the certificates of cur that were logged after the previous check, however
+// long ago it was. Without a previous check, e.g. after a restart, cur is the baseline and no
+// certificate is new.
+func NewCertificates(prev *CTInfo, cur CTInfo) []CTCertificate {
+	if prev == nil {
+		return nil
+	}
+	var certs []CTCertificate
+	for _, cert := range cur.Unexpired {
+		if cert.ID > prev.LatestID {
+			certs = append(certs, cert)
+		}
+	}
+	return certs
+}
+
+// parseIssuanceWindow parses a time of day range like "02:00-04:00" into offsets from midnight.
+// The range may wrap around midnight, e.g. "22:00-02:00".
+func parseIssuanceWindow(window string) (start, end time.Duration, err error) {
+	from, to, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid issuance_window %q: must look like 02:00-04:00", window)
+	}
+	for _, bound := range []struct {
+		s string
+		d *time.Duration
+	}{{from, &start}, {to, &end}} {
+		t, err := time.Parse("15:04", strings.TrimSpace(bound.s))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid issuance_window %q: must look like 02:00-04:00", window)
+		}
+		*bound.d = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return start, end, nil
+}
+
+// inIssuanceWindow reports whether t falls into the window in the local time zone. An empty
+// window contains no time, so every new certificate alerts.
+func inIssuanceWindow(window string, t time.Time) bool {
+	if window == "" {
+		return false
+	}
+	start, end, err := parseIssuanceWindow(window)
+	if err != nil {
+		return false
+	}
+	t = t.Local()
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if start <= end {
+		return offset >= start && offset < end
+	}
+	return offset >= start || offset < end
+}
+
+// validateCTChecks checks the ct_checks config
+func validateCTChecks(checks []CTCheck) error {
+	for _, check := range checks {
+		if check.Domain == "" {
+			return fmt.Errorf("invalid ct_checks entry: domain is required")
+		}
+		if check.IssuanceWindow == "" {
+			continue
+		}
+		if _, _, err := parseIssuanceWindow(check.IssuanceWindow); err != nil {
+			return fmt.Errorf("ct_checks %s: %w", check.Domain, err)
+		}
+	}
+	return nil
+}
+
+// formatCTCertificate describes a certificate for an alert, e.g.
+// "CN=www.example.com (www.example.com, example.com) by C=US, O=Let's Encrypt, CN=R3, logged 2024-05-01 12:21 CEST, crt.sh ID 12345"
+func formatCTCertificate(cert CTCertificate) string {
+	return fmt.Sprintf("CN=%s (%s) by %s, logged %s, crt.sh ID %d", cert.CommonName, strings.Join(cert.Names, ", "),
+		cert.Issuer, cert.LoggedAt.Local().Format("2006-01-02 15:04 MST"), cert.ID)
+}
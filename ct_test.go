@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCrtSh points crtShURL at a server answering with the given status and the crt.sh JSON
+// of the given certificate IDs, all logged age ago
+func fakeCrtSh(t *testing.T, status *int, ids *[]int64, age time.Duration) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if *status != http.StatusOK {
+			http.Error(w, "Bad Gateway", *status)
+			return
+		}
+		logged := time.Now().UTC().Add(-age).Format("2006-01-02T15:04:05.000")
+		var entries []string
+		for _, id := range *ids {
+			entries = append(entries, fmt.Sprintf(`{"id":%d,"issuer_name":"CN=R3","common_name":"www.example.com","name_value":"www.example.com","entry_timestamp":%q}`, id, logged))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(entries, ","))
+	}))
+	t.Cleanup(server.Close)
+
+	orig, origOutput := crtShURL, StatusOutput
+	crtShURL, StatusOutput = server.URL+"/", io.Discard
+	t.Cleanup(func() { crtShURL, StatusOutput = orig, origOutput })
+}
+
+func TestNewCertificatesBaseline(t *testing.T) {
+	cur := CTInfo{Domain: "example.com", Unexpired: []CTCertificate{{ID: 12}, {ID: 11}}, LatestID: 12}
+
+	if certs := NewCertificates(nil, cur); len(certs) != 0 {
+		t.Errorf("expected no new certificates without a previous check, got %v", certs)
+	}
+	certs := NewCertificates(&CTInfo{Domain: "example.com", LatestID: 11}, cur)
+	if len(certs) != 1 || certs[0].ID != 12 {
+		t.Errorf("expected certificate 12 to be new, got %v", certs)
+	}
+}
+
+func TestCTFirstCheckIsBaseline(t *testing.T) {
+	status, ids := http.StatusOK, []int64{11, 12}
+	fakeCrtSh(t, &status, &ids, time.Minute)
+	config := Config{CTChecks: []CTCheck{{Domain: "example.com"}}}
+
+	snap, alerts, _, _ := checkMetrics(context.Background(), config, MetricSet{"ct": true}, &SafeSnapshot{})
+	if len(alerts) != 0 {
+		t.Errorf("expected no alerts on the first check, got %v", alerts)
+	}
+	if len(snap.CT) != 1 || snap.CT[0].LatestID != 12 {
+		t.Errorf("expected the checked certificates in the snapshot, got %+v", snap.CT)
+	}
+}
+
+func TestCTFailedQueryKeepsPreviousCheck(t *testing.T) {
+	status, ids := http.StatusBadGateway, []int64{11, 12}
+	fakeCrtSh(t, &status, &ids, time.Minute)
+	config := Config{CTChecks: []CTCheck{{Domain: "example.com"}}}
+	previous := &SafeSnapshot{}
+	previous.Set(MetricSnapshot{CT: []CTInfo{{Domain: "example.com", LatestID: 12}}})
+
+	snap, alerts, _, errs := checkMetrics(context.Background(), config, MetricSet{"ct": true}, previous)
+	if len(errs) != 1 {
+		t.Errorf("expected the failed query in the errors, got %v", errs)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("expected no alerts for a single failed query, got %v", alerts)
+	}
+	if len(snap.CT) != 1 || snap.CT[0].LatestID != 12 {
+		t.Fatalf("expected the previous check to be kept, got %+v", snap.CT)
+	}
+
+	// Only the certificate logged since the last successful check alerts
+	status, ids = http.StatusOK, []int64{11, 12, 13}
+	previous.Set(snap)
+	_, alerts, _, _ = checkMetrics(context.Background(), config, MetricSet{"ct": true}, previous)
+	if len(alerts) != 1 || !strings.Contains(alerts[0].Message, "crt.sh ID 13") {
+		t.Errorf("expected a single alert for certificate 13, got %v", alerts)
+	}
+}
+
+func TestCTCertificatesOlderThanLookback(t *testing.T) {
+	// With a ct interval of two days the new certificate is no longer in the last day's list
+	status, ids := http.StatusOK, []int64{11, 12, 13}
+	fakeCrtSh(t, &status, &ids, 36*time.Hour)
+	config := Config{CTChecks: []CTCheck{{Domain: "example.com"}}}
+	previous := &SafeSnapshot{}
+	previous.Set(MetricSnapshot{CT: []CTInfo{{Domain: "example.com", LatestID: 12}}})
+
+	_, alerts, _, _ := checkMetrics(context.Background(), config, MetricSet{"ct": true}, previous)
+	if len(alerts) != 1 || !strings.Contains(alerts[0].Message, "crt.sh ID 13") {
+		t.Errorf("expected a single alert for certificate 13, got %v", alerts)
+	}
+}
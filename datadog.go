@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	datadogDefaultSite   = "datadoghq.com"
+	datadogMaxBatchSize  = 500 // points per series request
+	datadogMetricPrefix  = "system_monitor."
+	datadogMetricGauge   = 3 // Series v2 metric type
+	datadogErrorBodySize = 512
+)
+
+// DatadogConfig holds the Datadog account metrics are sent to
+type DatadogConfig struct {
+	APIKey string `json:"api_key"`
+	AppKey string `json:"app_key"` // optional; not required for metric submission
+	Site   string `json:"site"`    // e.g. datadoghq.eu; defaults to datadoghq.com
+}
+
+// DDMetricPoint is a single gauge value sent to Datadog
+type DDMetricPoint struct {
+	Metric    string
+	Timestamp int64 // Unix seconds
+	Value     float64
+	Host      string
+	Tags      []string // key:value
+}
+
+// ddSeriesPayload is the body of POST /api/v2/series
+type ddSeriesPayload struct {
+	Series []ddSeries `json:"series"`
+}
+
+type ddSeries struct {
+	Metric    string       `json:"metric"`
+	Type      int          `json:"type"`
+	Points    []ddPoint    `json:"points"`
+	Resources []ddResource `json:"resources,omitempty"`
+	Tags      []string     `json:"tags,omitempty"`
+}
+
+type ddPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+type ddResource struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// DatadogSeries converts a snapshot into one gauge point per metric, tagged with the config labels
+func DatadogSeries(snap MetricSnapshot, metrics MetricSet, host string, labels map[string]string) []DDMetricPoint {
+	tags := make([]string, 0, len(labels))
+	for key, value := range labels {
+		tags = append(tags, key+":"+value)
+	}
+	sort.Strings(tags)
+
+	rows := snapshotRows(snap)
+	points := make([]DDMetricPoint, 0, len(rows))
+	for _, row := range rows {
+		if name, _, _ := strings.Cut(row.Metric, "."); !metrics.Covers(name) {
+			continue
+		}
+		points = append(points, DDMetricPoint{
+			Metric:    datadogMetricPrefix + row.Metric,
+			Timestamp: snap.Timestamp.Unix(),
+			Value:     row.Value,
+			Host:      host,
+			Tags:      tags,
+		})
+	}
+	return points
+}
+
+// SendToDatadog submits the points as gauges to the Datadog Series v2 API in batches of up to 500 points
+func SendToDatadog(cfg DatadogConfig, series []DDMetricPoint) error {
+	site := cfg.Site
+	if site == "" {
+		site = datadogDefaultSite
+	}
+	url := "https://api." + site + "/api/v2/series"
+
+	for start := 0; start < len(series); start += datadogMaxBatchSize {
+		end := min(start+datadogMaxBatchSize, len(series))
+
+		var payload ddSeriesPayload
+		for _, point := range series[start:end] {
+			s := ddSeries{
+				Metric: point.Metric,
+				Type:   datadogMetricGauge,
+				Points: []ddPoint{{Timestamp: point.Timestamp, Value: point.Value}},
+				Tags:   point.Tags,
+			}
+			if point.Host != "" {
+				s.Resources = []ddResource{{Name: point.Host, Type: "host"}}
+			}
+			payload.Series = append(payload.Series, s)
+		}
+
+		if err := postDatadogSeries(cfg, url, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postDatadogSeries sends a single batch
+func postDatadogSeries(cfg DatadogConfig, url string, payload ddSeriesPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("Error encoding Datadog series: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serviceCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Error creating Datadog request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent())
+	req.Header.Set("DD-API-KEY", cfg.APIKey)
+	if cfg.AppKey != "" {
+		req.Header.Set("DD-APPLICATION-KEY", cfg.AppKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Error sending metrics to Datadog: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("Datadog rejected the API key (403 Forbidden): check datadog.api_key and datadog.site")
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, datadogErrorBodySize))
+		return fmt.Errorf("Datadog returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
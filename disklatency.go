@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// diskLatencySampleInterval is the time between the two readings of the block device counters
+const diskLatencySampleInterval = 1 * time.Second
+
+// ErrDiskLatencyNotAvailable is returned on systems without /sys/block
+var ErrDiskLatencyNotAvailable = errors.New("disk latency counters are not available on this system")
+
+// virtualBlockDevices are the prefixes of block devices without real storage behind them
+var virtualBlockDevices = []string{"loop", "ram", "zram"}
+
+// DiskLatencyStat holds the I/O latency of a block device over the sample interval
+type DiskLatencyStat struct {
+	Device      string  `json:"device"`
+	AwaitMs     float64 `json:"await_ms"`     // average time of a completed request, queueing included
+	IOPS        float64 `json:"iops"`         // completed reads and writes per second
+	UtilPercent float64 `json:"util_percent"` // share of the interval the device was busy
+	InFlight    uint64  `json:"in_flight"`    // requests in progress at the end of the interval
+}
+
+// diskCounters are the cumulative counters of /sys/block/{dev}/stat
+type diskCounters struct {
+	ios      uint64 // completed reads and writes
+	ticks    uint64 // ms spent on reads and writes
+	inFlight uint64
+	ioTicks  uint64 // ms the device was busy
+}
+
+// readDiskCounters parses /sys/block/{dev}/stat. The fields are: reads completed, reads merged,
+// sectors read, ms reading, writes completed, writes merged, sectors written, ms writing,
+// I/Os in progress, ms doing I/O, weighted ms doing I/O (followed by discard and flush fields).
+func readDiskCounters(device string) (diskCounters, error) {
+	data, err := os.ReadFile(filepath.Join(sysfsRoot, "block", device, "stat"))
+	if err != nil {
+		return diskCounters{}, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 11 {
+		return diskCounters{}, fmt.Errorf("could not parse %s stat: expected at least 11 fields, got %d", device, len(fields))
+	}
+	values := make([]uint64, 11)
+	for i := range values {
+		if values[i], err = strconv.ParseUint(fields[i], 10, 64); err != nil {
+			return diskCounters{}, fmt.Errorf("could not parse %s stat: %w", device, err)
+		}
+	}
+	return diskCounters{
+		ios:      values[0] + values[4],
+		ticks:    values[3] + values[7],
+		inFlight: values[8],
+		ioTicks:  values[9],
+	}, nil
+}
+
+// latency computes the latency between two readings elapsed apart
+func (before diskCounters) latency(device string, after diskCounters, elapsed time.Duration) DiskLatencyStat {
+	stat := DiskLatencyStat{Device: device, InFlight: after.inFlight}
+	// Counters reset when a device is re-added; skip the interval
+	if after.ios < before.ios || after.ticks < before.ticks || after.ioTicks < before.ioTicks {
+		return stat
+	}
+	ios := after.ios - before.ios
+	if ios > 0 {
+		stat.AwaitMs = float64(after.ticks-before.ticks) / float64(ios)
+	}
+	stat.IOPS = float64(ios) / elapsed.Seconds()
+	stat.UtilPercent = min(100, float64(after.ioTicks-before.ioTicks)/float64(elapsed.Milliseconds())*100)
+	return stat
+}
+
+// GetDiskLatency reads the counters of a block device (e.g. sda) interval apart and returns its latency
+func GetDiskLatency(device string, interval time.Duration) (DiskLatencyStat, error) {
+	before, err := readDiskCounters(device)
+	if err != nil {
+		return DiskLatencyStat{}, fmt.Errorf("Error reading disk stats: %w", err)
+	}
+	start := time.Now()
+	time.Sleep(interval)
+	after, err := readDiskCounters(device)
+	if err != nil {
+		return DiskLatencyStat{}, fmt.Errorf("Error reading disk stats: %w", err)
+	}
+	return before.latency(device, after, time.Since(start)), nil
+}
+
+// GetDiskLatencies samples all block devices except loop and RAM disks at once
+func GetDiskLatencies(ctx context.Context) ([]DiskLatencyStat, error) {
+	entries, err := os.ReadDir(filepath.Join(sysfsRoot, "block"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrDiskLatencyNotAvailable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error listing block devices: %w", err)
+	}
+
+	var devices []string
+	var before []diskCounters
+	for _, entry := range entries {
+		device := entry.Name()
+		if isVirtualBlockDevice(device) {
+			continue
+		}
+		counters, err := readDiskCounters(device)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading disk stats: %w", err)
+		}
+		devices = append(devices, device)
+		before = append(before, counters)
+	}
+	if len(devices) == 0 {
+		return nil, ErrDiskLatencyNotAvailable
+	}
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(diskLatencySampleInterval):
+	}
+
+	elapsed := time.Since(start)
+	stats := make([]DiskLatencyStat, 0, len(devices))
+	for i, device := range devices {
+		after, err := readDiskCounters(device)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading disk stats: %w", err)
+		}
+		stats = append(stats, before[i].latency(device, after, elapsed))
+	}
+	return stats, nil
+}
+
+// isVirtualBlockDevice reports whether device is a loop device or RAM disk
+func isVirtualBlockDevice(device string) bool {
+	for _, prefix := range virtualBlockDevices {
+		if strings.HasPrefix(device, prefix) {
+			return true
+		}
+	}
+	return false
+}
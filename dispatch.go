@@ -0,0 +1,140 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// alertChannelTimeout bounds the delivery of an alert on a single channel
+const alertChannelTimeout = 30 * time.Second
+
+// AlertChannel delivers alert notifications
+type AlertChannel interface {
+	Name() string
+	Send(ctx context.Context, payload AlertPayload) error
+}
+
+// AlertChannelError is the delivery failure of a single channel
+type AlertChannelError struct {
+	Channel string
+	Err     error
+}
+
+func (e *AlertChannelError) Error() string {
+	return fmt.Sprintf("Error sending alert via %s: %v", e.Channel, e.Err)
+}
+
+func (e *AlertChannelError) Unwrap() error {
+	return e.Err
+}
+
+// emailChannel sends the payload as an email with the Message as subject
+type emailChannel struct {
+	client  *SMTPClient
+	history *MetricStore // source of the email charts; nil without history_db
+}
+
+func (c emailChannel) Name() string { return "email" }
+
+func (c emailChannel) Send(ctx context.Context, payload AlertPayload) error {
+	msg := &EmailMessage{Subject: payload.Message, Body: payload.Description}
+	if c.client.config.EmailCharts && c.history != nil {
+		msg.Charts = renderAlertCharts(c.history, payload.Alerts, time.Now())
+	}
+	for _, attachment := range c.client.config.AttachLogs {
+		if !attachment.Matches(payload.Alerts) {
+			continue
+		}
+		if err := AttachLogFile(msg, attachment.Path, attachment.MaxBytes); err != nil {
+			log.Printf("Error attaching %s: %v\n", attachment.Path, err)
+		}
+	}
+	return sendEmailMessage(ctx, c.client, msg)
+}
+
+// syslogChannel forwards one syslog message per alert, or the description if there are none
+type syslogChannel struct {
+	config SyslogConfig
+}
+
+func (c syslogChannel) Name() string { return "syslog" }
+
+func (c syslogChannel) Send(ctx context.Context, payload AlertPayload) error {
+	if len(payload.Alerts) == 0 {
+		return ForwardToSyslog(c.config, payload.Description)
+	}
+	var errs []error
+	for _, alert := range payload.Alerts {
+		if err := ForwardToSyslog(c.config, alert.Message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewAlertChannels returns the channels configured in config
+func NewAlertChannels(config Config, mailer *SMTPClient, history *MetricStore) []AlertChannel {
+	channels := []AlertChannel{emailChannel{client: mailer, history: history}}
+	if config.Syslog != nil {
+		channels = append(channels, syslogChannel{config: *config.Syslog})
+	}
+	if config.Mattermost != nil {
+		channels = append(channels, mattermostChannel{config: *config.Mattermost})
+	}
+	return channels
+}
+
+// DispatchAlerts sends payload on all channels concurrently, so a hanging channel does not delay
+// the others. Each channel has alertChannelTimeout to deliver; the failures are logged and
+// returned as *AlertChannelError.
+func DispatchAlerts(ctx context.Context, channels []AlertChannel, payload AlertPayload) []error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	for _, channel := range channels {
+		wg.Add(1)
+		go func(channel AlertChannel) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, alertChannelTimeout)
+			defer cancel()
+
+			// Channels that ignore the context keep running in the background
+			done := make(chan error, 1)
+			go func() { done <- channel.Send(ctx, payload) }()
+			var err error
+			select {
+			case err = <-done:
+			case <-ctx.Done():
+				err = fmt.Errorf("timed out: %w", ctx.Err())
+			}
+			if err == nil {
+				return
+			}
+
+			channelErr := &AlertChannelError{Channel: channel.Name(), Err: err}
+			log.Printf("%v\n", channelErr)
+			mu.Lock()
+			errs = append(errs, channelErr)
+			mu.Unlock()
+		}(channel)
+	}
+	wg.Wait()
+	return errs
+}
+
+// dispatchFailed reports whether the named channel is among the DispatchAlerts errors
+func dispatchFailed(errs []error, channel string) bool {
+	for _, err := range errs {
+		var channelErr *AlertChannelError
+		if errors.As(err, &channelErr) && channelErr.Channel == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// dnsTimeout bounds a single DNS resolution check
+const dnsTimeout = 5 * time.Second
+
+// DNSCheck is a single entry of the dns_checks config
+type DNSCheck struct {
+	Hostname     string `json:"hostname"`
+	Server       string `json:"server"` // e.g. "1.1.1.1" or "10.0.0.2:53"; empty uses the system resolver
+	MaxLatencyMs int    `json:"max_latency_ms"`
+}
+
+// DNSStat holds the result of resolving a hostname
+type DNSStat struct {
+	Hostname string        `json:"hostname"`
+	Server   string        `json:"server"`
+	Latency  time.Duration `json:"latency"`
+	IPs      []string      `json:"ips"`
+}
+
+// CheckDNSResolution resolves hostname through the given DNS server and measures how long it took
+func CheckDNSResolution(ctx context.Context, hostname string, server string, timeout time.Duration) (DNSStat, error) {
+	stat := DNSStat{Hostname: hostname, Server: server}
+
+	resolver := net.DefaultResolver
+	if server != "" {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		stat.Server = server
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				// Ignore the system-configured address and always query the requested server
+				dialer := net.Dialer{Timeout: timeout}
+				return dialer.DialContext(ctx, network, server)
+			},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	ips, err := resolver.LookupHost(ctx, hostname)
+	stat.Latency = time.Since(start)
+	if err != nil {
+		return stat, fmt.Errorf("Error resolving %s: %w", hostname, err)
+	}
+
+	stat.IPs = ips
+	return stat, nil
+}
+
+// dnsServerName returns a printable name for a DNS server address
+func dnsServerName(server string) string {
+	if server == "" {
+		return "the system resolver"
+	}
+	return server
+}
@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GPIOTempSensor is a single entry of the gpio_temp_sensors config
+type GPIOTempSensor struct {
+	ID       string  `json:"id"`         // 1-Wire device ID, e.g. 28-0316a2794aff
+	Name     string  `json:"name"`       // e.g. ambient; defaults to the ID
+	MaxTempC float64 `json:"max_temp_c"` // alert threshold; 0 disables the alert
+}
+
+// GPIOTempStat is the reading of a 1-Wire temperature sensor
+type GPIOTempStat struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Celsius  float64 `json:"celsius"`
+	MaxTempC float64 `json:"max_temp_c,omitempty"`
+}
+
+// w1DevicesDir returns the sysfs directory of the 1-Wire bus devices
+func w1DevicesDir() string {
+	return filepath.Join(sysfsRoot, "bus", "w1", "devices")
+}
+
+// DiscoverDS18B20Sensors returns the DS18B20 sensors on the 1-Wire bus (family code 28)
+func DiscoverDS18B20Sensors() ([]GPIOTempSensor, error) {
+	paths, err := filepath.Glob(filepath.Join(w1DevicesDir(), "28-*"))
+	if err != nil {
+		return nil, fmt.Errorf("Error listing 1-Wire devices: %w", err)
+	}
+	sensors := make([]GPIOTempSensor, 0, len(paths))
+	for _, path := range paths {
+		id := filepath.Base(path)
+		sensors = append(sensors, GPIOTempSensor{ID: id, Name: id})
+	}
+	return sensors, nil
+}
+
+// GetDS18B20Temperature reads a DS18B20 sensor through the w1_therm driver in °C
+func GetDS18B20Temperature(sensorID string) (float64, error) {
+	data, err := os.ReadFile(filepath.Join(w1DevicesDir(), sensorID, "w1_slave"))
+	if err != nil {
+		return 0, fmt.Errorf("Error reading DS18B20 sensor %s: %w", sensorID, err)
+	}
+	return parseW1Slave(string(data))
+}
+
+// parseW1Slave parses the w1_slave file of the w1_therm driver:
+//
+//	72 01 4b 46 7f ff 0e 10 57 : crc=57 YES
+//	72 01 4b 46 7f ff 0e 10 57 t=23125
+func parseW1Slave(data string) (float64, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("could not parse DS18B20 reading %q", data)
+	}
+	// A failed CRC means the reading was corrupted on the bus
+	if !strings.HasSuffix(strings.TrimSpace(lines[0]), "YES") {
+		return 0, fmt.Errorf("DS18B20 reading failed the CRC check")
+	}
+	_, milliC, ok := strings.Cut(lines[1], "t=")
+	if !ok {
+		return 0, fmt.Errorf("could not parse DS18B20 reading %q", data)
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(milliC), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse DS18B20 temperature: %w", err)
+	}
+	return float64(value) / 1000, nil
+}
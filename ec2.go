@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+)
+
+const (
+	ec2DetectTimeout   = 100 * time.Millisecond // bounds the first metadata request, which fails quickly when not on EC2
+	ec2MetadataTimeout = 2 * time.Second
+)
+
+// ErrNotEC2 is returned when the instance metadata service is not reachable
+var ErrNotEC2 = errors.New("not running on EC2")
+
+// EC2Meta identifies the EC2 instance the monitor runs on
+type EC2Meta struct {
+	InstanceID       string `json:"instance_id"`
+	InstanceType     string `json:"instance_type"`
+	AvailabilityZone string `json:"availability_zone"`
+	PublicHostname   string `json:"public_hostname,omitempty"` // empty without a public IP address
+}
+
+// String returns e.g. "i-0abc123 (t3.micro in eu-west-1a)"
+func (m EC2Meta) String() string {
+	return fmt.Sprintf("%s (%s in %s)", m.InstanceID, m.InstanceType, m.AvailabilityZone)
+}
+
+// GetEC2Metadata reads the instance metadata from IMDSv2: the client requests a session
+// token with PUT /latest/api/token and sends it with every metadata GET. It returns
+// ErrNotEC2 if the metadata service does not answer within 100ms.
+func GetEC2Metadata() (EC2Meta, error) {
+	client := imds.New(imds.Options{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), ec2DetectTimeout)
+	instanceID, err := getEC2MetadataValue(ctx, client, "instance-id")
+	cancel()
+	if err != nil {
+		return EC2Meta{}, ErrNotEC2
+	}
+	meta := EC2Meta{InstanceID: instanceID}
+
+	ctx, cancel = context.WithTimeout(context.Background(), ec2MetadataTimeout)
+	defer cancel()
+	if meta.InstanceType, err = getEC2MetadataValue(ctx, client, "instance-type"); err != nil {
+		return meta, fmt.Errorf("Error fetching EC2 instance type: %w", err)
+	}
+	if meta.AvailabilityZone, err = getEC2MetadataValue(ctx, client, "placement/availability-zone"); err != nil {
+		return meta, fmt.Errorf("Error fetching EC2 availability zone: %w", err)
+	}
+	// Instances without a public IP address have no public hostname
+	meta.PublicHostname, _ = getEC2MetadataValue(ctx, client, "public-hostname")
+	return meta, nil
+}
+
+// getEC2MetadataValue reads a single metadata path, e.g. instance-id
+func getEC2MetadataValue(ctx context.Context, client *imds.Client, path string) (string, error) {
+	out, err := client.GetMetadata(ctx, &imds.GetMetadataInput{Path: path})
+	if err != nil {
+		return "", err
+	}
+	defer out.Content.Close()
+	value, err := io.ReadAll(out.Content)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// ec2Metadata is looked up once; the error is ErrNotEC2 when not running on EC2
+var ec2Metadata = sync.OnceValues(GetEC2Metadata)
@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// edacDriverDir is the platform driver directory of the Intel E3-1200 memory controller
+const edacDriverDir = "bus/platform/drivers/ie31200_edac"
+
+// ErrEDACNotAvailable is returned when the system does not expose EDAC error counters
+var ErrEDACNotAvailable = errors.New("EDAC memory error counters are not available on this system")
+
+// EDACStats holds the ECC memory error counts summed over all memory controllers since boot
+type EDACStats struct {
+	CorrectableErrors   uint64    `json:"correctable_errors"`
+	UncorrectableErrors uint64    `json:"uncorrectable_errors"`
+	SampledAt           time.Time `json:"-"` // when the counters were read, for rates between samples
+}
+
+// GetEDACStats reads the correctable (per channel) and uncorrectable (per controller) ECC error counters
+func GetEDACStats() (EDACStats, error) {
+	stats := EDACStats{SampledAt: time.Now()}
+
+	mcDir := filepath.Join(sysfsRoot, edacDriverDir, "*", "mc", "mc*")
+	controllers, err := filepath.Glob(mcDir)
+	if err != nil {
+		return stats, fmt.Errorf("Error listing EDAC memory controllers: %w", err)
+	}
+	if len(controllers) == 0 {
+		return stats, ErrEDACNotAvailable
+	}
+
+	ceCounters, err := filepath.Glob(filepath.Join(mcDir, "csrow*", "ch*_ce_count"))
+	if err != nil {
+		return stats, fmt.Errorf("Error listing EDAC error counters: %w", err)
+	}
+	for _, path := range ceCounters {
+		count, err := readSysfsUint(path)
+		if err != nil {
+			return stats, fmt.Errorf("Error reading EDAC correctable error count: %w", err)
+		}
+		stats.CorrectableErrors += count
+	}
+
+	for _, mc := range controllers {
+		count, err := readSysfsUint(filepath.Join(mc, "ue_count"))
+		if err != nil {
+			return stats, fmt.Errorf("Error reading EDAC uncorrectable error count: %w", err)
+		}
+		stats.UncorrectableErrors += count
+	}
+
+	return stats, nil
+}
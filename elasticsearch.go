@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ElasticsearchCluster is a single entry of the elasticsearch_clusters config
+type ElasticsearchCluster struct {
+	URL                string `json:"url"`
+	Username           string `json:"username"`
+	Password           string `json:"password"`
+	WarnOnYellow       bool   `json:"warn_on_yellow"`       // also alert on yellow (replicas unassigned)
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"` // accept self-signed certificates of internal clusters
+}
+
+// ESHealth is the response of the _cluster/health API
+type ESHealth struct {
+	URL              string `json:"url"`
+	ClusterName      string `json:"cluster_name"`
+	Status           string `json:"status"` // green, yellow or red
+	NumberOfNodes    int    `json:"number_of_nodes"`
+	ActiveShards     int    `json:"active_shards"`
+	RelocatingShards int    `json:"relocating_shards"`
+	UnassignedShards int    `json:"unassigned_shards"`
+}
+
+// GetElasticsearchHealth fetches the health of an Elasticsearch cluster
+func GetElasticsearchHealth(ctx context.Context, cluster ElasticsearchCluster) (ESHealth, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(cluster.URL, "/")+"/_cluster/health", nil)
+	if err != nil {
+		return ESHealth{}, fmt.Errorf("Error creating Elasticsearch request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent())
+	if cluster.Username != "" {
+		req.SetBasicAuth(cluster.Username, cluster.Password)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: cluster.InsecureSkipVerify}
+	client := &http.Client{Timeout: serviceCheckTimeout, Transport: transport}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ESHealth{}, fmt.Errorf("Error fetching Elasticsearch health: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ESHealth{}, fmt.Errorf("Elasticsearch returned %s", resp.Status)
+	}
+
+	var health ESHealth
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return ESHealth{}, fmt.Errorf("could not parse Elasticsearch health: %w", err)
+	}
+	health.URL = cluster.URL
+	return health, nil
+}
@@ -0,0 +1,204 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultLogAttachmentBytes is how much of the end of a log file is attached unless max_bytes is set
+const defaultLogAttachmentBytes = 64 << 10
+
+// LogAttachment is a single entry of the attach_logs config
+type LogAttachment struct {
+	Path     string   `json:"path"`
+	OnAlerts []string `json:"on_alerts"` // metrics (e.g. cpu_usage, or cpu for all cpu_* metrics) it is attached for; empty for all
+	MaxBytes int64    `json:"max_bytes"` // attached from the end of the file; defaults to 64 KiB
+}
+
+// Matches reports whether the log is attached to an email with the given alerts
+func (a LogAttachment) Matches(alerts []AlertEntry) bool {
+	if len(alerts) == 0 {
+		return false
+	}
+	if len(a.OnAlerts) == 0 {
+		return true
+	}
+	for _, alert := range alerts {
+		for _, metric := range a.OnAlerts {
+			if alert.Metric == metric || strings.HasPrefix(alert.Metric, metric+"_") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// EmailMessage is an email before it is encoded. Charts are embedded inline in an HTML version
+// of the body; attachments are added as separate parts.
+type EmailMessage struct {
+	Subject     string
+	Body        string
+	Charts      []EmailChart
+	Attachments []EmailAttachment
+}
+
+// AttachLogFile attaches the last maxBytes bytes of the log file at path as text/plain.
+// A line cut off at the start is dropped.
+func AttachLogFile(emailMsg *EmailMessage, path string, maxBytes int64) error {
+	if maxBytes <= 0 {
+		maxBytes = defaultLogAttachmentBytes
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not attach log file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("could not attach log file: %w", err)
+	}
+	offset := max(0, info.Size()-maxBytes)
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, info.Size()-offset))
+	if err != nil {
+		return fmt.Errorf("could not attach log file: %w", err)
+	}
+	if offset > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	emailMsg.Attachments = append(emailMsg.Attachments, EmailAttachment{
+		Filename:    filepath.Base(path),
+		ContentType: "text/plain; charset=utf-8",
+		Data:        data,
+	})
+	return nil
+}
+
+// Bytes encodes the message with the given Cc header. Messages without charts and
+// attachments are sent as plain text.
+func (m *EmailMessage) Bytes(cc []string) ([]byte, error) {
+	if len(m.Charts) == 0 && len(m.Attachments) == 0 {
+		return buildEmailMessage(m.Subject, m.Body, cc), nil
+	}
+
+	contentType, content, err := m.bodyPart()
+	if err != nil {
+		return nil, fmt.Errorf("could not build email: %w", err)
+	}
+	if len(m.Attachments) > 0 {
+		var mixed bytes.Buffer
+		mixedWriter := multipart.NewWriter(&mixed)
+		part, err := mixedWriter.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+		if err != nil {
+			return nil, fmt.Errorf("could not build email: %w", err)
+		}
+		part.Write(content)
+		for _, attachment := range m.Attachments {
+			part, err := mixedWriter.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {attachment.ContentType},
+				"Content-Transfer-Encoding": {"base64"},
+				"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", attachment.Filename)},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("could not build email: %w", err)
+			}
+			writeBase64Lines(part, attachment.Data)
+		}
+		mixedWriter.Close()
+		contentType, content = "multipart/mixed; boundary="+mixedWriter.Boundary(), mixed.Bytes()
+	}
+
+	headers := "Subject: " + m.Subject + "\n"
+	if len(cc) > 0 {
+		headers += "Cc: " + strings.Join(cc, ", ") + "\n"
+	}
+	headers += "MIME-Version: 1.0\nContent-Type: " + contentType + "\n\n"
+	return append([]byte(headers), content...), nil
+}
+
+// chartEmailTemplate is the HTML body of emails with charts
+var chartEmailTemplate = template.Must(template.New("email").Parse(`<html><body>
+<pre style="font-family: monospace">{{.Body}}</pre>
+{{range .Charts}}<p>{{.Metric}}{{if .Unit}} ({{.Unit}}){{end}}, last hour<br>
+<img src="cid:{{.ContentID}}" alt="{{.Metric}}"></p>
+{{end}}</body></html>
+`))
+
+// bodyPart returns the content type and content of the body: plain text, or with charts a
+// multipart/alternative of the plain body and an HTML body that references the charts as
+// inline images (multipart/related)
+func (m *EmailMessage) bodyPart() (string, []byte, error) {
+	if len(m.Charts) == 0 {
+		return "text/plain; charset=utf-8", []byte(m.Body), nil
+	}
+
+	var buf bytes.Buffer
+	alternative := multipart.NewWriter(&buf)
+	text, err := alternative.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return "", nil, err
+	}
+	text.Write([]byte(m.Body))
+
+	// The HTML body and the images it references
+	var related bytes.Buffer
+	relatedWriter := multipart.NewWriter(&related)
+	html, err := relatedWriter.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=utf-8"}})
+	if err != nil {
+		return "", nil, err
+	}
+	if err := chartEmailTemplate.Execute(html, m); err != nil {
+		return "", nil, err
+	}
+	for _, chart := range m.Charts {
+		image, err := relatedWriter.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {"image/png"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-ID":                {"<" + chart.ContentID + ">"},
+			"Content-Disposition":       {fmt.Sprintf("inline; filename=%q", chart.Metric+".png")},
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		writeBase64Lines(image, chart.PNG)
+	}
+	relatedWriter.Close()
+
+	part, err := alternative.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/related; boundary=" + relatedWriter.Boundary()},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	part.Write(related.Bytes())
+	alternative.Close()
+	return "multipart/alternative; boundary=" + alternative.Boundary(), buf.Bytes(), nil
+}
+
+// writeBase64Lines writes data base64 encoded in lines of at most 76 characters, as MIME requires
+func writeBase64Lines(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		io.WriteString(w, encoded[:76]+"\r\n")
+		encoded = encoded[76:]
+	}
+	io.WriteString(w, encoded+"\r\n")
+}
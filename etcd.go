@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// etcdHealthKey is the key read to verify that the cluster can serve linearizable reads,
+// the same one used by 'etcdctl endpoint health'
+const etcdHealthKey = "health"
+
+// EtcdCheck is a single entry of the etcd_checks config
+type EtcdCheck struct {
+	Endpoints      []string        `json:"endpoints"` // e.g. ["https://etcd1:2379", "https://etcd2:2379"]
+	TLSConfig      TLSClientConfig `json:"tls"`
+	MaxDBSizeBytes int64           `json:"max_db_size_bytes"`
+}
+
+// EtcdEndpointStat is the status of a single etcd endpoint
+type EtcdEndpointStat struct {
+	Endpoint    string `json:"endpoint"`
+	MemberID    string `json:"member_id,omitempty"`
+	IsLeader    bool   `json:"is_leader"`
+	DBSizeBytes int64  `json:"db_size_bytes"`
+	Error       string `json:"error,omitempty"` // set when the endpoint is unreachable or reports alarms
+}
+
+// EtcdStat holds the health of an etcd cluster
+type EtcdStat struct {
+	Members   int                `json:"members"`
+	HasLeader bool               `json:"has_leader"`
+	Endpoints []EtcdEndpointStat `json:"endpoints"`
+}
+
+// UnhealthyEndpoints returns the endpoints that are unreachable or report errors
+func (s EtcdStat) UnhealthyEndpoints() []EtcdEndpointStat {
+	var unhealthy []EtcdEndpointStat
+	for _, endpoint := range s.Endpoints {
+		if endpoint.Error != "" {
+			unhealthy = append(unhealthy, endpoint)
+		}
+	}
+	return unhealthy
+}
+
+// EndpointNames returns the endpoints in the order they were configured
+func (s EtcdStat) EndpointNames() []string {
+	names := make([]string, 0, len(s.Endpoints))
+	for _, endpoint := range s.Endpoints {
+		names = append(names, endpoint.Endpoint)
+	}
+	return names
+}
+
+// CheckEtcdHealth verifies that the cluster serves linearizable reads, counts its members and
+// queries the status (leader and database size) of every configured endpoint
+func CheckEtcdHealth(ctx context.Context, cfg EtcdCheck) (EtcdStat, error) {
+	var stat EtcdStat
+
+	clientConfig := clientv3.Config{Endpoints: cfg.Endpoints, DialTimeout: serviceCheckTimeout}
+	if cfg.TLSConfig.Enabled() {
+		tlsConfig, err := cfg.TLSConfig.Load()
+		if err != nil {
+			return stat, err
+		}
+		clientConfig.TLS = tlsConfig
+	}
+	client, err := clientv3.New(clientConfig)
+	if err != nil {
+		return stat, fmt.Errorf("Error creating etcd client: %w", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+
+	// Reads are linearizable by default and fail without a quorum
+	if _, err := client.Get(ctx, etcdHealthKey); err != nil {
+		return stat, fmt.Errorf("Error reading from etcd: %w", err)
+	}
+
+	members, err := client.MemberList(ctx)
+	if err != nil {
+		return stat, fmt.Errorf("Error listing etcd members: %w", err)
+	}
+	stat.Members = len(members.Members)
+
+	for _, endpoint := range cfg.Endpoints {
+		endpointStat := EtcdEndpointStat{Endpoint: endpoint}
+		status, err := client.Status(ctx, endpoint)
+		if err != nil {
+			endpointStat.Error = err.Error()
+			stat.Endpoints = append(stat.Endpoints, endpointStat)
+			continue
+		}
+
+		endpointStat.MemberID = strconv.FormatUint(status.Header.MemberId, 16)
+		endpointStat.IsLeader = status.Leader == status.Header.MemberId
+		endpointStat.DBSizeBytes = status.DbSize
+		if len(status.Errors) > 0 {
+			endpointStat.Error = fmt.Sprint(status.Errors)
+		}
+		if status.Leader != 0 {
+			stat.HasLeader = true
+		}
+		stat.Endpoints = append(stat.Endpoints, endpointStat)
+	}
+
+	return stat, nil
+}
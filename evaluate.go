@@ -0,0 +1,1149 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// evaluateMetrics checks a collected snapshot against the thresholds and returns the alerts. prev
+// is the snapshot of the previous run, which rates like the correctable ECC error rate are
+// measured against, and outcomes are those of the collection of snap. ctx bounds the commands
+// that add details to an alert, like ethtool and hubble; once it is done they are skipped.
+func evaluateMetrics(ctx context.Context, config Config, metrics MetricSet, snap, prev MetricSnapshot, outcomes []collectionOutcome) []AlertEntry {
+	var alerts []AlertEntry
+	thresholds := snap.Thresholds
+	unit := snap.TemperatureUnit
+
+	// A failed collection leaves a zero reading behind, which must not be checked
+	collected := func(metric string) bool {
+		return !slices.ContainsFunc(outcomes, func(outcome collectionOutcome) bool {
+			return outcome.Metric == metric && outcome.Err != nil
+		})
+	}
+
+	// Check the CPU and Ambient Temperature
+	if metrics.Has("cpu_temperature") {
+		temps := snap.CPUTemperature
+		ambientContext := ""
+		if ambient := snap.AmbientTemperature; ambient != nil {
+			ambientContext = fmt.Sprintf(" (ambient: %s)", unit.Format(*ambient, 2))
+
+			if *ambient > maxAmbientTempC {
+				// Without a CPU reading the message would claim a CPU temperature of 0
+				cpuContext := ""
+				if collected("cpu_temperature") {
+					cpuContext = fmt.Sprintf(" (CPU: %s)", unit.Format(temps, 2))
+				}
+				alerts = append(alerts, AlertEntry{
+					Metric:    "ambient_temperature",
+					Value:     unit.FromCelsius(*ambient),
+					Threshold: unit.FromCelsius(maxAmbientTempC),
+					Message:   fmt.Sprintf("Alert: Ambient temperature is above %s: %s%s", unit.Format(maxAmbientTempC, 0), unit.Format(*ambient, 2), cpuContext),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "Ambient Temperature: %s (Safe)\n", unit.Format(*ambient, 2))
+			}
+		}
+
+		if collected("cpu_temperature") {
+			if temps > maxTemp || temps < minTemp {
+				threshold := maxTemp
+				if temps < minTemp {
+					threshold = minTemp
+				}
+				alerts = append(alerts, AlertEntry{
+					Metric:    "cpu_temperature",
+					Value:     unit.FromCelsius(temps),
+					Threshold: unit.FromCelsius(threshold),
+					Message:   fmt.Sprintf("Alert: CPU Temperature is out of safe range: %s%s", unit.Format(temps, 2), ambientContext),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "CPU Temperature: %s (Safe)\n", unit.Format(temps, 2))
+			}
+		}
+
+		for _, socket := range sortedSockets(snap.SocketTemperatures) {
+			celsius := snap.SocketTemperatures[socket]
+			if snap.SocketMaxTempC > 0 && celsius > snap.SocketMaxTempC {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "cpu_temperature",
+					Value:     unit.FromCelsius(celsius),
+					Threshold: unit.FromCelsius(snap.SocketMaxTempC),
+					Message:   fmt.Sprintf("Alert: CPU socket %d temperature is above %s: %s%s", socket, unit.Format(snap.SocketMaxTempC, 0), unit.Format(celsius, 2), ambientContext),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "CPU Socket %d Temperature: %s (Safe)\n", socket, unit.Format(celsius, 2))
+			}
+		}
+	}
+
+	// Check the GPU Temperature
+	if metrics.Has("gpu_temperature") && snap.GPUTemperature != nil {
+		gpuTemp := *snap.GPUTemperature
+		if gpuTemp > maxGPUTempC {
+			alerts = append(alerts, AlertEntry{
+				Metric:    "gpu_temperature",
+				Value:     unit.FromCelsius(gpuTemp),
+				Threshold: unit.FromCelsius(maxGPUTempC),
+				Message:   fmt.Sprintf("Alert: GPU temperature is above %s: %s", unit.Format(maxGPUTempC, 0), unit.Format(gpuTemp, 2)),
+			})
+		} else {
+			fmt.Fprintf(StatusOutput, "GPU Temperature: %s (Safe)\n", unit.Format(gpuTemp, 2))
+		}
+	}
+
+	// Check the 1-Wire Temperature Sensors
+	if metrics.Has("gpio_temperature") {
+		for _, sensor := range snap.GPIOTemperatures {
+			if sensor.MaxTempC > 0 && sensor.Celsius > sensor.MaxTempC {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "gpio_temperature",
+					Value:     unit.FromCelsius(sensor.Celsius),
+					Threshold: unit.FromCelsius(sensor.MaxTempC),
+					Message:   fmt.Sprintf("Alert: Temperature of sensor %s is above %s: %s", sensor.Name, unit.Format(sensor.MaxTempC, 1), unit.Format(sensor.Celsius, 2)),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "Temperature of sensor %s: %s (Safe)\n", sensor.Name, unit.Format(sensor.Celsius, 2))
+			}
+		}
+	}
+
+	// Check the lm-sensors Temperatures Matching the thermal_sensors Patterns
+	if metrics.Has("thermal_sensors") {
+		for _, sensor := range snap.ThermalSensors {
+			if sensor.MaxTempC > 0 && sensor.Celsius > sensor.MaxTempC {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "thermal_sensors",
+					Value:     unit.FromCelsius(sensor.Celsius),
+					Threshold: unit.FromCelsius(sensor.MaxTempC),
+					Message:   fmt.Sprintf("Alert: Temperature of %s is above %s: %s", sensor.Name, unit.Format(sensor.MaxTempC, 1), unit.Format(sensor.Celsius, 2)),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "Temperature of %s: %s (Safe)\n", sensor.Name, unit.Format(sensor.Celsius, 2))
+			}
+		}
+	}
+
+	// Check the Fan Speeds
+	if metrics.Has("fan_speed") {
+		for _, fan := range parseFanReadings(snap.FanSpeeds) {
+			switch {
+			case fan.RPM < minFanSpeed:
+				alerts = append(alerts, AlertEntry{
+					Metric:    "fan_speed",
+					Value:     fan.RPM,
+					Threshold: minFanSpeed,
+					Message:   fmt.Sprintf("Alert: Fan speed of %s is below %d RPM: %.0f RPM", fan.Name, minFanSpeed, fan.RPM),
+				})
+			case fan.RPM > maxFanSpeed:
+				alerts = append(alerts, AlertEntry{
+					Metric:    "fan_speed",
+					Value:     fan.RPM,
+					Threshold: maxFanSpeed,
+					Message:   fmt.Sprintf("Alert: Fan speed of %s is above %d RPM: %.0f RPM", fan.Name, maxFanSpeed, fan.RPM),
+				})
+			default:
+				fmt.Fprintf(StatusOutput, "Fan Speed of %s: %.0f RPM (Safe)\n", fan.Name, fan.RPM)
+			}
+		}
+	}
+
+	// Check the CPU Clock Speed
+	if metrics.Has("cpu_clock") {
+		for _, ghz := range snap.CPUClockSpeeds {
+			if ghz < maxClockSpeed {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "cpu_clock",
+					Value:     ghz,
+					Threshold: maxClockSpeed,
+					Message:   fmt.Sprintf("Alert: CPU Clock Speed is below 3.20 GHz: %.2f GHz", ghz),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "CPU Clock Speed: %.2f GHz (Safe)\n", ghz)
+			}
+		}
+	}
+
+	// Check the CPU Usage
+	if metrics.Has("cpu_usage") {
+		alerts = append(alerts, cpuUsageAlerts(snap.CPUUsage, thresholds.CPUUsage)...)
+	}
+
+	// Check the CPU Power Consumption
+	if metrics.Has("cpu_power") {
+		for _, domain := range snap.CPUPower {
+			if !domain.IsPackage() {
+				fmt.Fprintf(StatusOutput, "CPU power (%s): %.2f W\n", domain.Name, domain.Watts)
+				continue
+			}
+			if domain.Watts > maxPackagePowerW {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "cpu_power",
+					Value:     domain.Watts,
+					Threshold: maxPackagePowerW,
+					Message:   fmt.Sprintf("Alert: CPU power draw (%s) is above %.0f W: %.2f W", domain.Name, maxPackagePowerW, domain.Watts),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "CPU power (%s): %.2f W (Safe)\n", domain.Name, domain.Watts)
+			}
+		}
+	}
+
+	// Check the CPU Thermal Throttling
+	if metrics.Has("cpu_throttle") {
+		for _, stat := range snap.CPUThrottle {
+			if stat.EventsPerSec > maxThrottleEventsPerSec {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "cpu_throttle",
+					Value:     stat.EventsPerSec,
+					Threshold: maxThrottleEventsPerSec,
+					Message: fmt.Sprintf("Alert: CPU Core %d is being thermally throttled: %.2f events/s (%d throttle events since boot)",
+						stat.CPU, stat.EventsPerSec, stat.TotalCount),
+				})
+			}
+		}
+	}
+
+	// Check the ECC Memory Errors
+	if metrics.Has("edac") && snap.EDAC != nil {
+		edacStats := *snap.EDAC
+
+		// Any uncorrectable error means data was lost, so it is sent right away. The counter only
+		// resets at boot, so only new errors alert, besides those found by the first run.
+		if edacStats.UncorrectableErrors > 0 && (prev.EDAC == nil || edacStats.UncorrectableErrors > prev.EDAC.UncorrectableErrors) {
+			alerts = append(alerts, AlertEntry{
+				Metric:    "edac",
+				Value:     float64(edacStats.UncorrectableErrors),
+				Message:   fmt.Sprintf("Alert: %d uncorrectable ECC memory errors since boot", edacStats.UncorrectableErrors),
+				Immediate: true,
+			})
+		}
+
+		// The correctable error rate is measured against the previous run
+		if prev.EDAC != nil && edacStats.CorrectableErrors >= prev.EDAC.CorrectableErrors {
+			rate := float64(edacStats.CorrectableErrors-prev.EDAC.CorrectableErrors) / edacStats.SampledAt.Sub(prev.EDAC.SampledAt).Hours()
+			if rate > maxCorrectableErrorsPerHour {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "edac",
+					Value:     rate,
+					Threshold: maxCorrectableErrorsPerHour,
+					Message: fmt.Sprintf("Alert: Correctable ECC memory error rate is above %.0f/h: %.2f/h (%d since boot)",
+						maxCorrectableErrorsPerHour, rate, edacStats.CorrectableErrors),
+				})
+			}
+		}
+		if edacStats.UncorrectableErrors == 0 {
+			fmt.Fprintf(StatusOutput, "ECC Memory Errors: %d correctable, 0 uncorrectable (Safe)\n", edacStats.CorrectableErrors)
+		}
+	}
+
+	// Check the BMC Hardware Sensors
+	if metrics.Has("ipmi") && len(snap.IPMI) > 0 {
+		critical := 0
+		for _, sensor := range snap.IPMI {
+			if !sensor.Critical() {
+				continue
+			}
+			critical++
+			reading := "no reading"
+			if sensor.Reading != nil {
+				reading = fmt.Sprintf("%.3f %s", *sensor.Reading, sensor.Unit)
+			}
+			alerts = append(alerts, AlertEntry{
+				Metric:  "ipmi",
+				Message: fmt.Sprintf("Alert: IPMI sensor %s is %s: %s (%s)", sensor.Name, ipmiStatusName(sensor.Status), reading, ipmiThresholds(sensor)),
+			})
+		}
+		if critical == 0 {
+			fmt.Fprintf(StatusOutput, "IPMI sensors: %d ok (Safe)\n", len(snap.IPMI))
+		}
+	}
+
+	// Check the Memory Usage and Kernel Same-page Merging
+	if metrics.Has("memory") {
+		if collected("memory") {
+			alerts = append(alerts, memoryUsageAlerts(snap.MemoryUsedPercent, thresholds.MemoryUsage)...)
+		}
+		if ksm := snap.KSM; ksm != nil {
+			if !ksm.Running {
+				alerts = append(alerts, AlertEntry{
+					Metric:  "ksm_saved",
+					Message: "Alert: KSM is disabled (/sys/kernel/mm/ksm/run is not 1)",
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "KSM: %.2f MiB saved (%d pages shared, %d sharing, %d unshared, %d full scans)\n",
+					float64(ksm.SavedBytes())/(1<<20), ksm.PagesShared, ksm.PagesSharing, ksm.PagesUnshared, ksm.FullScans)
+			}
+		}
+	}
+
+	// Check the Memory Bandwidth
+	if metrics.Has("memory_bandwidth") && snap.MemBandwidth != nil {
+		bandwidth := *snap.MemBandwidth
+		if bandwidth.TotalGBps() > maxMemBandwidthGBps {
+			alerts = append(alerts, AlertEntry{
+				Metric:    "memory_bandwidth",
+				Value:     bandwidth.TotalGBps(),
+				Threshold: maxMemBandwidthGBps,
+				Message: fmt.Sprintf("Alert: Memory bandwidth is above %.0f GB/s: %.2f GB/s (read: %.2f GB/s, write: %.2f GB/s)",
+					maxMemBandwidthGBps, bandwidth.TotalGBps(), bandwidth.ReadGBps, bandwidth.WriteGBps),
+			})
+		} else {
+			fmt.Fprintf(StatusOutput, "Memory Bandwidth: %.2f GB/s (Safe)\n", bandwidth.TotalGBps())
+		}
+	}
+
+	// Check the Huge Page Pool; without a configured pool there is nothing to run out of
+	if metrics.Has("hugepages") && snap.HugePages != nil && snap.HugePages.Total > 0 {
+		hugePages := *snap.HugePages
+		if hugePages.AvailablePercent() < minHugePagesFreePercent {
+			alerts = append(alerts, AlertEntry{
+				Metric:    "hugepages",
+				Value:     hugePages.AvailablePercent(),
+				Threshold: minHugePagesFreePercent,
+				Message: fmt.Sprintf("Alert: Available huge pages are below %.0f%%: %.2f%% (%d of %d free, %d reserved, %d surplus; page size %d kB, configured sizes: %s)",
+					minHugePagesFreePercent, hugePages.AvailablePercent(), hugePages.Free, hugePages.Total, hugePages.Reserved,
+					hugePages.Surplus, hugePages.PageSizeKB, formatHugePageSizes(hugePages.ConfiguredSizes)),
+			})
+		} else {
+			fmt.Fprintf(StatusOutput, "Available huge pages: %.2f%% (Safe)\n", hugePages.AvailablePercent())
+		}
+	}
+
+	// Check the Disk Usage
+	if metrics.Has("disk") && collected("disk") {
+		alerts = append(alerts, diskUsageAlerts(snap.DiskUsedPercent, thresholds.DiskUsage)...)
+	}
+
+	// Check the Disk Latency
+	if metrics.Has("disk_latency") {
+		for _, stat := range snap.DiskLatency {
+			if stat.AwaitMs > maxDiskAwaitMs {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "disk_latency",
+					Value:     stat.AwaitMs,
+					Threshold: maxDiskAwaitMs,
+					Message: fmt.Sprintf("Alert: Disk %s latency is above %.0f ms: %.2f ms (%.0f IOPS, %.0f%% busy, %d in flight)",
+						stat.Device, maxDiskAwaitMs, stat.AwaitMs, stat.IOPS, stat.UtilPercent, stat.InFlight),
+				})
+			}
+		}
+	}
+
+	// Check the tmpfs Usage
+	if metrics.Has("tmpfs") {
+		for _, stat := range snap.Tmpfs {
+			if stat.UsedPercent > maxTmpfsPercent {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "tmpfs",
+					Value:     stat.UsedPercent,
+					Threshold: maxTmpfsPercent,
+					Message: fmt.Sprintf("Alert: tmpfs %s usage is above %.0f%%: %.2f%% (%d of %d MiB)",
+						stat.Path, maxTmpfsPercent, stat.UsedPercent, stat.UsedBytes>>20, stat.TotalBytes>>20),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "tmpfs %s usage: %.2f%% (Safe)\n", stat.Path, stat.UsedPercent)
+			}
+		}
+	}
+
+	// Check the File and Directory Sizes
+	if metrics.Has("file_size") {
+		for _, stat := range snap.PathSizes {
+			i := slices.IndexFunc(config.FileSizeChecks, func(check FileSizeCheck) bool { return check.Path == stat.Path })
+			if i < 0 {
+				continue
+			}
+			check := config.FileSizeChecks[i]
+			if check.MaxSizeMB > 0 && stat.SizeMB() > float64(check.MaxSizeMB) {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "file_size",
+					Value:     stat.SizeMB(),
+					Threshold: float64(check.MaxSizeMB),
+					Message:   fmt.Sprintf("Alert: %s is above %d MiB: %.2f MiB", check.Path, check.MaxSizeMB, stat.SizeMB()),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "Size of %s: %.2f MiB (Safe)\n", check.Path, stat.SizeMB())
+			}
+		}
+	}
+
+	// Check the Disk Quotas of Users and Groups
+	if metrics.Has("disk_quota") {
+		for _, quota := range snap.DiskQuotas {
+			switch {
+			case quota.OverHardLimit():
+				alerts = append(alerts, AlertEntry{
+					Metric:    "disk_quota",
+					Value:     float64(quota.UsedKB) / 1024,
+					Threshold: float64(quota.HardLimitKB) / 1024,
+					Message: fmt.Sprintf("Alert: %s %s reached the hard quota on %s: %d of %d MiB",
+						quota.Type, quota.Name, quota.Device, quota.UsedKB/1024, quota.HardLimitKB/1024),
+				})
+			case quota.OverSoftLimit():
+				alerts = append(alerts, AlertEntry{
+					Metric:    "disk_quota",
+					Value:     float64(quota.UsedKB) / 1024,
+					Threshold: float64(quota.SoftLimitKB) / 1024,
+					Message: fmt.Sprintf("Alert: %s %s is over the soft quota on %s: %d of %d MiB (grace period ends in %s)",
+						quota.Type, quota.Name, quota.Device, quota.UsedKB/1024, quota.SoftLimitKB/1024,
+						time.Duration(quota.GraceSeconds)*time.Second),
+				})
+			}
+		}
+	}
+
+	// Check the SELinux and AppArmor Enforcement
+	if metrics.Has("mac") && snap.MAC != nil {
+		macStatus := *snap.MAC
+		safe := true
+		if config.RequireSELinuxEnforcing && macStatus.SELinux != macEnforcing {
+			safe = false
+			alerts = append(alerts, AlertEntry{
+				Metric:  "mac",
+				Message: fmt.Sprintf("Alert: SELinux is not enforcing: current mode is %s", macStatus.SELinux),
+			})
+		}
+		if config.RequireAppArmorEnabled && macStatus.AppArmor != macEnabled {
+			safe = false
+			alerts = append(alerts, AlertEntry{
+				Metric:  "mac",
+				Message: fmt.Sprintf("Alert: AppArmor is not enabled: current state is %s", macStatus.AppArmorMode()),
+			})
+		}
+		if safe {
+			fmt.Fprintf(StatusOutput, "Security Policy: SELinux %s, AppArmor %s (Safe)\n", macStatus.SELinux, macStatus.AppArmorMode())
+		}
+	}
+
+	// Check the DNS Resolution Latency; every check has an entry, failed lookups one without IPs
+	if metrics.Has("dns") {
+		for i, stat := range snap.DNS {
+			if i >= len(config.DNSChecks) || len(stat.IPs) == 0 {
+				continue
+			}
+			check := config.DNSChecks[i]
+			latencyMs := float64(stat.Latency) / float64(time.Millisecond)
+			if check.MaxLatencyMs > 0 && latencyMs > float64(check.MaxLatencyMs) {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "dns",
+					Value:     latencyMs,
+					Threshold: float64(check.MaxLatencyMs),
+					Message: fmt.Sprintf("Alert: DNS resolution of %s via %s is above %d ms: %.2f ms",
+						check.Hostname, dnsServerName(stat.Server), check.MaxLatencyMs, latencyMs),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "DNS resolution of %s: %.2f ms (Safe)\n", check.Hostname, latencyMs)
+			}
+		}
+	}
+
+	// Check the Route to Critical Hosts; every check has an entry, failed traceroutes one with an error
+	if metrics.Has("traceroute") {
+		for i, stat := range snap.Traceroute {
+			if i >= len(config.TracerouteChecks) || stat.Error != "" {
+				continue
+			}
+			check := config.TracerouteChecks[i]
+			if !stat.Reached {
+				alerts = append(alerts, AlertEntry{
+					Metric:  "traceroute",
+					Message: fmt.Sprintf("Alert: %s was not reached within %d hops", check.Host, len(stat.Hops)),
+				})
+				continue
+			}
+
+			// Without a configured baseline the route is compared with the one of the previous run
+			baseline := check.BaselineHops
+			if baseline == 0 {
+				for _, prevStat := range prev.Traceroute {
+					if prevStat.Host == check.Host {
+						baseline = prevStat.HopCount()
+					}
+				}
+			}
+			breached := false
+			if baseline > 0 && stat.HopCount() > baseline+check.MaxExtraHops {
+				breached = true
+				alerts = append(alerts, AlertEntry{
+					Metric:    "traceroute",
+					Value:     float64(stat.HopCount()),
+					Threshold: float64(baseline + check.MaxExtraHops),
+					Message: fmt.Sprintf("Alert: Route to %s grew from %d to %d hops, the route may have changed",
+						check.Host, baseline, stat.HopCount()),
+				})
+			}
+			if hop, ok := stat.SlowestHop(); ok && check.MaxHopRTTMs > 0 {
+				rttMs := float64(hop.RTT) / float64(time.Millisecond)
+				if rttMs > float64(check.MaxHopRTTMs) {
+					breached = true
+					alerts = append(alerts, AlertEntry{
+						Metric:    "traceroute",
+						Value:     rttMs,
+						Threshold: float64(check.MaxHopRTTMs),
+						Message: fmt.Sprintf("Alert: Hop %d (%s%s) on the route to %s is above %d ms: %.2f ms",
+							hop.TTL, hop.Address, geoIPContext(hop.Address, config.GeoIPDBPath), check.Host, check.MaxHopRTTMs, rttMs),
+					})
+				}
+			}
+			if !breached {
+				fmt.Fprintf(StatusOutput, "Route to %s: %d hops (Safe)\n", check.Host, stat.HopCount())
+			}
+		}
+	}
+
+	// Check the Packets Dropped by the Network Interfaces
+	if metrics.Has("nic") {
+		for _, stat := range snap.NICDrops {
+			dropped := stat.RxDroppedPerSec + stat.TxDroppedPerSec
+			if dropped > maxNICDropsPerSec {
+				message := fmt.Sprintf("Alert: Interface %s is dropping more than %.0f packets/s: %.2f/s (RX: %.2f/s, TX: %.2f/s)",
+					stat.Interface, maxNICDropsPerSec, dropped, stat.RxDroppedPerSec, stat.TxDroppedPerSec)
+				if details := nicDetails(ctx, stat.Interface); details != "" {
+					message += "; " + details
+				}
+				alerts = append(alerts, AlertEntry{
+					Metric:    "nic",
+					Value:     dropped,
+					Threshold: maxNICDropsPerSec,
+					Message:   message,
+				})
+			}
+		}
+	}
+
+	// Check the Packets Dropped by Cilium; the drop rate is measured against the previous run
+	if metrics.Has("cilium") && snap.CiliumDrops != nil && prev.CiliumDrops != nil {
+		rates := CiliumDropRates(*prev.CiliumDrops, *snap.CiliumDrops)
+		var total float64
+		for _, rate := range rates {
+			total += rate.DropsPerSec
+		}
+		if total > maxCiliumDropsPerSec {
+			message := fmt.Sprintf("Alert: Cilium is dropping more than %.0f packets/s: %.2f/s (%s)",
+				maxCiliumDropsPerSec, total, formatCiliumDropRates(rates))
+			if ctx.Err() == nil {
+				if flows, err := recentCiliumDrops(ctx); err != nil {
+					log.Printf("%v\n", err)
+				} else if len(flows) > 0 {
+					message += "; recent drops: " + strings.Join(flows, ", ")
+				}
+			}
+			alerts = append(alerts, AlertEntry{
+				Metric:    "cilium",
+				Value:     total,
+				Threshold: maxCiliumDropsPerSec,
+				Message:   message,
+			})
+		} else {
+			fmt.Fprintf(StatusOutput, "Cilium Packet Drops: %.2f/s (Safe)\n", total)
+		}
+	}
+
+	// Check the Certificate Transparency Logs for Certificates Issued Outside the Expected Window
+	if metrics.Has("ct") {
+		for _, info := range snap.CT {
+			i := slices.IndexFunc(config.CTChecks, func(check CTCheck) bool { return check.Domain == info.Domain })
+			// A failed query carries the previous check over, which has nothing new
+			if i < 0 || !collected("ct."+info.Domain) {
+				continue
+			}
+			check := config.CTChecks[i]
+
+			// Certificates are new when crt.sh logged them after the previous check
+			unexpected := 0
+			for _, cert := range NewCertificates(findCTInfo(prev.CT, check.Domain), info) {
+				if inIssuanceWindow(check.IssuanceWindow, cert.LoggedAt) {
+					continue
+				}
+				unexpected++
+				message := fmt.Sprintf("Alert: New certificate for %s in the Certificate Transparency logs: %s", check.Domain, formatCTCertificate(cert))
+				if check.IssuanceWindow != "" {
+					message = fmt.Sprintf("Alert: New certificate for %s issued outside %s: %s", check.Domain, check.IssuanceWindow, formatCTCertificate(cert))
+				}
+				alerts = append(alerts, AlertEntry{Metric: "ct", Message: message})
+			}
+			if unexpected == 0 {
+				fmt.Fprintf(StatusOutput, "Certificate Transparency for %s: %d certificates in the last day (Safe)\n", check.Domain, len(info.Recent))
+			}
+		}
+	}
+
+	// Check the Cron Job Heartbeats
+	if metrics.Has("cron") {
+		for _, stat := range snap.Cron {
+			i := slices.IndexFunc(config.CronChecks, func(check CronCheck) bool { return check.Name == stat.Name })
+			if i < 0 {
+				continue
+			}
+			check := config.CronChecks[i]
+			if stat.Stale {
+				lastRun := "never"
+				if !stat.LastRun.IsZero() {
+					lastRun = stat.LastRun.Format(time.RFC3339)
+				}
+				alerts = append(alerts, AlertEntry{
+					Metric:    "cron",
+					Value:     stat.Staleness.Seconds(),
+					Threshold: float64(check.MaxStalenessSeconds),
+					Message: fmt.Sprintf("Alert: Cron job %s has not succeeded for more than %d seconds (last run: %s)",
+						check.Name, check.MaxStalenessSeconds, lastRun),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "Cron job %s: last run %s ago (Safe)\n", check.Name, stat.Staleness.Round(time.Second))
+			}
+		}
+	}
+
+	// Check the Elasticsearch Cluster Health
+	if metrics.Has("elasticsearch") {
+		for _, health := range snap.Elasticsearch {
+			i := slices.IndexFunc(config.ElasticsearchClusters, func(cluster ElasticsearchCluster) bool { return cluster.URL == health.URL })
+			if i < 0 {
+				continue
+			}
+			cluster := config.ElasticsearchClusters[i]
+			if health.Status == "red" || (health.Status == "yellow" && cluster.WarnOnYellow) {
+				alerts = append(alerts, AlertEntry{
+					Metric: "elasticsearch",
+					Value:  float64(health.UnassignedShards),
+					Message: fmt.Sprintf("Alert: Elasticsearch cluster %s (%s) is %s: %d nodes, %d active, %d relocating, %d unassigned shards",
+						health.ClusterName, cluster.URL, health.Status, health.NumberOfNodes,
+						health.ActiveShards, health.RelocatingShards, health.UnassignedShards),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "Elasticsearch cluster %s: %s (Safe)\n", health.ClusterName, health.Status)
+			}
+		}
+	}
+
+	// Check the PostgreSQL Connections and Replication Lag
+	if metrics.Has("postgres") {
+		for _, stat := range snap.Postgres {
+			i := slices.IndexFunc(config.PostgresChecks, func(check PostgresCheck) bool { return check.Name == stat.Name })
+			if i < 0 {
+				continue
+			}
+			check := config.PostgresChecks[i]
+			safe := true
+			if check.MaxConnections > 0 && stat.Connections > check.MaxConnections {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "postgres",
+					Value:     float64(stat.Connections),
+					Threshold: float64(check.MaxConnections),
+					Message:   fmt.Sprintf("Alert: PostgreSQL %s has more than %d connections: %d", check.Name, check.MaxConnections, stat.Connections),
+				})
+			}
+			if check.MaxReplicationLagSeconds > 0 && stat.ReplicationLagSeconds > float64(check.MaxReplicationLagSeconds) {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "postgres",
+					Value:     stat.ReplicationLagSeconds,
+					Threshold: float64(check.MaxReplicationLagSeconds),
+					Message: fmt.Sprintf("Alert: PostgreSQL %s replication lag is above %d s: %.2f s",
+						check.Name, check.MaxReplicationLagSeconds, stat.ReplicationLagSeconds),
+				})
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "PostgreSQL %s: %d connections, %.2f s replication lag (Safe)\n", check.Name, stat.Connections, stat.ReplicationLagSeconds)
+			}
+		}
+	}
+
+	// Check the Redis Memory and Clients
+	if metrics.Has("redis") {
+		for _, stat := range snap.Redis {
+			i := slices.IndexFunc(config.RedisChecks, func(check RedisCheck) bool { return check.Addr == stat.Addr })
+			if i < 0 {
+				continue
+			}
+			check := config.RedisChecks[i]
+			safe := true
+			if check.MaxMemoryPercent > 0 && stat.MemoryPercent() > check.MaxMemoryPercent {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "redis",
+					Value:     stat.MemoryPercent(),
+					Threshold: check.MaxMemoryPercent,
+					Message: fmt.Sprintf("Alert: Redis %s memory usage is above %.0f%%: %.2f%% (%d of %d bytes)",
+						check.Addr, check.MaxMemoryPercent, stat.MemoryPercent(), stat.UsedMemoryBytes, stat.MaxMemoryBytes),
+				})
+			}
+			if check.MaxConnectedClients > 0 && stat.ConnectedClients > check.MaxConnectedClients {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "redis",
+					Value:     float64(stat.ConnectedClients),
+					Threshold: float64(check.MaxConnectedClients),
+					Message:   fmt.Sprintf("Alert: Redis %s has more than %d connected clients: %d", check.Addr, check.MaxConnectedClients, stat.ConnectedClients),
+				})
+			}
+			if check.MaxBlockedClients > 0 && stat.BlockedClients > check.MaxBlockedClients {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "redis",
+					Value:     float64(stat.BlockedClients),
+					Threshold: float64(check.MaxBlockedClients),
+					Message:   fmt.Sprintf("Alert: Redis %s has more than %d blocked clients: %d", check.Addr, check.MaxBlockedClients, stat.BlockedClients),
+				})
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "Redis %s: %d bytes used, %d clients (Safe)\n", check.Addr, stat.UsedMemoryBytes, stat.ConnectedClients)
+			}
+		}
+
+		// Sentinel-managed masters: disconnected replicas and failovers since the previous run
+		for _, stat := range snap.RedisSentinels {
+			safe := true
+			for _, prevStat := range prev.RedisSentinels {
+				if prevStat.MasterName == stat.MasterName && stat.ConfigEpoch > prevStat.ConfigEpoch {
+					safe = false
+					alerts = append(alerts, AlertEntry{
+						Metric: "redis",
+						Value:  float64(stat.ConfigEpoch),
+						Message: fmt.Sprintf("Alert: Redis Sentinel failed over master %s from %s to %s (config epoch %d)",
+							stat.MasterName, prevStat.MasterAddr, stat.MasterAddr, stat.ConfigEpoch),
+					})
+				}
+			}
+			for _, replica := range stat.Replicas {
+				if replica.Disconnected() {
+					safe = false
+					alerts = append(alerts, AlertEntry{
+						Metric: "redis",
+						Message: fmt.Sprintf("Alert: Redis replica %s of master %s is disconnected (flags: %s, link: %s)",
+							replica.Addr, stat.MasterName, replica.Flags, replica.LinkStatus),
+					})
+				}
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "Redis Sentinel master %s: %s with %d replicas (Safe)\n", stat.MasterName, stat.MasterAddr, len(stat.Replicas))
+			}
+		}
+	}
+
+	// Check the MongoDB Connections and Replica Set Health
+	if metrics.Has("mongo") {
+		for _, stat := range snap.Mongo {
+			i := slices.IndexFunc(config.MongoChecks, func(check MongoCheck) bool { return check.Name == stat.Name })
+			if i < 0 {
+				continue
+			}
+			check := config.MongoChecks[i]
+			safe := true
+			if check.MaxConnectionsPercent > 0 && stat.ConnectionsPercent() > check.MaxConnectionsPercent {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "mongo",
+					Value:     stat.ConnectionsPercent(),
+					Threshold: check.MaxConnectionsPercent,
+					Message: fmt.Sprintf("Alert: MongoDB %s connection usage is above %.0f%%: %.2f%% (%d open, %d available)",
+						check.Name, check.MaxConnectionsPercent, stat.ConnectionsPercent(), stat.CurrentConnections, stat.AvailableConnections),
+				})
+			}
+			if stat.UnhealthyMembers > 0 {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:  "mongo",
+					Value:   float64(stat.UnhealthyMembers),
+					Message: fmt.Sprintf("Alert: MongoDB %s replica set %s has %d unhealthy members", check.Name, stat.ReplicaSet, stat.UnhealthyMembers),
+				})
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "MongoDB %s: %d connections, %d MB resident (Safe)\n", check.Name, stat.CurrentConnections, stat.ResidentMemoryMB)
+			}
+		}
+	}
+
+	// Check the RabbitMQ Queue Depth and Consumers
+	if metrics.Has("rabbitmq") {
+		for _, queue := range snap.RabbitMQ {
+			i := slices.IndexFunc(config.RabbitMQChecks, func(check RabbitMQCheck) bool { return check.ManagementURL == queue.ManagementURL })
+			if i < 0 {
+				continue
+			}
+			check := config.RabbitMQChecks[i]
+			safe := true
+			if check.MaxQueueDepth > 0 && queue.MessagesReady > check.MaxQueueDepth {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "rabbitmq",
+					Value:     float64(queue.MessagesReady),
+					Threshold: float64(check.MaxQueueDepth),
+					Message: fmt.Sprintf("Alert: RabbitMQ queue %s has more than %d messages ready: %d",
+						queue.Name, check.MaxQueueDepth, queue.MessagesReady),
+				})
+			}
+			if queue.Consumers == 0 {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:  "rabbitmq",
+					Message: fmt.Sprintf("Alert: RabbitMQ queue %s has no consumers (%d messages ready)", queue.Name, queue.MessagesReady),
+				})
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "RabbitMQ queue %s: %d messages ready, %d consumers (Safe)\n", queue.Name, queue.MessagesReady, queue.Consumers)
+			}
+		}
+	}
+
+	// Check the Kafka Consumer Group Lag
+	if metrics.Has("kafka") {
+		for _, check := range config.KafkaChecks {
+			safe, checked := true, false
+			var totalLag int64
+			for _, lag := range snap.Kafka {
+				if lag.ConsumerGroup != check.ConsumerGroup {
+					continue
+				}
+				checked = true
+				totalLag += lag.Lag
+				if check.MaxLagMessages > 0 && lag.Lag > check.MaxLagMessages {
+					safe = false
+					alerts = append(alerts, AlertEntry{
+						Metric:    "kafka",
+						Value:     float64(lag.Lag),
+						Threshold: float64(check.MaxLagMessages),
+						Message: fmt.Sprintf("Alert: Kafka consumer group %s lag on %s partition %d is above %d messages: %d",
+							lag.ConsumerGroup, lag.Topic, lag.Partition, check.MaxLagMessages, lag.Lag),
+					})
+				}
+			}
+			if !checked {
+				continue
+			}
+			if check.MaxTotalLagMessages > 0 && totalLag > check.MaxTotalLagMessages {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "kafka",
+					Value:     float64(totalLag),
+					Threshold: float64(check.MaxTotalLagMessages),
+					Message: fmt.Sprintf("Alert: Kafka consumer group %s total lag is above %d messages: %d",
+						check.ConsumerGroup, check.MaxTotalLagMessages, totalLag),
+				})
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "Kafka consumer group %s: %d messages behind (Safe)\n", check.ConsumerGroup, totalLag)
+			}
+		}
+	}
+
+	// Check the MySQL Connections and Replication
+	if metrics.Has("mysql") {
+		for _, stat := range snap.MySQL {
+			i := slices.IndexFunc(config.MySQLChecks, func(check MySQLCheck) bool { return check.Name == stat.Name })
+			if i < 0 {
+				continue
+			}
+			check := config.MySQLChecks[i]
+			safe := true
+			if check.MaxConnections > 0 && stat.ThreadsConnected > check.MaxConnections {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "mysql",
+					Value:     float64(stat.ThreadsConnected),
+					Threshold: float64(check.MaxConnections),
+					Message:   fmt.Sprintf("Alert: MySQL %s has more than %d connected threads: %d", check.Name, check.MaxConnections, stat.ThreadsConnected),
+				})
+			}
+			if stat.IsReplica && stat.SlaveSQLRunning != "Yes" {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric: "mysql",
+					Message: fmt.Sprintf("Alert: MySQL %s replication SQL thread is not running (Slave_SQL_Running: %s)\n%s",
+						check.Name, stat.SlaveSQLRunning, stat.SlaveStatus),
+				})
+			} else if stat.IsReplica && check.MaxSlaveLatencySeconds > 0 && stat.SecondsBehindMaster > int64(check.MaxSlaveLatencySeconds) {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "mysql",
+					Value:     float64(stat.SecondsBehindMaster),
+					Threshold: float64(check.MaxSlaveLatencySeconds),
+					Message: fmt.Sprintf("Alert: MySQL %s is more than %d s behind its master: %d s\n%s",
+						check.Name, check.MaxSlaveLatencySeconds, stat.SecondsBehindMaster, stat.SlaveStatus),
+				})
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "MySQL %s: %d connected threads (Safe)\n", check.Name, stat.ThreadsConnected)
+			}
+		}
+	}
+
+	// Check the AWS RDS Instances
+	if metrics.Has("rds") {
+		for _, stat := range snap.RDS {
+			i := slices.IndexFunc(config.RDSChecks, func(check RDSCheck) bool { return check.DBInstanceIdentifier == stat.DBInstanceIdentifier })
+			if i < 0 {
+				continue
+			}
+			check := config.RDSChecks[i]
+			id := check.DBInstanceIdentifier
+			freeableMemoryMB := stat.FreeableMemoryBytes / (1 << 20)
+			freeStorageGB := stat.FreeStorageBytes / (1 << 30)
+			safe := true
+			if check.MaxCPUPercent > 0 && stat.CPUPercent > check.MaxCPUPercent {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "rds",
+					Value:     stat.CPUPercent,
+					Threshold: check.MaxCPUPercent,
+					Message:   fmt.Sprintf("Alert: RDS %s CPU usage is above %.0f%%: %.2f%%", id, check.MaxCPUPercent, stat.CPUPercent),
+				})
+			}
+			if check.MinFreeableMemoryMB > 0 && freeableMemoryMB < check.MinFreeableMemoryMB {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "rds",
+					Value:     freeableMemoryMB,
+					Threshold: check.MinFreeableMemoryMB,
+					Message:   fmt.Sprintf("Alert: RDS %s freeable memory is below %.0f MiB: %.0f MiB", id, check.MinFreeableMemoryMB, freeableMemoryMB),
+				})
+			}
+			if check.MinFreeStorageGB > 0 && freeStorageGB < check.MinFreeStorageGB {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "rds",
+					Value:     freeStorageGB,
+					Threshold: check.MinFreeStorageGB,
+					Message:   fmt.Sprintf("Alert: RDS %s free storage is below %.0f GiB: %.2f GiB", id, check.MinFreeStorageGB, freeStorageGB),
+				})
+			}
+			if check.MaxConnections > 0 && stat.Connections > check.MaxConnections {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "rds",
+					Value:     stat.Connections,
+					Threshold: check.MaxConnections,
+					Message:   fmt.Sprintf("Alert: RDS %s has more than %.0f connections: %.0f", id, check.MaxConnections, stat.Connections),
+				})
+			}
+			if lag := stat.ReplicaLagSeconds; lag != nil && check.MaxReplicaLagSeconds > 0 && *lag > check.MaxReplicaLagSeconds {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "rds",
+					Value:     *lag,
+					Threshold: check.MaxReplicaLagSeconds,
+					Message:   fmt.Sprintf("Alert: RDS replica %s is more than %.0f s behind: %.0f s", id, check.MaxReplicaLagSeconds, *lag),
+				})
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "RDS %s: %.2f%% CPU, %.0f connections (Safe)\n", id, stat.CPUPercent, stat.Connections)
+			}
+		}
+	}
+
+	// Check the Consul Service Health
+	if metrics.Has("consul") {
+		for _, service := range snap.Consul {
+			if len(service.FailingChecks) == 0 {
+				fmt.Fprintf(StatusOutput, "Consul service %s: %d instances passing (Safe)\n", service.Service, service.Instances)
+				continue
+			}
+			var details strings.Builder
+			for _, failing := range service.FailingChecks {
+				fmt.Fprintf(&details, "\n  %s on %s (%s): %s: %s",
+					failing.CheckName, failing.Node, failing.ServiceID, failing.Status, strings.TrimSpace(failing.Output))
+			}
+			alerts = append(alerts, AlertEntry{
+				Metric: "consul",
+				Value:  float64(len(service.FailingChecks)),
+				Message: fmt.Sprintf("Alert: Consul service %s has %d failing checks:%s",
+					service.Service, len(service.FailingChecks), details.String()),
+			})
+		}
+	}
+
+	// Check the etcd Cluster Health
+	if metrics.Has("etcd") {
+		for _, stat := range snap.Etcd {
+			i := slices.IndexFunc(config.EtcdChecks, func(check EtcdCheck) bool { return slices.Equal(check.Endpoints, stat.EndpointNames()) })
+			if i < 0 {
+				continue
+			}
+			check := config.EtcdChecks[i]
+			safe := true
+			if !stat.HasLeader {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:  "etcd",
+					Message: fmt.Sprintf("Alert: etcd cluster %s has no leader", strings.Join(check.Endpoints, ",")),
+				})
+			}
+			for _, endpoint := range stat.UnhealthyEndpoints() {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:  "etcd",
+					Message: fmt.Sprintf("Alert: etcd member %s is unhealthy: %s", endpoint.Endpoint, endpoint.Error),
+				})
+			}
+			for _, endpoint := range stat.Endpoints {
+				if check.MaxDBSizeBytes > 0 && endpoint.DBSizeBytes > check.MaxDBSizeBytes {
+					safe = false
+					alerts = append(alerts, AlertEntry{
+						Metric:    "etcd",
+						Value:     float64(endpoint.DBSizeBytes),
+						Threshold: float64(check.MaxDBSizeBytes),
+						Message: fmt.Sprintf("Alert: etcd member %s database size is above %d bytes: %d bytes",
+							endpoint.Endpoint, check.MaxDBSizeBytes, endpoint.DBSizeBytes),
+					})
+				}
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "etcd cluster %s: %d members, leader elected (Safe)\n", strings.Join(check.Endpoints, ","), stat.Members)
+			}
+		}
+	}
+
+	// Check the Vault Seal Status and Token Expiry
+	if metrics.Has("vault") {
+		for _, stat := range snap.Vault {
+			i := slices.IndexFunc(config.VaultChecks, func(check VaultCheck) bool { return check.Address == stat.Address })
+			if i < 0 {
+				continue
+			}
+			check := config.VaultChecks[i]
+			cluster := fmt.Sprintf("cluster %s (%s)", stat.ClusterName, stat.ClusterID)
+			minTTL := time.Duration(check.MinTokenTTLSeconds) * time.Second
+			switch {
+			case !stat.Initialized:
+				alerts = append(alerts, AlertEntry{
+					Metric:  "vault",
+					Message: fmt.Sprintf("Alert: Vault %s is not initialized", check.Address),
+				})
+			case stat.Sealed:
+				alerts = append(alerts, AlertEntry{
+					Metric:  "vault",
+					Value:   1,
+					Message: fmt.Sprintf("Alert: Vault %s is sealed, %s", check.Address, cluster),
+				})
+			case minTTL > 0 && stat.TokenTTL > 0 && stat.TokenTTL < minTTL:
+				alerts = append(alerts, AlertEntry{
+					Metric:    "vault",
+					Value:     stat.TokenTTL.Seconds(),
+					Threshold: minTTL.Seconds(),
+					Message: fmt.Sprintf("Alert: Vault token on %s expires in %s (less than %s), %s",
+						check.Address, stat.TokenTTL, minTTL, cluster),
+				})
+			default:
+				fmt.Fprintf(StatusOutput, "Vault %s: unsealed, %s (Safe)\n", check.Address, cluster)
+			}
+		}
+	}
+
+	// Check the HAProxy Frontends and Backends
+	if metrics.Has("haproxy") {
+		for _, check := range config.HAProxyChecks {
+			var rows []BackendStat
+			for _, row := range snap.HAProxy {
+				if row.StatsURL == check.StatsURL {
+					rows = append(rows, row)
+				}
+			}
+			if len(rows) == 0 {
+				continue
+			}
+			safe := true
+			for _, row := range rows {
+				if !row.IsSummary() || !row.IsDown() {
+					continue
+				}
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric: "haproxy",
+					Message: fmt.Sprintf("Alert: HAProxy %s %s is DOWN (servers: %s)",
+						strings.ToLower(row.Server), row.Proxy, HAProxyServerStates(rows, row.Proxy)),
+				})
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "HAProxy %s: all frontends and backends up (Safe)\n", check.StatsURL)
+			}
+		}
+	}
+
+	// Check the Nginx Connections
+	if metrics.Has("nginx") {
+		for _, stat := range snap.Nginx {
+			i := slices.IndexFunc(config.NginxChecks, func(check NginxCheck) bool { return check.StubStatusURL == stat.URL })
+			if i < 0 {
+				continue
+			}
+			check := config.NginxChecks[i]
+			safe := true
+			if check.MaxActiveConnections > 0 && stat.ActiveConnections > check.MaxActiveConnections {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric:    "nginx",
+					Value:     float64(stat.ActiveConnections),
+					Threshold: float64(check.MaxActiveConnections),
+					Message: fmt.Sprintf("Alert: nginx %s has more than %d active connections: %d (reading: %d, writing: %d, waiting: %d)",
+						check.StubStatusURL, check.MaxActiveConnections, stat.ActiveConnections, stat.Reading, stat.Writing, stat.Waiting),
+				})
+			}
+			if stat.Dropped() > 0 {
+				safe = false
+				alerts = append(alerts, AlertEntry{
+					Metric: "nginx",
+					Value:  float64(stat.Dropped()),
+					Message: fmt.Sprintf("Alert: nginx %s dropped %d connections (%d accepted, %d handled)",
+						check.StubStatusURL, stat.Dropped(), stat.Accepts, stat.Handled),
+				})
+			}
+			if safe {
+				fmt.Fprintf(StatusOutput, "nginx %s: %d active connections (Safe)\n", check.StubStatusURL, stat.ActiveConnections)
+			}
+		}
+	}
+
+	// Check the Apache Workers
+	if metrics.Has("apache") {
+		for _, stat := range snap.Apache {
+			i := slices.IndexFunc(config.ApacheChecks, func(check ApacheCheck) bool { return check.StatusURL == stat.URL })
+			if i < 0 {
+				continue
+			}
+			check := config.ApacheChecks[i]
+			if check.MaxWorkerUtilization > 0 && stat.WorkerUtilization() > check.MaxWorkerUtilization {
+				alerts = append(alerts, AlertEntry{
+					Metric:    "apache",
+					Value:     stat.WorkerUtilization(),
+					Threshold: check.MaxWorkerUtilization,
+					Message: fmt.Sprintf("Alert: Apache %s worker utilization is above %.0f%%: %.0f%% (%d busy, %d idle)",
+						check.StatusURL, check.MaxWorkerUtilization*100, stat.WorkerUtilization()*100, stat.BusyWorkers, stat.IdleWorkers),
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "Apache %s: %d busy, %d idle workers (Safe)\n", check.StatusURL, stat.BusyWorkers, stat.IdleWorkers)
+			}
+		}
+	}
+
+	// Check the Required Processes; the results follow the order of the process checks
+	if metrics.Has("process") {
+		for i, result := range snap.Processes {
+			if i >= len(config.ProcessChecks) {
+				break
+			}
+			check := config.ProcessChecks[i]
+			if result.OK {
+				fmt.Fprintf(StatusOutput, "Process %s: %d running (Safe)\n", result.Name, result.Count)
+				continue
+			}
+
+			expected := fmt.Sprintf("at least %d", check.MinCount)
+			if check.MaxCount > 0 {
+				expected = fmt.Sprintf("%d to %d", check.MinCount, check.MaxCount)
+			}
+			alerts = append(alerts, AlertEntry{
+				Metric:  "process",
+				Value:   float64(result.Count),
+				Message: fmt.Sprintf("Alert: %d processes named %s are running, expected %s", result.Count, result.Name, expected),
+			})
+		}
+	}
+
+	return alerts
+}
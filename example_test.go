@@ -0,0 +1,45 @@
+package monitor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	monitor "github.com/VedantamSravan/go-system-monitor"
+)
+
+func ExampleCheckAll() {
+	cfg, err := monitor.ReadConfig("config.json")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	snap, errs := monitor.CheckAll(ctx, cfg)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	fmt.Printf("memory: %.1f%%, disk: %.1f%%\n", snap.MemoryUsedPercent, snap.DiskUsedPercent)
+}
+
+func ExampleFormatSnapshot() {
+	snap := monitor.MetricSnapshot{
+		Timestamp:         time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Thresholds:        monitor.Thresholds{CPUUsage: 80, MemoryUsage: 80, DiskUsage: 50},
+		CPUTemperature:    85,
+		CPUUsage:          []float64{12.5, 85},
+		MemoryUsedPercent: 60,
+		DiskUsedPercent:   55,
+	}
+	monitor.FormatSnapshot(snap, monitor.OutputCSV, os.Stdout)
+	// Output:
+	// timestamp,metric,value,unit,status
+	// 2025-01-01T12:00:00Z,cpu_temperature,85.00,°C,ok
+	// 2025-01-01T12:00:00Z,cpu_usage.0,12.50,%,ok
+	// 2025-01-01T12:00:00Z,cpu_usage.1,85.00,%,alert
+	// 2025-01-01T12:00:00Z,memory,60.00,%,ok
+	// 2025-01-01T12:00:00Z,disk,55.00,%,alert
+}
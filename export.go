@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportAlertHistory writes the alert events raised in [from, to) to w as CSV or a JSON array
+func ExportAlertHistory(store *MetricStore, format string, from, to time.Time, w io.Writer) error {
+	records, err := store.QueryAlerts(from, to)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case OutputJSON:
+		if records == nil {
+			records = []AlertRecord{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+
+	case OutputCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"timestamp", "metric", "value", "threshold", "severity", "notified", "resolved_at", "status"})
+		for _, record := range records {
+			resolvedAt := ""
+			if record.ResolvedAt != nil {
+				resolvedAt = record.ResolvedAt.Format(time.RFC3339)
+			}
+			cw.Write([]string{
+				record.Timestamp.Format(time.RFC3339),
+				record.Metric,
+				strconv.FormatFloat(record.Value, 'f', 2, 64),
+				strconv.FormatFloat(record.Threshold, 'f', 2, 64),
+				record.Severity,
+				strconv.FormatBool(record.Notified),
+				resolvedAt,
+				record.Status,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		return fmt.Errorf("unknown export format %q (expected csv or json)", format)
+	}
+}
+
+// parseExportTime parses a --from/--to value given as a date (2006-01-02) or RFC 3339 time.
+// A date used as the end of the range covers the whole day.
+func parseExportTime(value string, endOfRange bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (expected YYYY-MM-DD or RFC 3339)", value)
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
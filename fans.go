@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FanReading is the speed of a single fan
+type FanReading struct {
+	Name string
+	RPM  float64
+}
+
+// parsePowermetricsFanSpeeds finds the fan speeds in the output of the powermetrics smc sampler, e.g.
+//
+//	Fan: 1797.12 rpm
+//
+// The fans are named fan1, fan2, ... in the order they are listed. Fan lines without a speed are
+// skipped; it is an error only when no fan speed is found. The smc sampler has no JSON output,
+// so the text is parsed line by line.
+func parsePowermetricsFanSpeeds(output string) ([]FanReading, error) {
+	var readings []FanReading
+	for _, line := range strings.Split(output, "\n") {
+		label, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.HasPrefix(strings.ToLower(strings.TrimSpace(label)), "fan") {
+			continue
+		}
+		// Other fan lines, like the fan control mode, have no speed
+		var rpm float64
+		if _, err := fmt.Sscanf(strings.TrimSpace(value), "%f", &rpm); err != nil {
+			continue
+		}
+		readings = append(readings, FanReading{Name: fmt.Sprintf("fan%d", len(readings)+1), RPM: rpm})
+	}
+	if len(readings) == 0 {
+		return nil, fmt.Errorf("could not find the fan speeds in the powermetrics output")
+	}
+	return readings, nil
+}
+
+// formatFanReadings renders the readings in the 'sensors' output format so they are
+// handled like the Linux fan speeds
+func formatFanReadings(readings []FanReading) string {
+	var b strings.Builder
+	for _, reading := range readings {
+		fmt.Fprintf(&b, "%s: %.0f RPM\n", reading.Name, reading.RPM)
+	}
+	return b.String()
+}
+
+// parseFanReadings reads the fans of the 'sensors' output format, e.g.
+//
+//	fan1:        1797 RPM  (min =    0 RPM)
+//
+// Lines that are not fan speeds are skipped.
+func parseFanReadings(output string) []FanReading {
+	var readings []FanReading
+	for _, line := range strings.Split(output, "\n") {
+		label, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) < 2 || fields[1] != "RPM" {
+			continue
+		}
+		rpm, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+		readings = append(readings, FanReading{Name: strings.TrimSpace(label), RPM: rpm})
+	}
+	return readings
+}
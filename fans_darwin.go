@@ -0,0 +1,42 @@
+//go:build darwin
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GetFanSpeeds returns the fan speeds reported by the SMC, in the 'sensors' output format
+func GetFanSpeeds(ctx context.Context) (string, error) {
+	readings, err := getFanSpeedsMacOS(ctx)
+	if err != nil {
+		return "", err
+	}
+	return formatFanReadings(readings), nil
+}
+
+// getFanSpeedsMacOS reads the fan speeds with the smc sampler of 'powermetrics', which must run
+// as root. sudo is invoked non-interactively, so a missing sudoers rule yields ErrSudoRequired.
+// Apple silicon Macs have no smc sampler.
+func getFanSpeedsMacOS(ctx context.Context) ([]FanReading, error) {
+	cmd := exec.CommandContext(ctx, "sudo", "-n", "powermetrics", "-n", "1", "-i", "1", "--samplers", "smc")
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			stderr := string(exitErr.Stderr)
+			if strings.Contains(stderr, "password is required") || strings.Contains(stderr, "superuser") {
+				return nil, ErrSudoRequired
+			}
+		}
+		return nil, fmt.Errorf("Error fetching fan speeds: %w", err)
+	}
+	return parsePowermetricsFanSpeeds(string(output))
+}
@@ -0,0 +1,23 @@
+//go:build !freebsd && !darwin
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// GetFanSpeeds returns the fan speeds using the 'sensors' command on Linux
+func GetFanSpeeds(ctx context.Context) (string, error) {
+	// Run the 'sensors' command (make sure lm-sensors is installed)
+	cmd := exec.CommandContext(ctx, "sensors")
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("Error fetching fan speeds: %w", err)
+	}
+	return string(output), nil
+}
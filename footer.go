@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// defaultEmailFooter is used when email_footer is not configured
+const defaultEmailFooter = "Sent by go-system-monitor v{{.MonitorVersion}} on {{.Hostname}}{{if .EC2.InstanceID}}, EC2 instance {{.EC2}}{{end}}"
+
+// EmailFooterData is the data available to the email_footer template
+type EmailFooterData struct {
+	Hostname       string
+	MonitorVersion string    // without the leading "v" of release tags
+	ConfigFile     string    // path of the configuration file
+	NextCheckAt    time.Time // zero unless collection_interval is set
+	EC2            EC2Meta   // zero when not running on EC2
+}
+
+// EmailFooter renders the footer appended to every outgoing email
+type EmailFooter struct {
+	tmpl *template.Template
+	data EmailFooterData
+
+	mu          sync.Mutex
+	nextCheckAt time.Time
+}
+
+// NewEmailFooter parses the email_footer template text, falling back to defaultEmailFooter when it is empty
+func NewEmailFooter(text, configFile string) (*EmailFooter, error) {
+	if text == "" {
+		text = defaultEmailFooter
+	}
+	tmpl, err := template.New("email_footer").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse email_footer: %w", err)
+	}
+	// Catch references to unknown fields now rather than when an alert is sent
+	if err := tmpl.Execute(io.Discard, EmailFooterData{}); err != nil {
+		return nil, fmt.Errorf("could not render email_footer: %w", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	// Alerts from EC2 name the instance, as hostnames there are rarely meaningful
+	ec2, err := ec2Metadata()
+	if err != nil {
+		if !errors.Is(err, ErrNotEC2) {
+			log.Printf("%v\n", err)
+		}
+		ec2 = EC2Meta{}
+	}
+	return &EmailFooter{
+		tmpl: tmpl,
+		data: EmailFooterData{
+			Hostname:       hostname,
+			MonitorVersion: strings.TrimPrefix(Version, "v"),
+			ConfigFile:     configFile,
+			EC2:            ec2,
+		},
+	}, nil
+}
+
+// SetNextCheck records when the next collection cycle is due
+func (f *EmailFooter) SetNextCheck(at time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextCheckAt = at.Round(0)
+}
+
+// Render executes the footer template; a footer that fails to render is left out
+func (f *EmailFooter) Render() string {
+	f.mu.Lock()
+	data := f.data
+	data.NextCheckAt = f.nextCheckAt
+	f.mu.Unlock()
+
+	var sb strings.Builder
+	if err := f.tmpl.Execute(&sb, data); err != nil {
+		log.Printf("Error rendering email footer: %v\n", err)
+		return ""
+	}
+	return sb.String()
+}
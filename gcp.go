@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"cloud.google.com/go/compute/metadata"
+	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"google.golang.org/api/option"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	monitoredrespb "google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	gcpMetricPrefix    = "custom.googleapis.com/go_system_monitor/"
+	gcpMaxSeriesPerReq = 200 // CreateTimeSeries limit
+)
+
+// GCPMonitoringConfig holds the Google Cloud project metrics are written to
+type GCPMonitoringConfig struct {
+	ProjectID       string `json:"project_id"`
+	CredentialsFile string `json:"credentials_file"` // service account key; empty uses Application Default Credentials
+}
+
+// WriteToCloudMonitoring writes the points as custom gauge metrics to Cloud Monitoring
+func WriteToCloudMonitoring(ctx context.Context, cfg GCPMonitoringConfig, points []MetricPoint) error {
+	var opts []option.ClientOption
+	if cfg.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(cfg.CredentialsFile))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+
+	client, err := monitoring.NewMetricClient(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("Error creating Cloud Monitoring client: %w", err)
+	}
+	defer client.Close()
+
+	resource := gcpMonitoredResource(ctx, cfg.ProjectID)
+
+	series := make([]*monitoringpb.TimeSeries, 0, len(points))
+	for _, point := range points {
+		series = append(series, &monitoringpb.TimeSeries{
+			Metric:   &metricpb.Metric{Type: gcpMetricPrefix + point.Name, Labels: point.Labels},
+			Resource: resource,
+			Points: []*monitoringpb.Point{{
+				Interval: &monitoringpb.TimeInterval{EndTime: timestamppb.New(point.Timestamp)},
+				Value:    &monitoringpb.TypedValue{Value: &monitoringpb.TypedValue_DoubleValue{DoubleValue: point.Value}},
+			}},
+		})
+	}
+
+	for start := 0; start < len(series); start += gcpMaxSeriesPerReq {
+		end := min(start+gcpMaxSeriesPerReq, len(series))
+		err := client.CreateTimeSeries(ctx, &monitoringpb.CreateTimeSeriesRequest{
+			Name:       "projects/" + cfg.ProjectID,
+			TimeSeries: series[start:end],
+		})
+		if err != nil {
+			return fmt.Errorf("Error writing to Cloud Monitoring: %w", err)
+		}
+	}
+	return nil
+}
+
+// gcpMonitoredResource describes this machine as a gce_instance when running on
+// Compute Engine and as a generic_node elsewhere
+func gcpMonitoredResource(ctx context.Context, projectID string) *monitoredrespb.MonitoredResource {
+	if metadata.OnGCE() {
+		instanceID, idErr := metadata.InstanceIDWithContext(ctx)
+		zone, zoneErr := metadata.ZoneWithContext(ctx)
+		if idErr == nil && zoneErr == nil {
+			return &monitoredrespb.MonitoredResource{
+				Type: "gce_instance",
+				Labels: map[string]string{
+					"project_id":  projectID,
+					"instance_id": instanceID,
+					"zone":        zone,
+				},
+			}
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	return &monitoredrespb.MonitoredResource{
+		Type: "generic_node",
+		Labels: map[string]string{
+			"project_id": projectID,
+			"location":   "global",
+			"namespace":  "go-system-monitor",
+			"node_id":    hostname,
+		},
+	}
+}
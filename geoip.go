@@ -0,0 +1,111 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoInfo is the location and network of an IP address. Fields the database does not
+// cover are empty.
+type GeoInfo struct {
+	Country string `json:"country,omitempty"` // ISO code, e.g. DE
+	City    string `json:"city,omitempty"`
+	ASN     uint   `json:"asn,omitempty"`
+	ISP     string `json:"isp,omitempty"` // or the organization of the autonomous system
+}
+
+// String returns e.g. "Frankfurt am Main, DE, AS3320 Deutsche Telekom AG"
+func (g GeoInfo) String() string {
+	var parts []string
+	if g.City != "" {
+		parts = append(parts, g.City)
+	}
+	if g.Country != "" {
+		parts = append(parts, g.Country)
+	}
+	switch {
+	case g.ASN != 0 && g.ISP != "":
+		parts = append(parts, fmt.Sprintf("AS%d %s", g.ASN, g.ISP))
+	case g.ASN != 0:
+		parts = append(parts, fmt.Sprintf("AS%d", g.ASN))
+	case g.ISP != "":
+		parts = append(parts, g.ISP)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// geoIPRecord holds the fields of the GeoLite2/GeoIP2 City, Country, ASN and ISP databases
+type geoIPRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	ASN          uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+	ISP          string `maxminddb:"isp"`
+}
+
+// geoIPReaders keeps the opened databases per path between lookups
+var geoIPReaders = struct {
+	sync.Mutex
+	readers map[string]*maxminddb.Reader
+}{readers: make(map[string]*maxminddb.Reader)}
+
+// LookupGeoIP looks up ip in the MaxMind database at dbPath, e.g. GeoLite2-City.mmdb or
+// GeoLite2-ASN.mmdb. Private, loopback and link-local addresses are not looked up and
+// return an empty GeoInfo.
+func LookupGeoIP(ip string, dbPath string) (GeoInfo, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return GeoInfo{}, fmt.Errorf("could not parse IP address %q", ip)
+	}
+	if addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return GeoInfo{}, nil
+	}
+
+	geoIPReaders.Lock()
+	defer geoIPReaders.Unlock()
+	reader, ok := geoIPReaders.readers[dbPath]
+	if !ok {
+		var err error
+		reader, err = maxminddb.Open(dbPath)
+		if err != nil {
+			return GeoInfo{}, fmt.Errorf("Error opening GeoIP database: %w", err)
+		}
+		geoIPReaders.readers[dbPath] = reader
+	}
+
+	var record geoIPRecord
+	if err := reader.Lookup(addr, &record); err != nil {
+		return GeoInfo{}, fmt.Errorf("Error looking up %s in the GeoIP database: %w", ip, err)
+	}
+	info := GeoInfo{Country: record.Country.ISOCode, City: record.City.Names["en"], ASN: record.ASN, ISP: record.ISP}
+	if info.ISP == "" {
+		info.ISP = record.Organization
+	}
+	return info, nil
+}
+
+// geoIPContext returns the location of ip to append to the address in an alert message, e.g.
+// ", Frankfurt am Main, DE, AS3320 Deutsche Telekom AG", or "" when it is unknown
+func geoIPContext(ip string, dbPath string) string {
+	if dbPath == "" || ip == "" {
+		return ""
+	}
+	info, err := LookupGeoIP(ip, dbPath)
+	if err != nil {
+		log.Printf("%v\n", err)
+		return ""
+	}
+	if location := info.String(); location != "" {
+		return ", " + location
+	}
+	return ""
+}
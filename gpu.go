@@ -0,0 +1,31 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrSudoRequired is returned when powermetrics cannot run as root without a password prompt
+var ErrSudoRequired = errors.New("powermetrics requires passwordless sudo (see README)")
+
+// ErrGPUTempNotAvailable is returned on systems where the GPU temperature cannot be read
+var ErrGPUTempNotAvailable = errors.New("GPU temperature monitoring is only supported on macOS")
+
+// parsePowermetricsGPUTemperature finds the GPU temperature in powermetrics output, e.g.
+//
+//	GPU die temperature: 48.31 C
+func parsePowermetricsGPUTemperature(output string) (float64, error) {
+	for _, line := range strings.Split(output, "\n") {
+		label, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.Contains(label, "GPU") || !strings.Contains(strings.ToLower(label), "temperature") {
+			continue
+		}
+		var temp float64
+		if _, err := fmt.Sscanf(strings.TrimSpace(value), "%f", &temp); err != nil {
+			return 0, fmt.Errorf("Error parsing GPU temperature: %w", err)
+		}
+		return temp, nil
+	}
+	return 0, fmt.Errorf("could not find the GPU temperature in the powermetrics output")
+}
@@ -0,0 +1,32 @@
+//go:build darwin
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GetMacGPUTemperature returns the GPU temperature in °C using 'powermetrics', which must
+// run as root. sudo is invoked non-interactively, so a missing sudoers rule yields ErrSudoRequired.
+func GetMacGPUTemperature(ctx context.Context) (float64, error) {
+	cmd := exec.CommandContext(ctx, "sudo", "-n", "powermetrics", "-n", "1", "-i", "1", "--samplers", "gpu_power")
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			stderr := string(exitErr.Stderr)
+			if strings.Contains(stderr, "password is required") || strings.Contains(stderr, "superuser") {
+				return 0, ErrSudoRequired
+			}
+		}
+		return 0, fmt.Errorf("Error fetching GPU temperature: %w", err)
+	}
+	return parsePowermetricsGPUTemperature(string(output))
+}
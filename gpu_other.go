@@ -0,0 +1,10 @@
+//go:build !darwin
+
+package monitor
+
+import "context"
+
+// GetMacGPUTemperature is only supported on macOS
+func GetMacGPUTemperature(ctx context.Context) (float64, error) {
+	return 0, ErrGPUTempNotAvailable
+}
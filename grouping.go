@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultAlertGroupWindow is how long alerts are collected into one email unless alert_group_window_seconds is set
+const defaultAlertGroupWindow = 30 * time.Second
+
+// AlertGroup is a burst of alerts sent as one email
+type AlertGroup struct {
+	Alerts []AlertEntry
+	Window time.Duration
+}
+
+// Subject returns the email subject, which counts the alerts when several metrics are involved
+func (g AlertGroup) Subject() string {
+	metrics := make(map[string]bool)
+	for _, alert := range g.Alerts {
+		metrics[alert.Metric] = true
+	}
+	if len(metrics) > 1 {
+		return fmt.Sprintf("System Alert: %d alerts in the last %s", len(g.Alerts), g.Window)
+	}
+	return "System Alert: Resource Usage Exceeded"
+}
+
+// GroupAlertsWithin groups the alerts by time: each group holds the alerts found within window
+// of its first alert. The groups and the alerts in them are in chronological order.
+func GroupAlertsWithin(alerts []AlertEntry, window time.Duration) []AlertGroup {
+	sorted := make([]AlertEntry, len(alerts))
+	copy(sorted, alerts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	var groups []AlertGroup
+	var start time.Time
+	for _, alert := range sorted {
+		if len(groups) == 0 || alert.Timestamp.After(start.Add(window)) {
+			groups = append(groups, AlertGroup{Window: window})
+			start = alert.Timestamp
+		}
+		last := &groups[len(groups)-1]
+		last.Alerts = append(last.Alerts, alert)
+	}
+	return groups
+}
+
+// AlertGrouper holds back alerts for a window after the first one, so a burst of threshold
+// violations from concurrent checks is sent as one email instead of one per check
+type AlertGrouper struct {
+	window time.Duration
+	notify func(AlertGroup)
+
+	mu      sync.Mutex
+	pending []AlertEntry
+	timer   *time.Timer
+}
+
+// alertGroupWindow returns the alert_group_window_seconds of config, or the default window
+func alertGroupWindow(config Config) time.Duration {
+	if config.AlertGroupWindow > 0 {
+		return time.Duration(config.AlertGroupWindow) * time.Second
+	}
+	return defaultAlertGroupWindow
+}
+
+// NewAlertGrouper creates a grouper that calls notify with every group once its window has passed
+func NewAlertGrouper(window time.Duration, notify func(AlertGroup)) *AlertGrouper {
+	return &AlertGrouper{window: window, notify: notify}
+}
+
+// Add queues alerts for the current group, starting its window if it is the first
+func (g *AlertGrouper) Add(alerts []AlertEntry) {
+	if len(alerts) == 0 {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pending = append(g.pending, alerts...)
+	if g.timer == nil {
+		g.timer = time.AfterFunc(g.window, g.Flush)
+	}
+}
+
+// Send sends alerts as a group of their own right away, bypassing the window
+func (g *AlertGrouper) Send(alerts []AlertEntry) {
+	if len(alerts) == 0 {
+		return
+	}
+	g.notify(AlertGroup{Alerts: alerts, Window: g.window})
+}
+
+// Flush sends the queued alerts without waiting for the window to pass
+func (g *AlertGrouper) Flush() {
+	g.mu.Lock()
+	pending := g.pending
+	g.pending = nil
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+	g.mu.Unlock()
+
+	for _, group := range GroupAlertsWithin(pending, g.window) {
+		g.notify(group)
+	}
+}
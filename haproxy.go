@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HAProxyCheck is a single entry of the haproxy_checks config
+type HAProxyCheck struct {
+	StatsURL string `json:"stats_url"` // e.g. http://lb1:8404/stats
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// BackendStat is a single row of the HAProxy stats CSV: a server, or the
+// FRONTEND/BACKEND summary row of a proxy
+type BackendStat struct {
+	StatsURL       string `json:"stats_url"` // the stats page the row was read from
+	Proxy          string `json:"proxy"`
+	Server         string `json:"server"`
+	Status         string `json:"status"` // e.g. UP, DOWN, MAINT, OPEN; may carry a transition like "UP 1/3"
+	Active         int    `json:"active"` // active servers (summary rows) or 1 for an active server
+	Weight         int    `json:"weight"`
+	LBTotal        int64  `json:"lbtot"` // times the server was selected by the load balancer
+	ErrorResponses int64  `json:"eresp"`
+}
+
+// IsSummary reports whether the row is the FRONTEND or BACKEND summary of a proxy
+func (s BackendStat) IsSummary() bool {
+	return s.Server == "FRONTEND" || s.Server == "BACKEND"
+}
+
+// IsDown reports whether the row is down, including servers going up after a failure ("DOWN 1/2")
+func (s BackendStat) IsDown() bool {
+	return strings.HasPrefix(s.Status, "DOWN")
+}
+
+// HAProxyServerStates summarizes the servers of a proxy by state, e.g. "1 DOWN, 3 UP"
+func HAProxyServerStates(rows []BackendStat, proxy string) string {
+	counts := make(map[string]int)
+	for _, row := range rows {
+		if row.Proxy != proxy || row.IsSummary() {
+			continue
+		}
+		// Drop health check transitions like the "1/3" of "UP 1/3"
+		state, _, _ := strings.Cut(row.Status, " ")
+		counts[state]++
+	}
+
+	states := make([]string, 0, len(counts))
+	for state, count := range counts {
+		states = append(states, fmt.Sprintf("%d %s", count, state))
+	}
+	sort.Strings(states)
+	return strings.Join(states, ", ")
+}
+
+// CheckHAProxyBackends fetches and parses the CSV export of the HAProxy stats page
+func CheckHAProxyBackends(ctx context.Context, cfg HAProxyCheck) ([]BackendStat, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(cfg.StatsURL, "/;")+";csv", nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating HAProxy request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent())
+	if cfg.Username != "" {
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+
+	client := &http.Client{Timeout: serviceCheckTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching HAProxy stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HAProxy returned %s", resp.Status)
+	}
+	stats, err := parseHAProxyCSV(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stats {
+		stats[i].StatsURL = cfg.StatsURL
+	}
+	return stats, nil
+}
+
+// parseHAProxyCSV parses the stats CSV, whose header line starts with "# pxname,svname,..."
+func parseHAProxyCSV(r io.Reader) ([]BackendStat, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse HAProxy stats: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimPrefix(strings.TrimSpace(name), "# ")] = i
+	}
+	for _, name := range []string{"pxname", "svname", "status", "act", "weight", "lbtot", "eresp"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("HAProxy stats are missing the %s column", name)
+		}
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse HAProxy stats: %w", err)
+	}
+
+	stats := make([]BackendStat, 0, len(records))
+	for _, record := range records {
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		// Counters are empty where they do not apply, e.g. lbtot on frontends
+		active, _ := strconv.Atoi(field("act"))
+		weight, _ := strconv.Atoi(field("weight"))
+		lbtot, _ := strconv.ParseInt(field("lbtot"), 10, 64)
+		eresp, _ := strconv.ParseInt(field("eresp"), 10, 64)
+		stats = append(stats, BackendStat{
+			Proxy:          field("pxname"),
+			Server:         field("svname"),
+			Status:         field("status"),
+			Active:         active,
+			Weight:         weight,
+			LBTotal:        lbtot,
+			ErrorResponses: eresp,
+		})
+	}
+	return stats, nil
+}
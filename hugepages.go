@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// procMeminfoPath is read for the huge page counters
+var procMeminfoPath = "/proc/meminfo"
+
+// ErrHugePagesNotAvailable is returned on systems without /proc/meminfo
+var ErrHugePagesNotAvailable = errors.New("huge page statistics are not available on this system")
+
+// HugePageStat holds the HugeTLB pool of the default huge page size
+type HugePageStat struct {
+	Total           uint64   `json:"total"`
+	Free            uint64   `json:"free"`
+	Reserved        uint64   `json:"reserved"` // promised to a mapping but not yet faulted in
+	Surplus         uint64   `json:"surplus"`  // allocated beyond the pool from overcommit
+	PageSizeKB      uint64   `json:"page_size_kb"`
+	ConfiguredSizes []uint64 `json:"configured_sizes_kb,omitempty"` // page sizes with a pool in /sys/kernel/mm/hugepages
+}
+
+// AvailablePercent returns the percentage of the pool that is neither in use nor reserved
+func (s HugePageStat) AvailablePercent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	available := s.Free - min(s.Reserved, s.Free)
+	return float64(available) / float64(s.Total) * 100
+}
+
+// GetHugePagesStats reads the huge page counters from /proc/meminfo and lists the huge page
+// sizes that have a pool configured
+func GetHugePagesStats() (HugePageStat, error) {
+	file, err := os.Open(procMeminfoPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return HugePageStat{}, ErrHugePagesNotAvailable
+		}
+		return HugePageStat{}, fmt.Errorf("Error reading meminfo: %w", err)
+	}
+	defer file.Close()
+
+	var stat HugePageStat
+	fields := map[string]*uint64{
+		"HugePages_Total": &stat.Total,
+		"HugePages_Free":  &stat.Free,
+		"HugePages_Rsvd":  &stat.Reserved,
+		"HugePages_Surp":  &stat.Surplus,
+		"Hugepagesize":    &stat.PageSizeKB,
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// e.g. "HugePages_Total:     512" or "Hugepagesize:       2048 kB"
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		field, known := fields[name]
+		if !ok || !known {
+			continue
+		}
+		number, _, _ := strings.Cut(strings.TrimSpace(value), " ")
+		if *field, err = strconv.ParseUint(number, 10, 64); err != nil {
+			return HugePageStat{}, fmt.Errorf("Error parsing %s in meminfo: %w", name, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return HugePageStat{}, fmt.Errorf("Error reading meminfo: %w", err)
+	}
+	if stat.PageSizeKB == 0 {
+		return HugePageStat{}, ErrHugePagesNotAvailable
+	}
+
+	stat.ConfiguredSizes = configuredHugePageSizes()
+	return stat, nil
+}
+
+// configuredHugePageSizes returns the huge page sizes in kB that have pages allocated
+func configuredHugePageSizes() []uint64 {
+	dirs, _ := filepath.Glob(filepath.Join(sysfsRoot, "kernel", "mm", "hugepages", "hugepages-*kB"))
+	var sizes []uint64
+	for _, dir := range dirs {
+		size, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(dir), "hugepages-"), "kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+		if pages, err := readSysfsUint(filepath.Join(dir, "nr_hugepages")); err == nil && pages > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+	return sizes
+}
+
+// formatHugePageSizes renders the sizes for an alert, e.g. "2048 kB, 1048576 kB", or "none"
+func formatHugePageSizes(sizes []uint64) string {
+	if len(sizes) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(sizes))
+	for i, size := range sizes {
+		parts[i] = fmt.Sprintf("%d kB", size)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadConfigWithIncludes reads the config file at basePath together with the files listed in its
+// include key, e.g. "include": ["/etc/monitor/smtp.json", "thresholds.json"]. Relative paths are
+// resolved against the directory of the including file, and included files may include others.
+// The files are deep-merged: objects are merged key by key, while other values replace each other.
+// Later includes override earlier ones, and the including file overrides all of its includes.
+func LoadConfigWithIncludes(basePath string) (Config, error) {
+	merged, err := loadConfigTree(basePath, nil)
+	if err != nil {
+		return Config{}, err
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not merge config files: %w", err)
+	}
+	return parseConfig(data)
+}
+
+// loadConfigTree reads the config file at path and merges its includes. stack holds the
+// files that are currently being included, to detect circular includes.
+func loadConfigTree(path string, stack []string) (map[string]any, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve config file %s: %w", path, err)
+	}
+	for i, including := range stack {
+		if including == absPath {
+			cycle := append(append([]string{}, stack[i:]...), absPath)
+			return nil, fmt.Errorf("circular config include: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	stack = append(stack, absPath)
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %w", err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+
+	var includes []string
+	if raw, ok := values["include"]; ok {
+		list, ok := raw.([]any)
+		if !ok {
+			return nil, fmt.Errorf("could not parse config file %s: include must be a list of file paths", path)
+		}
+		for _, entry := range list {
+			include, ok := entry.(string)
+			if !ok {
+				return nil, fmt.Errorf("could not parse config file %s: include must be a list of file paths", path)
+			}
+			includes = append(includes, include)
+		}
+		delete(values, "include")
+	}
+
+	merged := make(map[string]any)
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(absPath), include)
+		}
+		included, err := loadConfigTree(include, stack)
+		if err != nil {
+			return nil, err
+		}
+		mergeConfigValues(merged, included)
+	}
+	mergeConfigValues(merged, values)
+	return merged, nil
+}
+
+// mergeConfigValues deep-merges src into dst, with the values of src taking precedence
+func mergeConfigValues(dst, src map[string]any) {
+	for key, value := range src {
+		srcObject, srcIsObject := value.(map[string]any)
+		dstObject, dstIsObject := dst[key].(map[string]any)
+		if srcIsObject && dstIsObject {
+			mergeConfigValues(dstObject, srcObject)
+			continue
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// containerRuntimeSockets maps the API sockets of common container runtimes to their names
+var containerRuntimeSockets = []struct {
+	Path    string
+	Runtime string
+}{
+	{"/var/run/docker.sock", "docker"},
+	{"/run/containerd/containerd.sock", "containerd"},
+	{"/run/crio/crio.sock", "cri-o"},
+	{"/run/podman/podman.sock", "podman"},
+}
+
+// HostFingerprint is a structured profile of the machine for asset inventories
+type HostFingerprint struct {
+	Hostname          string          `json:"hostname"`
+	IPAddresses       []string        `json:"ip_addresses"`
+	CPUModel          string          `json:"cpu_model"`
+	CPUCores          int             `json:"cpu_cores"` // logical cores
+	TotalMemoryBytes  uint64          `json:"total_memory_bytes"`
+	Disks             []InventoryDisk `json:"disks"`
+	OS                string          `json:"os"` // e.g. ubuntu 22.04
+	KernelVersion     string          `json:"kernel_version"`
+	ContainerRuntimes []string        `json:"container_runtimes,omitempty"`
+	Virtualization    *VirtInfo       `json:"virtualization,omitempty"` // nil if it could not be detected
+}
+
+// InventoryDisk is a mounted disk partition
+type InventoryDisk struct {
+	Device     string `json:"device"`
+	Mountpoint string `json:"mountpoint"`
+	Fstype     string `json:"fstype"`
+	SizeBytes  uint64 `json:"size_bytes"`
+}
+
+// GetHostFingerprint collects the hardware and software profile of the machine
+func GetHostFingerprint(ctx context.Context) (HostFingerprint, error) {
+	var fp HostFingerprint
+
+	info, err := host.InfoWithContext(ctx)
+	if err != nil {
+		return fp, fmt.Errorf("Error fetching host info: %w", err)
+	}
+	fp.Hostname = info.Hostname
+	fp.OS = info.Platform + " " + info.PlatformVersion
+	fp.KernelVersion = info.KernelVersion
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return fp, fmt.Errorf("Error fetching IP addresses: %w", err)
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+			fp.IPAddresses = append(fp.IPAddresses, ipNet.IP.String())
+		}
+	}
+
+	cpuInfo, err := cpu.InfoWithContext(ctx)
+	if err != nil {
+		return fp, fmt.Errorf("Error fetching CPU info: %w", err)
+	}
+	if len(cpuInfo) > 0 {
+		fp.CPUModel = cpuInfo[0].ModelName
+	}
+	fp.CPUCores, err = cpu.CountsWithContext(ctx, true)
+	if err != nil {
+		return fp, fmt.Errorf("Error fetching CPU core count: %w", err)
+	}
+
+	memStats, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return fp, fmt.Errorf("Error fetching memory stats: %w", err)
+	}
+	fp.TotalMemoryBytes = memStats.Total
+
+	// Only physical devices; pseudo filesystems like proc and tmpfs are skipped
+	partitions, err := disk.PartitionsWithContext(ctx, false)
+	if err != nil {
+		return fp, fmt.Errorf("Error fetching disk partitions: %w", err)
+	}
+	for _, partition := range partitions {
+		usage, err := disk.UsageWithContext(ctx, partition.Mountpoint)
+		if err != nil {
+			continue
+		}
+		fp.Disks = append(fp.Disks, InventoryDisk{
+			Device:     partition.Device,
+			Mountpoint: partition.Mountpoint,
+			Fstype:     partition.Fstype,
+			SizeBytes:  usage.Total,
+		})
+	}
+
+	for _, socket := range containerRuntimeSockets {
+		if _, err := os.Stat(socket.Path); err == nil {
+			fp.ContainerRuntimes = append(fp.ContainerRuntimes, socket.Runtime)
+		}
+	}
+
+	if virt, err := DetectVirtualization(); err == nil {
+		fp.Virtualization = &virt
+	}
+
+	return fp, nil
+}
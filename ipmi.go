@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ErrIPMINotAvailable is returned when ipmitool is not installed
+var ErrIPMINotAvailable = errors.New("IPMI sensors are not available (install ipmitool)")
+
+// IPMIConfig selects the BMC whose sensors are read. Without a host the local BMC is
+// read through the kernel IPMI driver, which requires root.
+type IPMIConfig struct {
+	IPMISensors  bool   `json:"ipmi_sensors"`
+	IPMIHost     string `json:"ipmi_host"` // BMC address for remote IPMI over LAN (lanplus)
+	IPMIUser     string `json:"ipmi_user"`
+	IPMIPassword string `json:"ipmi_password"`
+}
+
+// IPMISensor is a single row of 'ipmitool sensor list'. Readings and thresholds the
+// sensor does not have are nil.
+type IPMISensor struct {
+	Name                string   `json:"name"`
+	Reading             *float64 `json:"reading,omitempty"`
+	Unit                string   `json:"unit"`   // e.g. degrees C, RPM, Volts or discrete
+	Status              string   `json:"status"` // ok, nc, cr or nr (or a bit mask for discrete sensors)
+	LowerNonRecoverable *float64 `json:"lower_non_recoverable,omitempty"`
+	LowerCritical       *float64 `json:"lower_critical,omitempty"`
+	LowerNonCritical    *float64 `json:"lower_non_critical,omitempty"`
+	UpperNonCritical    *float64 `json:"upper_non_critical,omitempty"`
+	UpperCritical       *float64 `json:"upper_critical,omitempty"`
+	UpperNonRecoverable *float64 `json:"upper_non_recoverable,omitempty"`
+}
+
+// Critical reports whether the sensor is in the critical or non-recoverable state
+func (s IPMISensor) Critical() bool {
+	return s.Status == "cr" || s.Status == "nr"
+}
+
+// ipmiStatusName spells out the critical sensor states
+func ipmiStatusName(status string) string {
+	switch status {
+	case "cr":
+		return "critical"
+	case "nr":
+		return "non-recoverable"
+	default:
+		return status
+	}
+}
+
+// GetIPMISensors reads the sensors of the BMC with 'ipmitool sensor list'. The password of a
+// remote BMC is passed in the IPMI_PASSWORD environment variable rather than on the command line.
+func GetIPMISensors(ctx context.Context, cfg IPMIConfig) ([]IPMISensor, error) {
+	var args []string
+	if cfg.IPMIHost != "" {
+		args = append(args, "-I", "lanplus", "-H", cfg.IPMIHost, "-U", cfg.IPMIUser, "-E")
+	}
+	cmd := exec.CommandContext(ctx, "ipmitool", append(args, "sensor", "list")...)
+	if cfg.IPMIHost != "" {
+		cmd.Env = append(os.Environ(), "IPMI_PASSWORD="+cfg.IPMIPassword)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, ErrIPMINotAvailable
+		}
+		return nil, fmt.Errorf("Error fetching IPMI sensors: %w", err)
+	}
+	return parseIPMISensorList(string(output))
+}
+
+// parseIPMISensorList parses the table of 'ipmitool sensor list':
+//
+//	CPU Temp         | 45.000     | degrees C  | ok    | 0.000     | 0.000     | 0.000     | 95.000    | 100.000   | 100.000
+//	PS1 Status       | 0x1        | discrete   | 0x0100| na        | na        | na        | na        | na        | na
+func parseIPMISensorList(output string) ([]IPMISensor, error) {
+	var sensors []IPMISensor
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		columns := strings.Split(line, "|")
+		if len(columns) < 10 {
+			return nil, fmt.Errorf("could not parse ipmitool sensor line %q", line)
+		}
+		for i := range columns {
+			columns[i] = strings.TrimSpace(columns[i])
+		}
+
+		sensor := IPMISensor{Name: columns[0], Unit: columns[2], Status: columns[3]}
+		if sensor.Unit != "discrete" {
+			sensor.Reading = parseIPMIValue(columns[1])
+		}
+		sensor.LowerNonRecoverable = parseIPMIValue(columns[4])
+		sensor.LowerCritical = parseIPMIValue(columns[5])
+		sensor.LowerNonCritical = parseIPMIValue(columns[6])
+		sensor.UpperNonCritical = parseIPMIValue(columns[7])
+		sensor.UpperCritical = parseIPMIValue(columns[8])
+		sensor.UpperNonRecoverable = parseIPMIValue(columns[9])
+		sensors = append(sensors, sensor)
+	}
+	return sensors, nil
+}
+
+// parseIPMIValue parses a reading or threshold; "na" and other non-numbers are nil
+func parseIPMIValue(s string) *float64 {
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// ipmiThresholds describes the thresholds of a sensor for an alert, e.g. "upper critical 100.000"
+func ipmiThresholds(s IPMISensor) string {
+	var parts []string
+	for _, threshold := range []struct {
+		name  string
+		value *float64
+	}{
+		{"lower non-recoverable", s.LowerNonRecoverable},
+		{"lower critical", s.LowerCritical},
+		{"upper critical", s.UpperCritical},
+		{"upper non-recoverable", s.UpperNonRecoverable},
+	} {
+		if threshold.value != nil {
+			parts = append(parts, fmt.Sprintf("%s %.3f", threshold.name, *threshold.value))
+		}
+	}
+	if len(parts) == 0 {
+		return "no thresholds"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,25 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+)
+
+// JournalEntry holds the fields of a systemd journal entry that are reported in alerts
+type JournalEntry struct {
+	Message   string    `json:"message"`
+	Unit      string    `json:"unit"`
+	Priority  int       `json:"priority"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FormatJournalAlert builds the alert body for a critical journal entry
+func FormatJournalAlert(entry JournalEntry) string {
+	return fmt.Sprintf("Alert: Critical journal entry from %s\n"+
+		"MESSAGE: %s\n"+
+		"_SYSTEMD_UNIT: %s\n"+
+		"PRIORITY: %d\n"+
+		"__REALTIME_TIMESTAMP: %d (%s)\n",
+		entry.Unit, entry.Message, entry.Unit, entry.Priority,
+		entry.Timestamp.UnixMicro(), entry.Timestamp.Format(time.RFC3339))
+}
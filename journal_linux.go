@@ -0,0 +1,89 @@
+//go:build linux && cgo
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/sdjournal"
+)
+
+// journalWaitTimeout bounds how long the reader blocks before re-checking for cancellation
+const journalWaitTimeout = 1 * time.Second
+
+// MonitorJournal streams new journal entries for the given units whose priority is at
+// or above priority (lower values are more severe). The channel is closed when ctx is done.
+func MonitorJournal(ctx context.Context, units []string, priority int) (<-chan JournalEntry, error) {
+	j, err := sdjournal.NewJournal()
+	if err != nil {
+		return nil, fmt.Errorf("Error opening systemd journal: %w", err)
+	}
+
+	// Matches on the same field are OR'ed, matches on different fields are AND'ed
+	for p := 0; p <= priority; p++ {
+		if err := j.AddMatch(sdjournal.SD_JOURNAL_FIELD_PRIORITY + "=" + strconv.Itoa(p)); err != nil {
+			j.Close()
+			return nil, fmt.Errorf("Error adding journal priority match: %w", err)
+		}
+	}
+	for _, unit := range units {
+		if err := j.AddMatch(sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT + "=" + unit); err != nil {
+			j.Close()
+			return nil, fmt.Errorf("Error adding journal unit match: %w", err)
+		}
+	}
+
+	// Start at the end of the journal so only new entries are reported
+	if err := j.SeekTail(); err != nil {
+		j.Close()
+		return nil, fmt.Errorf("Error seeking to end of journal: %w", err)
+	}
+	if _, err := j.Previous(); err != nil {
+		j.Close()
+		return nil, fmt.Errorf("Error seeking to end of journal: %w", err)
+	}
+
+	entries := make(chan JournalEntry)
+	go func() {
+		defer close(entries)
+		defer j.Close()
+
+		for ctx.Err() == nil {
+			n, err := j.Next()
+			if err != nil {
+				log.Printf("Error reading systemd journal: %v\n", err)
+				return
+			}
+			if n == 0 {
+				j.Wait(journalWaitTimeout)
+				continue
+			}
+
+			raw, err := j.GetEntry()
+			if err != nil {
+				log.Printf("Error reading systemd journal entry: %v\n", err)
+				continue
+			}
+
+			entryPriority, _ := strconv.Atoi(raw.Fields[sdjournal.SD_JOURNAL_FIELD_PRIORITY])
+			entry := JournalEntry{
+				Message:   raw.Fields[sdjournal.SD_JOURNAL_FIELD_MESSAGE],
+				Unit:      raw.Fields[sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT],
+				Priority:  entryPriority,
+				Timestamp: time.UnixMicro(int64(raw.RealtimeTimestamp)),
+			}
+
+			select {
+			case entries <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return entries, nil
+}
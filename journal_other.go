@@ -0,0 +1,13 @@
+//go:build !linux || !cgo
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+)
+
+// MonitorJournal is only supported on Linux builds with cgo enabled
+func MonitorJournal(ctx context.Context, units []string, priority int) (<-chan JournalEntry, error) {
+	return nil, fmt.Errorf("systemd journal monitoring is not supported on this platform")
+}
@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaCheck is a single entry of the kafka_checks config
+type KafkaCheck struct {
+	Brokers             []string `json:"brokers"` // e.g. ["kafka1:9092", "kafka2:9092"]
+	ConsumerGroup       string   `json:"consumer_group"`
+	MaxLagMessages      int64    `json:"max_lag_messages"`       // per partition
+	MaxTotalLagMessages int64    `json:"max_total_lag_messages"` // summed over all partitions of the group
+}
+
+// PartitionLag is how far a consumer group is behind on a single partition
+type PartitionLag struct {
+	ConsumerGroup   string `json:"consumer_group"`
+	Topic           string `json:"topic"`
+	Partition       int    `json:"partition"`
+	CommittedOffset int64  `json:"committed_offset"`
+	LatestOffset    int64  `json:"latest_offset"`
+	Lag             int64  `json:"lag"`
+}
+
+// CheckKafkaLag compares the committed offsets of a consumer group with the latest
+// offsets of every partition it consumes. Partitions without a committed offset are skipped.
+func CheckKafkaLag(ctx context.Context, cfg KafkaCheck) ([]PartitionLag, error) {
+	client := &kafka.Client{Addr: kafka.TCP(cfg.Brokers...), Timeout: serviceCheckTimeout}
+
+	// Leaving Topics nil fetches the offsets of every topic the group has committed to
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: cfg.ConsumerGroup})
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching Kafka consumer group offsets: %w", err)
+	}
+	if committed.Error != nil {
+		return nil, fmt.Errorf("Error fetching Kafka consumer group offsets: %w", committed.Error)
+	}
+
+	latestReq := &kafka.ListOffsetsRequest{Topics: make(map[string][]kafka.OffsetRequest)}
+	for topic, partitions := range committed.Topics {
+		for _, p := range partitions {
+			latestReq.Topics[topic] = append(latestReq.Topics[topic], kafka.LastOffsetOf(p.Partition))
+		}
+	}
+	latest, err := client.ListOffsets(ctx, latestReq)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching Kafka partition offsets: %w", err)
+	}
+
+	latestOffsets := make(map[string]map[int]int64)
+	for topic, partitions := range latest.Topics {
+		latestOffsets[topic] = make(map[int]int64)
+		for _, p := range partitions {
+			if p.Error != nil {
+				return nil, fmt.Errorf("Error fetching Kafka offset of %s/%d: %w", topic, p.Partition, p.Error)
+			}
+			latestOffsets[topic][p.Partition] = p.LastOffset
+		}
+	}
+
+	var lags []PartitionLag
+	for topic, partitions := range committed.Topics {
+		for _, p := range partitions {
+			if p.Error != nil {
+				return nil, fmt.Errorf("Error fetching Kafka committed offset of %s/%d: %w", topic, p.Partition, p.Error)
+			}
+			// -1 means the group has never committed on this partition
+			if p.CommittedOffset < 0 {
+				continue
+			}
+			end := latestOffsets[topic][p.Partition]
+			lags = append(lags, PartitionLag{
+				ConsumerGroup:   cfg.ConsumerGroup,
+				Topic:           topic,
+				Partition:       p.Partition,
+				CommittedOffset: p.CommittedOffset,
+				LatestOffset:    end,
+				Lag:             max(end-p.CommittedOffset, 0),
+			})
+		}
+	}
+
+	sort.Slice(lags, func(i, j int) bool {
+		if lags[i].Topic != lags[j].Topic {
+			return lags[i].Topic < lags[j].Topic
+		}
+		return lags[i].Partition < lags[j].Partition
+	})
+	return lags, nil
+}
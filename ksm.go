@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ksmDir holds the KSM counters relative to sysfsRoot
+const ksmDir = "kernel/mm/ksm"
+
+// ErrKSMNotAvailable is returned on systems without Kernel Same-page Merging
+var ErrKSMNotAvailable = errors.New("KSM statistics are not available on this system")
+
+// KSMStat holds the Kernel Same-page Merging counters
+type KSMStat struct {
+	Running       bool   `json:"running"`        // run is 1; 0 stops merging, 2 also unmerges
+	PagesShared   uint64 `json:"pages_shared"`   // shared pages in use
+	PagesSharing  uint64 `json:"pages_sharing"`  // sites sharing them, i.e. how many pages are saved
+	PagesUnshared uint64 `json:"pages_unshared"` // unique pages checked repeatedly for merging
+	FullScans     uint64 `json:"full_scans"`
+	PageSize      uint64 `json:"page_size"` // in bytes
+}
+
+// SavedBytes returns the memory saved by merging: pages_sharing * page size
+func (s KSMStat) SavedBytes() uint64 {
+	return s.PagesSharing * s.PageSize
+}
+
+// GetKSMStats reads the KSM counters from /sys/kernel/mm/ksm
+func GetKSMStats() (KSMStat, error) {
+	stat := KSMStat{PageSize: uint64(os.Getpagesize())}
+	dir := filepath.Join(sysfsRoot, ksmDir)
+	if _, err := os.Stat(dir); err != nil {
+		return stat, ErrKSMNotAvailable
+	}
+
+	run, err := readSysfsUint(filepath.Join(dir, "run"))
+	if err != nil {
+		return stat, fmt.Errorf("Error reading KSM run state: %w", err)
+	}
+	stat.Running = run == 1
+
+	counters := []struct {
+		name  string
+		value *uint64
+	}{
+		{"pages_shared", &stat.PagesShared},
+		{"pages_sharing", &stat.PagesSharing},
+		{"pages_unshared", &stat.PagesUnshared},
+		{"full_scans", &stat.FullScans},
+	}
+	for _, counter := range counters {
+		if *counter.value, err = readSysfsUint(filepath.Join(dir, counter.name)); err != nil {
+			return stat, fmt.Errorf("Error reading KSM %s: %w", counter.name, err)
+		}
+	}
+	return stat, nil
+}
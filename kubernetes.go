@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// kubernetesResync is how often the node informer replays its cache
+const kubernetesResync = 10 * time.Minute
+
+// KubernetesConfig selects the cluster whose nodes are watched
+type KubernetesConfig struct {
+	Kubeconfig string `json:"kubeconfig"` // path of a kubeconfig file; empty uses the in-cluster service account
+}
+
+// NodeEvent reports a node that became NotReady or Ready again
+type NodeEvent struct {
+	Node       string
+	Ready      bool
+	Conditions string        // e.g. "Ready=False (KubeletNotReady: container runtime is down), DiskPressure=True"
+	Since      time.Time     // when the node became NotReady
+	NotReady   time.Duration // how long the node has been, or was, NotReady
+}
+
+// kubernetesConfig returns the client config of the kubeconfig file, or of the service
+// account of the pod when kubeconfig is empty
+func kubernetesConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig == "" {
+		return rest.InClusterConfig()
+	}
+	return clientcmd.BuildConfigFromFlags("", kubeconfig)
+}
+
+// WatchKubernetesNodes sends an event on ch whenever a node changes from Ready to NotReady or
+// back, and one for each node that is NotReady when the watch starts. It blocks until ctx is done.
+func WatchKubernetesNodes(ctx context.Context, kubeconfig string, ch chan<- NodeEvent) error {
+	config, err := kubernetesConfig(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("Error loading Kubernetes config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("Error creating Kubernetes client: %w", err)
+	}
+
+	factory := informers.NewSharedInformerFactory(clientset, kubernetesResync)
+	defer factory.Shutdown()
+	informer := factory.Core().V1().Nodes().Informer()
+
+	send := func(event NodeEvent) {
+		select {
+		case ch <- event:
+		case <-ctx.Done():
+		}
+	}
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			// Nodes that join later start out NotReady; only report those found NotReady at startup
+			node, ok := obj.(*corev1.Node)
+			if !ok || informer.HasSynced() {
+				return
+			}
+			if ready, condition := nodeReady(node); !ready {
+				send(newNodeEvent(node, false, condition.LastTransitionTime.Time))
+			}
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			oldNode, ok := oldObj.(*corev1.Node)
+			newNode, ok2 := newObj.(*corev1.Node)
+			if !ok || !ok2 {
+				return
+			}
+			wasReady, oldCondition := nodeReady(oldNode)
+			ready, condition := nodeReady(newNode)
+			switch {
+			case wasReady && !ready:
+				send(newNodeEvent(newNode, false, condition.LastTransitionTime.Time))
+			case !wasReady && ready:
+				send(newNodeEvent(newNode, true, oldCondition.LastTransitionTime.Time))
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Error watching Kubernetes nodes: %w", err)
+	}
+
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) && ctx.Err() == nil {
+		return fmt.Errorf("Error listing Kubernetes nodes: the cache did not sync")
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// nodeReady returns whether the Ready condition of the node is True, together with the condition
+func nodeReady(node *corev1.Node) (bool, corev1.NodeCondition) {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue, condition
+		}
+	}
+	return false, corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}
+}
+
+// newNodeEvent creates the event of a node that became NotReady at since, or Ready again
+// after being NotReady since then
+func newNodeEvent(node *corev1.Node, ready bool, since time.Time) NodeEvent {
+	event := NodeEvent{Node: node.Name, Ready: ready, Conditions: formatNodeConditions(node), Since: since}
+	if !since.IsZero() {
+		event.NotReady = time.Since(since).Round(time.Second)
+	}
+	return event
+}
+
+// formatNodeConditions lists the Ready condition and every other condition that is not False
+func formatNodeConditions(node *corev1.Node) string {
+	var parts []string
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady && condition.Status == corev1.ConditionFalse {
+			continue
+		}
+		part := fmt.Sprintf("%s=%s", condition.Type, condition.Status)
+		if condition.Reason != "" {
+			part += fmt.Sprintf(" (%s: %s)", condition.Reason, condition.Message)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatNodeAlert formats a node event for an alert
+func FormatNodeAlert(event NodeEvent) string {
+	if event.Ready {
+		return fmt.Sprintf("Kubernetes node %s is Ready again after being NotReady for %s\nConditions: %s",
+			event.Node, event.NotReady, event.Conditions)
+	}
+	return fmt.Sprintf("Alert: Kubernetes node %s is NotReady since %s (%s)\nConditions: %s",
+		event.Node, event.Since.Format(time.RFC3339), event.NotReady, event.Conditions)
+}
@@ -0,0 +1,257 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// defaultConfigKey is the KV key the config is read from unless --config-key is given
+	defaultConfigKey = "go-system-monitor/config"
+	// consulWatchWait is how long a blocking Consul query waits for the key to change
+	consulWatchWait = 5 * time.Minute
+	// kvWatchRetryDelay is the pause before watching again after a failed watch
+	kvWatchRetryDelay = 10 * time.Second
+)
+
+// parseKVConfig parses a configuration stored in etcd or Consul. A KV value has no directory
+// to resolve include paths against, so include is rejected rather than silently ignored.
+func parseKVConfig(data []byte) (Config, error) {
+	config, err := parseConfig(data)
+	if err != nil {
+		return Config{}, err
+	}
+	if len(config.Include) > 0 {
+		return Config{}, fmt.Errorf("could not parse config: include is only supported in config files")
+	}
+	return config, nil
+}
+
+// newEtcdClient connects to the etcd cluster at endpoint
+func newEtcdClient(endpoint string) (*clientv3.Client, error) {
+	client, err := clientv3.New(clientv3.Config{Endpoints: []string{endpoint}, DialTimeout: serviceCheckTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to etcd: %w", err)
+	}
+	return client, nil
+}
+
+// ReadConfigFromEtcd reads the monitor configuration from the JSON value of key in etcd
+func ReadConfigFromEtcd(ctx context.Context, endpoint, key string) (Config, error) {
+	client, err := newEtcdClient(endpoint)
+	if err != nil {
+		return Config{}, err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+	resp, err := client.Get(ctx, key)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read config from etcd: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return Config{}, fmt.Errorf("could not read config from etcd: key %q does not exist", key)
+	}
+	return parseKVConfig(resp.Kvs[0].Value)
+}
+
+// WatchConfigFromEtcd sends the configuration every time key is changed in etcd until ctx is
+// done. Invalid configurations are logged and skipped.
+func WatchConfigFromEtcd(ctx context.Context, endpoint, key string) (<-chan Config, error) {
+	client, err := newEtcdClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan Config)
+	go func() {
+		defer close(updates)
+		defer client.Close()
+		// A restarted watch resumes after the last revision seen so changes made in between are not lost
+		var rev int64
+		for ctx.Err() == nil {
+			var opts []clientv3.OpOption
+			if rev > 0 {
+				opts = append(opts, clientv3.WithRev(rev+1))
+			}
+			for resp := range client.Watch(ctx, key, opts...) {
+				if resp.CompactRevision != 0 {
+					// The revisions since rev were compacted; resume at the oldest one that is left
+					rev = resp.CompactRevision - 1
+				}
+				if err := resp.Err(); err != nil {
+					log.Printf("Error watching config in etcd: %v\n", err)
+					continue
+				}
+				rev = resp.Header.Revision
+				for _, event := range resp.Events {
+					if event.Type != clientv3.EventTypePut {
+						log.Printf("Config key %q was deleted from etcd; keeping the current config\n", key)
+						continue
+					}
+					config, err := parseKVConfig(event.Kv.Value)
+					if err != nil {
+						log.Printf("Error reloading config from etcd: %v\n", err)
+						continue
+					}
+					select {
+					case updates <- config:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			// The watch channel closes when ctx is done or the watch was canceled by the server
+			select {
+			case <-ctx.Done():
+			case <-time.After(kvWatchRetryDelay):
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// newConsulClient connects to the Consul agent at address, or the one configured by CONSUL_HTTP_ADDR when empty
+func newConsulClient(address string) (*api.Client, error) {
+	consulConfig := api.DefaultConfig()
+	if address != "" {
+		consulConfig.Address = address
+	}
+	client, err := api.NewClient(consulConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to Consul: %w", err)
+	}
+	return client, nil
+}
+
+// readConsulConfig reads key with the given query options and returns the raw pair with the query index
+func readConsulConfig(client *api.Client, key string, query *api.QueryOptions) (*api.KVPair, uint64, error) {
+	pair, meta, err := client.KV().Get(key, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not read config from Consul: %w", err)
+	}
+	if pair == nil {
+		return nil, meta.LastIndex, fmt.Errorf("could not read config from Consul: key %q does not exist", key)
+	}
+	return pair, meta.LastIndex, nil
+}
+
+// ReadConfigFromConsul reads the monitor configuration from the JSON value of key in the Consul KV store
+func ReadConfigFromConsul(ctx context.Context, address, key string) (Config, error) {
+	client, err := newConsulClient(address)
+	if err != nil {
+		return Config{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+	pair, _, err := readConsulConfig(client, key, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return Config{}, err
+	}
+	return parseKVConfig(pair.Value)
+}
+
+// WatchConfigFromConsul sends the configuration every time key is changed in Consul until ctx
+// is done, using blocking queries. Invalid configurations are logged and skipped.
+func WatchConfigFromConsul(ctx context.Context, address, key string) (<-chan Config, error) {
+	client, err := newConsulClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan Config)
+	go func() {
+		defer close(updates)
+		var index uint64
+		for ctx.Err() == nil {
+			query := (&api.QueryOptions{WaitIndex: index, WaitTime: consulWatchWait}).WithContext(ctx)
+			pair, lastIndex, err := readConsulConfig(client, key, query)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("Error watching config in Consul: %v\n", err)
+				select {
+				case <-ctx.Done():
+				case <-time.After(kvWatchRetryDelay):
+				}
+				continue
+			}
+
+			// The first query only records the index of the config that was already read;
+			// an unchanged index means the blocking query timed out
+			changed := index != 0 && lastIndex != index
+			if lastIndex < index {
+				// The index went backwards, e.g. after a Consul snapshot restore
+				index = 0
+			} else {
+				index = lastIndex
+			}
+			if !changed {
+				continue
+			}
+
+			config, err := parseKVConfig(pair.Value)
+			if err != nil {
+				log.Printf("Error reloading config from Consul: %v\n", err)
+				continue
+			}
+			select {
+			case updates <- config:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// ConfigSource is where the configuration is read from: a file, or a key in etcd or Consul
+type ConfigSource struct {
+	File   string
+	Etcd   string // etcd endpoint
+	Consul string // Consul agent address
+	Key    string
+}
+
+func (s ConfigSource) String() string {
+	switch {
+	case s.Etcd != "":
+		return fmt.Sprintf("etcd key %s at %s", s.Key, s.Etcd)
+	case s.Consul != "":
+		return fmt.Sprintf("Consul key %s at %s", s.Key, s.Consul)
+	default:
+		return s.File
+	}
+}
+
+// Read reads the configuration from the source
+func (s ConfigSource) Read(ctx context.Context) (Config, error) {
+	switch {
+	case s.Etcd != "":
+		return ReadConfigFromEtcd(ctx, s.Etcd, s.Key)
+	case s.Consul != "":
+		return ReadConfigFromConsul(ctx, s.Consul, s.Key)
+	default:
+		return ReadConfig(s.File)
+	}
+}
+
+// Watch sends the configuration every time it changes. Config files are not watched and
+// return a nil channel.
+func (s ConfigSource) Watch(ctx context.Context) (<-chan Config, error) {
+	switch {
+	case s.Etcd != "":
+		return WatchConfigFromEtcd(ctx, s.Etcd, s.Key)
+	case s.Consul != "":
+		return WatchConfigFromConsul(ctx, s.Consul, s.Key)
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPConfig describes the directory used to authenticate API users
+type LDAPConfig struct {
+	URL          string `json:"url"`     // e.g. ldaps://ldap.example.com:636
+	BindDN       string `json:"bind_dn"` // service account used to look up users
+	BindPassword string `json:"bind_password"`
+	UserBaseDN   string `json:"user_base_dn"` // e.g. ou=people,dc=example,dc=com
+	UserFilter   string `json:"user_filter"`  // %s is replaced by the username, e.g. (sAMAccountName=%s)
+}
+
+// AuthenticateLDAP looks up username with the service account and verifies the
+// password by binding as that user
+func AuthenticateLDAP(cfg LDAPConfig, username, password string) (bool, error) {
+	// An empty password would be an unauthenticated bind, which most servers accept
+	if username == "" || password == "" {
+		return false, nil
+	}
+
+	conn, err := ldap.DialURL(cfg.URL)
+	if err != nil {
+		return false, fmt.Errorf("Error connecting to LDAP server: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+		return false, fmt.Errorf("Error binding to LDAP server: %w", err)
+	}
+
+	search := ldap.NewSearchRequest(
+		cfg.UserBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(cfg.UserFilter, ldap.EscapeFilter(username)), []string{"dn"}, nil)
+	result, err := conn.Search(search)
+	if err != nil {
+		return false, fmt.Errorf("Error searching LDAP user: %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return false, nil
+	}
+
+	if err := conn.Bind(result.Entries[0].DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return false, nil
+		}
+		return false, fmt.Errorf("Error verifying LDAP credentials: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"io"
+	"log"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Log file rotation defaults
+const (
+	defaultLogMaxSizeMB  = 100
+	defaultLogMaxBackups = 5
+	defaultLogMaxAgeDays = 30
+)
+
+// LogConfig describes the log file of the monitor and when it is rotated
+type LogConfig struct {
+	FilePath   string `json:"file_path"`
+	MaxSizeMB  int    `json:"max_size_mb"`  // rotate once the file reaches this size; defaults to 100
+	MaxBackups int    `json:"max_backups"`  // rotated files to keep; defaults to 5
+	MaxAgeDays int    `json:"max_age_days"` // remove rotated files older than this; defaults to 30
+}
+
+// SetupLogFile sends the log and the status lines to the rotated log file of cfg.
+// The returned closer closes the file.
+func SetupLogFile(cfg LogConfig) io.Closer {
+	logger := &lumberjack.Logger{
+		Filename:   cfg.FilePath,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+		Compress:   true,
+	}
+	if logger.MaxSize <= 0 {
+		logger.MaxSize = defaultLogMaxSizeMB
+	}
+	if logger.MaxBackups <= 0 {
+		logger.MaxBackups = defaultLogMaxBackups
+	}
+	if logger.MaxAge <= 0 {
+		logger.MaxAge = defaultLogMaxAgeDays
+	}
+
+	log.SetOutput(logger)
+	StatusOutput = logger
+	return logger
+}
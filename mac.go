@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrMACNotAvailable is returned on systems without SELinux and AppArmor support, i.e. other than Linux
+var ErrMACNotAvailable = errors.New("SELinux and AppArmor are not available on this system")
+
+// SELinux modes and AppArmor states of MACStatus
+const (
+	macEnforcing  = "enforcing"
+	macPermissive = "permissive"
+	macEnabled    = "enabled"
+	macDisabled   = "disabled"
+)
+
+// MACStatus is the state of the mandatory access control systems of the kernel
+type MACStatus struct {
+	SELinux          string `json:"selinux"`  // enforcing, permissive or disabled
+	AppArmor         string `json:"apparmor"` // enabled or disabled
+	AppArmorEnforced int    `json:"apparmor_enforced"`
+	AppArmorComplain int    `json:"apparmor_complain"`
+}
+
+// AppArmorMode describes the AppArmor state with its profiles, e.g. "enabled (12 enforced, 3 complain)"
+func (s MACStatus) AppArmorMode() string {
+	if s.AppArmor != macEnabled || s.AppArmorEnforced+s.AppArmorComplain == 0 {
+		return s.AppArmor
+	}
+	return fmt.Sprintf("%s (%d enforced, %d complain)", s.AppArmor, s.AppArmorEnforced, s.AppArmorComplain)
+}
+
+// parseAAStatus counts the enforced and complaining profiles in the output of 'aa-status --json':
+//
+//	{"version": "2", "profiles": {"/usr/sbin/cupsd": "enforce", "/usr/bin/man": "complain"}, "processes": {...}}
+func parseAAStatus(data []byte) (enforced, complain int, err error) {
+	var status struct {
+		Profiles map[string]string `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return 0, 0, fmt.Errorf("Error parsing aa-status output: %w", err)
+	}
+	for _, mode := range status.Profiles {
+		switch mode {
+		case "enforce":
+			enforced++
+		case "complain":
+			complain++
+		}
+	}
+	return enforced, complain, nil
+}
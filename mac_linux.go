@@ -0,0 +1,57 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GetMACStatus reads the SELinux mode from /sys/fs/selinux/enforce and the AppArmor profiles
+// from 'aa-status --json', which needs root. Without aa-status, AppArmor is reported enabled
+// when the kernel module says so, without profile counts.
+func GetMACStatus() (MACStatus, error) {
+	status := MACStatus{SELinux: macDisabled, AppArmor: macDisabled}
+
+	// The file only exists while SELinux is enabled: 1 is enforcing, 0 permissive
+	enforce, err := os.ReadFile(filepath.Join(sysfsRoot, "fs/selinux/enforce"))
+	switch {
+	case err == nil && strings.TrimSpace(string(enforce)) == "1":
+		status.SELinux = macEnforcing
+	case err == nil:
+		status.SELinux = macPermissive
+	case !errors.Is(err, os.ErrNotExist):
+		return status, fmt.Errorf("Error reading SELinux mode: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serviceCheckTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "aa-status", "--json").Output()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		status.AppArmor = macEnabled
+		status.AppArmorEnforced, status.AppArmorComplain, err = parseAAStatus(output)
+		if err != nil {
+			return status, err
+		}
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		// Exit status 1 means AppArmor is not enabled
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 2:
+		// Exit status 2 means AppArmor is enabled without any policy loaded
+		status.AppArmor = macEnabled
+	case errors.Is(err, exec.ErrNotFound):
+		enabled, err := os.ReadFile(filepath.Join(sysfsRoot, "module/apparmor/parameters/enabled"))
+		if err == nil && strings.TrimSpace(string(enabled)) == "Y" {
+			status.AppArmor = macEnabled
+		}
+	default:
+		return status, fmt.Errorf("Error running aa-status: %w", err)
+	}
+	return status, nil
+}
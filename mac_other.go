@@ -0,0 +1,8 @@
+//go:build !linux
+
+package monitor
+
+// GetMACStatus is only supported on Linux
+func GetMACStatus() (MACStatus, error) {
+	return MACStatus{}, ErrMACNotAvailable
+}
@@ -1,219 +1,1606 @@
-package main
+package monitor
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"github.com/shirou/gopsutil/v4/cpu"
-	"github.com/shirou/gopsutil/v4/disk"
-	"github.com/shirou/gopsutil/v4/mem"
-	"io/ioutil"
+	"github.com/coreos/go-systemd/v22/daemon"
+	"github.com/coreos/go-systemd/v22/journal"
+	"io"
 	"log"
-	"net/smtp"
-	"os/exec"
+	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 // SMTPConfig holds the SMTP server configuration
 type SMTPConfig struct {
-	SMTPHost      string `json:"smtp_host"`
-	SMTPPort      string `json:"smtp_port"`
-	FromEmail     string `json:"from_email"`
-	EmailPassword string `json:"email_password"`
-	ToEmail       string `json:"to_email"`
+	SMTPHost      string   `json:"smtp_host"`
+	SMTPPort      string   `json:"smtp_port"`
+	FromEmail     string   `json:"from_email"`
+	EmailPassword string   `json:"email_password"`
+	ToEmail       string   `json:"to_email"`
+	CC            []string `json:"cc"`  // copied recipients, listed in the Cc header
+	BCC           []string `json:"bcc"` // blind copied recipients, e.g. an audit log mailbox
+
+	SMTPMaxConnections int    `json:"smtp_max_connections"` // persistent connections kept open; defaults to 1
+	EmailFooter        string `json:"email_footer"`         // text/template appended to every email
+	MaxRetryAttempts   int    `json:"max_retry_attempts"`   // attempts per email before it is given up; defaults to 3
+	OAuth2TokenFile    string `json:"oauth2_token_file"`    // authenticate with OAuth2 (XOAUTH2) instead of email_password
+	EmailCharts        bool   `json:"email_charts"`         // embed sparklines of the alerting metrics (needs history_db)
+
+	AttachLogs []LogAttachment `json:"attach_logs"` // log files whose end is attached to alert emails
 }
 
-// Send email function
-func sendEmail(config SMTPConfig, subject, body string) {
-	// Email content
-	subjectLine := "Subject: " + subject + "\n"
-	message := []byte(subjectLine + "\n" + body)
+// Config holds the monitor configuration. The SMTP settings are embedded so
+// they stay at the top level of config.json.
+type Config struct {
+	Include []string `json:"include"` // config files merged into this one; see LoadConfigWithIncludes
 
-	// Set up authentication information.
-	auth := smtp.PlainAuth("", config.FromEmail, config.EmailPassword, config.SMTPHost)
+	SMTPConfig
+	JournalUnits       []string                `json:"journal_units"`       // systemd units watched for critical journal entries
+	CollectionInterval int                     `json:"collection_interval"` // seconds between checks; 0 runs the checks once
+	Metrics            map[string]MetricConfig `json:"metrics"`             // per-metric collection intervals, e.g. {"disk": {"interval": "5m"}}
+	APIAddr            string                  `json:"api_addr"`            // listen address of the HTTP API, e.g. ":8080"
+	APIUsername        string                  `json:"api_username"`        // static basic auth credentials of the HTTP API
+	APIPassword        string                  `json:"api_password"`
+	ProbeAddr          string                  `json:"probe_addr"` // listen address of the GET /healthz liveness probe, e.g. ":8081"
+	LDAP               *LDAPConfig             `json:"ldap"`       // authenticate HTTP API users against LDAP / Active Directory
 
-	// Send the email
-	err := smtp.SendMail(config.SMTPHost+":"+config.SMTPPort, auth, config.FromEmail, []string{config.ToEmail}, message)
-	if err != nil {
-		log.Fatalf("Error sending email: %v\n", err)
-	} else {
-		fmt.Println("Alert email sent successfully!")
-	}
+	CorrelationGroups []CorrelationGroup `json:"correlation_groups"`
+	Syslog            *SyslogConfig      `json:"syslog"`     // forward alert events to syslog when set
+	Mattermost        *MattermostConfig  `json:"mattermost"` // post alerts to a Mattermost incoming webhook
+
+	DNSChecks        []DNSCheck        `json:"dns_checks"`
+	TracerouteChecks []TracerouteCheck `json:"traceroute_checks"` // needs root or CAP_NET_RAW
+	CiliumDrops      bool              `json:"cilium_drops"`      // read the drop counters of the local Cilium agent
+	GeoIPDBPath      string            `json:"geoip_db_path"`     // MaxMind database adding the location of IP addresses to network alerts
+
+	CTChecks []CTCheck `json:"ct_checks"` // domains whose new certificates in the Certificate Transparency logs alert
+
+	CheckUpdates    *bool                    `json:"check_updates"`     // look for a newer release at startup; defaults to true
+	USBTempSensor   bool                     `json:"usb_temp_sensor"`   // read the ambient temperature from a TEMPer USB thermometer
+	GPUTemp         bool                     `json:"gpu_temp"`          // read the GPU temperature with powermetrics (macOS)
+	GPIOTempSensors []GPIOTempSensor         `json:"gpio_temp_sensors"` // DS18B20 1-Wire sensors; discovered when empty
+	ThermalSensors  []ThermalSensorThreshold `json:"thermal_sensors"`   // thresholds of lm-sensors temperatures by sensor name pattern
+	SocketMaxTempC  float64                  `json:"socket_max_temp_c"` // alert when a single CPU socket is hotter; 0 skips the per-socket check
+	TemperatureUnit TempUnit                 `json:"temperature_unit"`  // celsius, fahrenheit or kelvin; also the unit of the thresholds above
+	MemBandwidth    bool                     `json:"memory_bandwidth"`  // sample the memory controller bandwidth with perf
+
+	RequiredMicrocodeVersion string `json:"required_microcode_version"` // e.g. 0xf0; alert at startup when the CPU runs older microcode
+
+	EnableVulnScan bool              `json:"enable_vuln_scan"` // scan the container image of an alerting workload with trivy
+	VulnScanImages map[string]string `json:"vuln_scan_images"` // metric name to container image, e.g. {"redis": "redis:7.2"}
+
+	IPMIConfig // BMC hardware sensors of bare-metal servers
+
+	DiskQuotas   bool     `json:"monitor_disk_quotas"` // alert on users and groups over their disk quota (Linux, needs root)
+	ExcludeUsers []string `json:"exclude_users"`       // users and groups whose quotas are not checked
+
+	KSMMonitoring bool `json:"ksm_monitoring"` // report Kernel Same-page Merging savings and alert when it is off (Linux)
+
+	RequireSELinuxEnforcing bool `json:"require_selinux_enforcing"` // alert unless SELinux is enforcing (Linux)
+	RequireAppArmorEnabled  bool `json:"require_apparmor_enabled"`  // alert unless AppArmor is enabled (Linux)
+
+	CronChecks     []CronCheck     `json:"cron_checks"`
+	FileSizeChecks []FileSizeCheck `json:"file_size_checks"` // files and directories that must not grow too large
+	SNMPTraps      *SNMPTrapConfig `json:"snmp_traps"`
+
+	MonitorCrontabs bool     `json:"monitor_crontabs"` // alert immediately when a crontab is created, modified or deleted
+	CrontabPaths    []string `json:"crontab_paths"`    // crontab files and directories to watch; defaults to defaultCrontabPaths
+
+	KubernetesNodes *KubernetesConfig `json:"kubernetes_nodes"` // alert when cluster nodes become NotReady
+
+	HistoryDB string `json:"history_db"` // SQLite file keeping the alert history
+
+	ElasticsearchClusters []ElasticsearchCluster `json:"elasticsearch_clusters"`
+	PostgresChecks        []PostgresCheck        `json:"postgres_checks"`
+	RedisChecks           []RedisCheck           `json:"redis_checks"`
+	RedisSentinelChecks   []SentinelCheck        `json:"redis_sentinel_checks"` // failovers and replica links of Sentinel-managed masters
+	MongoChecks           []MongoCheck           `json:"mongo_checks"`
+	RabbitMQChecks        []RabbitMQCheck        `json:"rabbitmq_checks"`
+	KafkaChecks           []KafkaCheck           `json:"kafka_checks"`
+	MySQLChecks           []MySQLCheck           `json:"mysql_checks"`
+	RDSChecks             []RDSCheck             `json:"rds_checks"`
+	ConsulChecks          []ConsulCheck          `json:"consul_checks"`
+	VaultChecks           []VaultCheck           `json:"vault_checks"`
+	EtcdChecks            []EtcdCheck            `json:"etcd_checks"`
+	HAProxyChecks         []HAProxyCheck         `json:"haproxy_checks"`
+	NginxChecks           []NginxCheck           `json:"nginx_checks"`
+	ApacheChecks          []ApacheCheck          `json:"apache_checks"`
+	ProcessChecks         []ProcessCheck         `json:"process_checks"`
+	AutoRestart           bool                   `json:"auto_restart"` // run the restart_command of missing processes
+
+	AlertTemplate string `json:"alert_template"` // Go template of the alert messages; see RenderAlertMessage
+
+	TimeBasedPriority []PriorityRule `json:"time_based_priority"` // override the alert severity by time of day, e.g. downgrade during business hours
+
+	MaxConsecutiveFailures int `json:"max_consecutive_failures"`   // alert once a metric fails this often in a row (default 3)
+	MaxStalenessSeconds    int `json:"max_staleness_seconds"`      // alert once a metric has not been collected for this long
+	AlertGroupWindow       int `json:"alert_group_window_seconds"` // alerts within this many seconds share one email (default 30)
+	WatchdogInterval       int `json:"watchdog_interval_seconds"`  // systemd watchdog notification interval; defaults to half of WatchdogSec
+
+	Log *LogConfig `json:"log"` // rotated log file; --log-file overrides the path
+
+	OTelEndpoint string `json:"otel_endpoint"` // OTLP gRPC endpoint the collection traces are exported to, e.g. http://otel-collector:4317
+
+	MachineClass string     `json:"machine_class"` // web, database or batch; selects the default thresholds
+	Thresholds   Thresholds `json:"thresholds"`    // overrides the machine class defaults
+
+	Labels      map[string]string    `json:"labels"` // attached as tags to exported metrics
+	Datadog     *DatadogConfig       `json:"datadog"`
+	OTLPMetrics *OTLPMetricsConfig   `json:"otlp_metrics"`
+	OpsGenie    *OpsGenieConfig      `json:"opsgenie"`
+	GCP         *GCPMonitoringConfig `json:"gcp_monitoring"`
+	Azure       *AzureMonitorConfig  `json:"azure_monitor"`
+	CloudWatch  *CloudWatchConfig    `json:"cloudwatch"`
 }
 
-// ReadSMTPConfig reads the SMTP configuration from a file
-func ReadSMTPConfig(filePath string) (SMTPConfig, error) {
-	data, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		return SMTPConfig{}, fmt.Errorf("could not read config file: %w", err)
+// Send email function. Transient SMTP errors are retried with backoff; the returned error
+// means the email could not be delivered at all.
+func sendEmail(ctx context.Context, client *SMTPClient, subject, body string) error {
+	return sendEmailMessage(ctx, client, &EmailMessage{Subject: subject, Body: body})
+}
+
+// sendEmailMessage sends msg like sendEmail
+func sendEmailMessage(ctx context.Context, client *SMTPClient, msg *EmailMessage) error {
+	maxAttempts := client.config.MaxRetryAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxRetryAttempts
 	}
 
-	var config SMTPConfig
-	err = json.Unmarshal(data, &config)
+	// Send the email over a pooled connection
+	err := RetryWithBackoff(ctx, func() error {
+		return client.Send(msg)
+	}, maxAttempts, emailRetryDelay)
 	if err != nil {
-		return SMTPConfig{}, fmt.Errorf("could not parse config file: %w", err)
+		log.Printf("Error sending email: %v\n", err)
+		return err
 	}
+	fmt.Fprintln(StatusOutput, "Alert email sent successfully!")
+	return nil
+}
+
+// ReadConfig reads the monitor configuration from a file and the files it includes
+func ReadConfig(filePath string) (Config, error) {
+	return LoadConfigWithIncludes(filePath)
+}
+
+// parseConfig parses and validates a JSON configuration
+func parseConfig(data []byte) (Config, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("could not parse config: %w", err)
+	}
+	if err := validateMachineClass(config.MachineClass); err != nil {
+		return Config{}, err
+	}
+	if err := validateMetricIntervals(config.Metrics); err != nil {
+		return Config{}, err
+	}
+	if err := validateEmailAddresses("cc", config.CC); err != nil {
+		return Config{}, err
+	}
+	if err := validateEmailAddresses("bcc", config.BCC); err != nil {
+		return Config{}, err
+	}
+	if err := validateAlertTemplate(config.AlertTemplate); err != nil {
+		return Config{}, err
+	}
+	if err := validateTempUnit(config.TemperatureUnit); err != nil {
+		return Config{}, err
+	}
+	if err := validateMicrocodeVersion(config.RequiredMicrocodeVersion); err != nil {
+		return Config{}, err
+	}
+	if err := validateCTChecks(config.CTChecks); err != nil {
+		return Config{}, err
+	}
+	if err := validatePriorityRules(config.TimeBasedPriority); err != nil {
+		return Config{}, err
+	}
+	if err := validateSyslogConfig(config.Syslog); err != nil {
+		return Config{}, err
+	}
+	config.normalizeTemperatureThresholds()
 
 	return config, nil
 }
 
-// GetFanSpeeds returns the fan speeds using the 'sensors' command on Linux
-func GetFanSpeeds() (string, error) {
-	// Run the 'sensors' command (make sure lm-sensors is installed)
-	cmd := exec.Command("sensors")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("Error fetching fan speeds: %w", err)
+// StatusOutput receives the human-readable status lines. It is switched to
+// stderr when --output is used so stdout only carries the formatted report.
+// Programs using the package as a library may set it to io.Discard.
+var StatusOutput io.Writer = os.Stdout
+
+// Thresholds
+const (
+	maxTemp          = 90.0 // Max temperature in °C
+	minTemp          = 80.0 // Min temperature in °C
+	minFanSpeed      = 3500 // Min fan speed in RPM
+	maxFanSpeed      = 5000 // Max fan speed in RPM
+	maxClockSpeed    = 3.20 // Max clock speed in GHz
+	maxPackagePowerW = 95.0 // Max CPU package power draw in W (thermal design power)
+
+	maxThrottleEventsPerSec = 1.0  // Max thermal throttle events per second on a single core
+	maxAmbientTempC         = 35.0 // Max ambient (room) temperature in °C
+	maxGPUTempC             = 90.0 // Max GPU temperature in °C
+
+	maxCorrectableErrorsPerHour = 10.0  // Max rate of correctable ECC memory errors
+	maxMemBandwidthGBps         = 20.0  // Max combined memory read and write bandwidth in GB/s
+	maxTmpfsPercent             = 90.0  // Max usage of a tmpfs mount in percent
+	maxDiskAwaitMs              = 100.0 // Max average I/O request time of a block device in ms
+	maxCiliumDropsPerSec        = 10.0  // Max rate of packets dropped by Cilium, e.g. denied by a network policy
+	maxNICDropsPerSec           = 100.0 // Max rate of packets a network interface drops, received and sent together
+	minHugePagesFreePercent     = 10.0  // Min share of the huge page pool that is neither used nor reserved
+)
+
+// serviceCheckTimeout bounds a single check of an external service
+const serviceCheckTimeout = 10 * time.Second
+
+// Main runs the go-system-monitor command: it parses the flags, reads the config and runs
+// the checks once or, in daemon mode, until interrupted
+func Main() {
+	if err := run(); err != nil {
+		log.Fatalf("%v\n", err)
 	}
-	return string(output), nil
 }
 
-func main() {
-	// Read SMTP configuration from config file
-	config, err := ReadSMTPConfig("config.json")
-	if err != nil {
-		log.Fatalf("Error reading SMTP config: %v\n", err)
+// run is the body of Main. Errors are returned rather than exiting so the deferred cleanup,
+// like removing the PID file, runs before the process ends.
+func run() error {
+	output := flag.String("output", "", "print each metric report to stdout as json, csv or table")
+	showVersion := flag.Bool("version", false, "print version information and exit")
+	inventory := flag.Bool("inventory", false, "print the host inventory as JSON and exit")
+	exportAlerts := flag.Bool("export-alerts", false, "export the alert history to stdout and exit")
+	exportFrom := flag.String("from", "", "start of the --export-alerts range (YYYY-MM-DD or RFC 3339)")
+	exportTo := flag.String("to", "", "end of the --export-alerts range (YYYY-MM-DD or RFC 3339); defaults to now")
+	exportFormat := flag.String("format", OutputCSV, "--export-alerts format: csv or json")
+	configEtcd := flag.String("config-etcd", "", "read the config from this etcd endpoint instead of config.json and reload it when it changes")
+	configConsul := flag.String("config-consul", "", "read the config from the Consul agent at this address instead of config.json and reload it when it changes")
+	configKey := flag.String("config-key", defaultConfigKey, "key of the config in etcd or Consul")
+	logFile := flag.String("log-file", "", "write the log to this file, rotated by size (e.g. /var/log/monitor.log)")
+	pidFile := flag.String("pid-file", "", "write the process ID to this file (e.g. /var/run/monitor.pid) and refuse to start if the process in it is running")
+	selfTest := flag.Bool("self-test", false, "run the alert pipeline on artificial out-of-threshold values, check the alert channels without sending, and exit")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(GetBuildInfo())
+		return nil
 	}
 
-	// Thresholds
-	const (
-		maxTemp            = 90.0 // Max temperature in °C
-		minTemp            = 80.0 // Min temperature in °C
-		minFanSpeed        = 3500 // Min fan speed in RPM
-		maxFanSpeed        = 5000 // Max fan speed in RPM
-		maxClockSpeed      = 3.20 // Max clock speed in GHz
-		cpuUsageThreshold  = 80.0 // Max CPU usage in %
-		memUsageThreshold  = 80.0 // Max memory usage in %
-		diskUsageThreshold = 50.0 // Max disk usage in %
-	)
+	if *inventory {
+		fingerprint, err := GetHostFingerprint(context.Background())
+		if err != nil {
+			return fmt.Errorf("Error collecting host inventory: %w", err)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(fingerprint); err != nil {
+			return fmt.Errorf("Error writing host inventory: %w", err)
+		}
+		return nil
+	}
 
-	alertMessage := ""
+	switch *output {
+	case "":
+	case OutputJSON, OutputCSV, OutputTable:
+		StatusOutput = os.Stderr
+	default:
+		return fmt.Errorf("Unknown output format %q (expected json, csv or table)", *output)
+	}
 
-	// Monitor CPU Temperature (using sensors command for Linux)
-	temps, err := GetCPUTemperature()
+	// Read configuration from config file, or from etcd or Consul
+	source := ConfigSource{File: "config.json", Etcd: *configEtcd, Consul: *configConsul, Key: *configKey}
+	config, err := source.Read(context.Background())
 	if err != nil {
-		log.Fatalf("Error fetching CPU temperature: %v\n", err)
+		return fmt.Errorf("Error reading config: %w", err)
+	}
+
+	// Rotate the log file so a long-running daemon does not fill the disk
+	logConfig := LogConfig{}
+	if config.Log != nil {
+		logConfig = *config.Log
+	}
+	if *logFile != "" {
+		logConfig.FilePath = *logFile
+	}
+	if logConfig.FilePath != "" {
+		defer SetupLogFile(logConfig).Close()
+	}
+
+	if *selfTest {
+		if !PrintSelfTestSummary(os.Stdout, RunSelfTest(context.Background(), config)) {
+			os.Exit(1)
+		}
+		return nil
+	}
+
+	// Alert history, kept when history_db is configured
+	var store *MetricStore
+	if config.HistoryDB != "" {
+		store, err = OpenMetricStore(config.HistoryDB)
+		if err != nil {
+			return fmt.Errorf("Error opening alert history: %w", err)
+		}
+		defer store.Close()
+	}
+
+	if *exportAlerts {
+		if store == nil {
+			return errors.New("Cannot export alerts: history_db is not configured")
+		}
+		from, to := time.Time{}, time.Now()
+		if *exportFrom != "" {
+			if from, err = parseExportTime(*exportFrom, false); err != nil {
+				return fmt.Errorf("Invalid --from: %w", err)
+			}
+		}
+		if *exportTo != "" {
+			if to, err = parseExportTime(*exportTo, true); err != nil {
+				return fmt.Errorf("Invalid --to: %w", err)
+			}
+		}
+		if err := ExportAlertHistory(store, *exportFormat, from, to, os.Stdout); err != nil {
+			return fmt.Errorf("Error exporting alerts: %w", err)
+		}
+		return nil
+	}
+
+	// Let init scripts and other tools find the daemon; a second instance would send duplicate alerts
+	if *pidFile != "" {
+		if err := WritePIDFile(*pidFile); err != nil {
+			return fmt.Errorf("Error writing PID file: %w", err)
+		}
+		defer RemovePIDFile(*pidFile)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// A server or watcher that fails stops the monitor like a signal; run returns its error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fatal := make(chan error, 1)
+	fail := func(err error) {
+		select {
+		case fatal <- err:
+			cancel()
+		default:
+		}
+	}
+	fatalError := func() error {
+		select {
+		case err := <-fatal:
+			return err
+		default:
+			return nil
+		}
+	}
+
+	// Latest collected metrics, shared with the probe and API servers
+	snapshot := &SafeSnapshot{}
+
+	// The liveness probe starts before the rest of the initialization so orchestrators see the
+	// monitor while it initializes; the one-shot modes above have returned and never bind it
+	if config.ProbeAddr != "" {
+		startedAt := time.Now()
+		go func() {
+			healthy := func() bool { return monitorHealthy(config, snapshot.Get(), startedAt, time.Now()) }
+			if err := StartProbeServer(config.ProbeAddr, healthy); err != nil {
+				fail(fmt.Errorf("Error starting probe server: %w", err))
+			}
+		}()
 	}
-	if temps > maxTemp || temps < minTemp {
-		alertMessage += fmt.Sprintf("Alert: CPU Temperature is out of safe range: %.2f°C\n", temps)
+
+	// Identify the running build in the log, e.g. when comparing the behavior of releases
+	log.Printf("Starting %s\n", GetBuildInfo())
+
+	// Some metrics (like CPU steal) and thresholds depend on whether the machine is virtual
+	if virt, err := DetectVirtualization(); err != nil {
+		log.Printf("%v\n", err)
+	} else if virt.IsVM {
+		hypervisor := virt.HypervisorType
+		if hypervisor == "" {
+			hypervisor = "unknown"
+		}
+		log.Printf("Running virtualized (hypervisor: %s)\n", hypervisor)
 	} else {
-		fmt.Printf("CPU Temperature: %.2f°C (Safe)\n", temps)
+		log.Printf("Running on a physical machine\n")
 	}
 
-	// Monitor Fan Speeds (using external sensors command)
-	fanSpeeds, err := GetFanSpeeds()
-	if err != nil {
-		log.Fatalf("Error fetching fan speeds: %v\n", err)
+	// Let the user know about newer releases; this is a notice, not an alert.
+	// Development builds have no release version to compare against.
+	if (config.CheckUpdates == nil || *config.CheckUpdates) && Version != "dev" {
+		if latest, newer, err := CheckForUpdates(Version); err != nil {
+			log.Printf("Could not check for updates: %v\n", err)
+		} else if newer {
+			log.Printf("Notice: go-system-monitor %s is available (running %s)\n", latest, Version)
+		}
+	}
+
+	// Keep the systemd watchdog (WatchdogSec) from restarting the service and
+	// tell systemd when a graceful shutdown begins
+	if interval := watchdogInterval(config); interval > 0 {
+		StartWatchdog(ctx, interval)
+	}
+	go func() {
+		<-ctx.Done()
+		notifySystemd(daemon.SdNotifyStopping)
+	}()
+
+	// Trace the collection cycles when an OTLP endpoint is configured
+	if config.OTelEndpoint != "" {
+		shutdown, err := InitTracing(ctx, config.OTelEndpoint)
+		if err != nil {
+			log.Printf("Error setting up tracing: %v\n", err)
+		} else {
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), serviceCheckTimeout)
+				defer cancel()
+				if err := shutdown(ctx); err != nil {
+					log.Printf("Error flushing traces: %v\n", err)
+				}
+			}()
+		}
+	}
+
+	// Export the metrics to an OTLP backend
+	if config.OTLPMetrics != nil {
+		if err := StartOTLPMetricsExporter(ctx, *config.OTLPMetrics); err != nil {
+			log.Printf("Error starting OTLP metrics export: %v\n", err)
+		}
 	}
-	// Checking if fan speed data is in range
-	if strings.Contains(fanSpeeds, "fan1") {
-		alertMessage += fmt.Sprintf("Fan speed info:\n%s\n", fanSpeeds)
+
+	// Warn about unreachable alert channels; they may only be down temporarily
+	for _, err := range ValidateAlertChannels(ctx, config) {
+		log.Printf("Warning: %v\n", err)
 	}
 
-	// Monitor CPU Clock Speed (using CPU Info method)
-	clockSpeeds, err := cpu.Info()
+	// Alert emails share persistent SMTP connections
+	footer, err := NewEmailFooter(config.EmailFooter, source.String())
 	if err != nil {
-		log.Fatalf("Error fetching CPU clock speed: %v\n", err)
+		return fmt.Errorf("Error reading config: %w", err)
 	}
-	for _, cpuInfo := range clockSpeeds {
-		// Assuming the CPU has a frequency field available
-		if cpuInfo.Mhz/1000.0 < maxClockSpeed {
-			alertMessage += fmt.Sprintf("Alert: CPU Clock Speed is below 3.20 GHz: %.2f GHz\n", cpuInfo.Mhz/1000.0)
+	mailer := NewSMTPClient(config.SMTPConfig, footer)
+	defer mailer.Close()
+	channels := NewAlertChannels(config, mailer, store)
+
+	// Scanning is opt-in since it holds back the alert email until trivy is done
+	if config.EnableVulnScan {
+		OnCriticalAlert = NewVulnScanHook(config.VulnScanImages)
+	}
+
+	// Alerts of the same burst are sent together once the group window has passed
+	grouper := NewAlertGrouper(alertGroupWindow(config), func(group AlertGroup) {
+		sendAlertGroup(context.WithoutCancel(ctx), channels, store, group)
+	})
+	defer grouper.Flush()
+
+	// Outdated microcode leaves the CPU open to Spectre and MDS; it only changes with a reboot,
+	// so it is checked once at startup
+	if config.RequiredMicrocodeVersion != "" {
+		current, err := GetMicrocodeVersion()
+		if err != nil && !errors.Is(err, ErrMicrocodeNotAvailable) {
+			log.Printf("%v\n", err)
+		} else if err == nil {
+			if outdated, _ := MicrocodeOutdated(current, config.RequiredMicrocodeVersion); outdated {
+				DispatchAlerts(ctx, channels, AlertPayload{
+					Message: "System Alert: Outdated CPU Microcode",
+					Description: fmt.Sprintf("Alert: CPU microcode %s is older than the required %s (CPU: %s); update the microcode package and reboot",
+						current, config.RequiredMicrocodeVersion, getCPUModelName()),
+					Severity: defaultSeverity,
+				})
+			} else {
+				fmt.Fprintf(StatusOutput, "CPU Microcode: %s (Safe)\n", current)
+			}
+		}
+	}
+
+	var opsgenie *OpsGenieForwarder
+	if config.OpsGenie != nil {
+		opsgenie = NewOpsGenieForwarder(*config.OpsGenie)
+	}
+	var syslogRecoveries *SyslogRecoveryForwarder
+	if config.Syslog != nil {
+		syslogRecoveries = NewSyslogRecoveryForwarder(*config.Syslog)
+	}
+
+	if config.APIAddr != "" {
+		go func() {
+			if err := StartAPIServer(config, snapshot); err != nil {
+				fail(fmt.Errorf("Error starting API server: %w", err))
+			}
+		}()
+	}
+
+	// Watch the systemd journal for critical entries of the configured units
+	if len(config.JournalUnits) > 0 {
+		journalEntries, err := MonitorJournal(ctx, config.JournalUnits, int(journal.PriCrit))
+		if err != nil {
+			return fmt.Errorf("Error monitoring systemd journal: %w", err)
+		}
+		go func() {
+			for entry := range journalEntries {
+				alertMessage := FormatJournalAlert(entry)
+				DispatchAlerts(ctx, channels, AlertPayload{
+					Message:     "System Alert: Critical Journal Entry",
+					Description: alertMessage,
+					Severity:    defaultSeverity,
+				})
+			}
+		}()
+	}
+
+	// Watch the crontabs, a common place for attackers to persist, and alert on every change
+	// right away instead of waiting for the alert group window
+	if config.MonitorCrontabs {
+		paths := config.CrontabPaths
+		if len(paths) == 0 {
+			paths = defaultCrontabPaths
+		}
+		crontabChanges := make(chan CrontabChange)
+		go func() {
+			if err := MonitorCrontabChanges(paths, crontabChanges); err != nil {
+				fail(fmt.Errorf("Error monitoring crontabs: %w", err))
+			}
+		}()
+		go func() {
+			for change := range crontabChanges {
+				DispatchAlerts(ctx, channels, AlertPayload{
+					Message:     "System Alert: Crontab Changed",
+					Description: FormatCrontabAlert(change),
+					Severity:    SeverityCritical,
+				})
+			}
+		}()
+	}
+
+	// Receive SNMP traps from network devices and alert on known trap OIDs
+	if config.SNMPTraps != nil {
+		trapConfig := *config.SNMPTraps
+		traps := make(chan SNMPTrap)
+		go func() {
+			if err := StartSNMPTrapReceiver(trapConfig.ListenAddr, trapConfig.Community, traps); err != nil {
+				fail(fmt.Errorf("Error starting SNMP trap receiver: %w", err))
+			}
+		}()
+		go func() {
+			for trap := range traps {
+				if !trapConfig.IsAlertOID(trap.OID) {
+					fmt.Fprintf(StatusOutput, "SNMP trap %s from %s (ignored)\n", trap.OID, trap.Source)
+					continue
+				}
+				alertMessage := FormatSNMPTrapAlert(trap)
+				DispatchAlerts(ctx, channels, AlertPayload{
+					Message:     "System Alert: SNMP Trap Received",
+					Description: alertMessage,
+					Severity:    defaultSeverity,
+				})
+			}
+		}()
+	}
+
+	// Watch the Kubernetes nodes and alert when one becomes NotReady or recovers
+	if config.KubernetesNodes != nil {
+		kubeconfig := config.KubernetesNodes.Kubeconfig
+		nodeEvents := make(chan NodeEvent)
+		go func() {
+			if err := WatchKubernetesNodes(ctx, kubeconfig, nodeEvents); err != nil {
+				fail(fmt.Errorf("Error watching Kubernetes nodes: %w", err))
+			}
+		}()
+		go func() {
+			for event := range nodeEvents {
+				subject, severity := "System Alert: Kubernetes Node NotReady", SeverityCritical
+				if event.Ready {
+					subject, severity = "System Recovered: Kubernetes Node Ready", SeverityInfo
+				}
+				DispatchAlerts(ctx, channels, AlertPayload{
+					Message:     subject,
+					Description: FormatNodeAlert(event),
+					Severity:    severity,
+				})
+			}
+		}()
+	}
+
+	// Metrics collected on their own interval report concurrently
+	var outputMu sync.Mutex
+	var ready sync.Once
+	collect := func(ctx context.Context, metrics MetricSet, next time.Time) {
+		if !next.IsZero() {
+			footer.SetNextCheck(next)
+		}
+		runChecks(ctx, config, metrics, snapshot, grouper, store, opsgenie, syslogRecoveries)
+		// Tell systemd the service is up once the first metrics are in
+		ready.Do(func() { notifySystemd(daemon.SdNotifyReady) })
+		if *output != "" {
+			outputMu.Lock()
+			defer outputMu.Unlock()
+			if err := FormatSnapshot(snapshot.Get(), *output, os.Stdout); err != nil {
+				log.Printf("Error writing metric report: %v\n", err)
+			}
+		}
+	}
+
+	// Run the checks once, or every collection_interval seconds in daemon mode
+	// (with the intervals of the metrics config overriding it per metric)
+	if config.CollectionInterval > 0 {
+		// Config in etcd or Consul is reloaded when it changes. The checks, thresholds and
+		// intervals are restarted with the new config; alert channels and the API server are not.
+		updates, err := source.Watch(ctx)
+		if err != nil {
+			log.Printf("Error watching config: %v\n", err)
+		}
+		for {
+			schedulerCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				NewMetricScheduler(config, collect).Run(schedulerCtx)
+				close(done)
+			}()
+
+			var updated *Config
+			for updated == nil && ctx.Err() == nil {
+				select {
+				case <-ctx.Done():
+				case newConfig, ok := <-updates:
+					switch {
+					case !ok:
+						updates = nil
+					case newConfig.CollectionInterval <= 0:
+						log.Printf("Ignoring config change from %s: collection_interval must stay set in daemon mode\n", source)
+					default:
+						updated = &newConfig
+					}
+				}
+			}
+			cancel()
+			<-done
+			if updated == nil {
+				return fatalError()
+			}
+			config = *updated
+			log.Printf("Reloaded config from %s\n", source)
+		}
+	}
+	collect(ctx, allMetrics(), time.Time{})
+	grouper.Flush()
+
+	// Keep streaming journal, crontab, trap and node alerts until interrupted
+	if len(config.JournalUnits) > 0 || config.MonitorCrontabs || config.SNMPTraps != nil || config.KubernetesNodes != nil {
+		<-ctx.Done()
+	}
+	return fatalError()
+}
+
+// sendAlertGroup notifies all alert channels of the group and records its alerts with the delivery status
+func sendAlertGroup(ctx context.Context, channels []AlertChannel, store *MetricStore, group AlertGroup) {
+	alertMessage := FormatAlertMessage(group.Alerts)
+	if len(group.Alerts) > 1 {
+		if analysis := AnalyzeCorrelation(group.Alerts, store); analysis != "" {
+			alertMessage += "\n" + analysis
+		}
+	}
+
+	// Let the critical alert hook add context, like the vulnerabilities of the affected container image
+	if OnCriticalAlert != nil {
+		added := make(map[string]bool)
+		for _, alert := range group.Alerts {
+			if alertSeverity(alert) != SeverityCritical {
+				continue
+			}
+			note, err := OnCriticalAlert(ctx, alert)
+			if err != nil {
+				log.Printf("%v\n", err)
+				continue
+			}
+			if note != "" && !added[note] {
+				added[note] = true
+				alertMessage += "\n" + note
+			}
+		}
+	}
+	status := AlertStatusSent
+	errs := DispatchAlerts(ctx, channels, AlertPayload{
+		Message:     group.Subject(),
+		Description: alertMessage,
+		Severity:    highestSeverity(group.Alerts),
+		Alerts:      group.Alerts,
+	})
+	if dispatchFailed(errs, "email") {
+		status = AlertStatusFailed
+	}
+
+	if store != nil {
+		for _, alert := range group.Alerts {
+			if err := store.RecordAlert(alert.Timestamp, alert, status); err != nil {
+				log.Printf("Error recording alert history: %v\n", err)
+			}
+		}
+	}
+}
+
+// CheckAll collects every metric once and returns the snapshot together with the errors of the
+// collections that failed. Metrics that are not configured or not available on the system are
+// left empty. Unlike the monitor it has no side effects: it sends no alerts, exports nothing,
+// restarts no processes and leaves the failure counts of stale metric alerts alone.
+func CheckAll(ctx context.Context, cfg Config) (MetricSnapshot, []error) {
+	snap, _, _, errs := checkMetrics(ctx, cfg, allMetrics(), &SafeSnapshot{})
+	return snap, errs
+}
+
+// runChecks collects the given metrics once, publishes them to snapshot and sends an alert email if any threshold is exceeded
+func runChecks(ctx context.Context, config Config, metrics MetricSet, snapshot *SafeSnapshot, grouper *AlertGrouper, store *MetricStore, opsgenie *OpsGenieForwarder, syslogRecoveries *SyslogRecoveryForwarder) {
+	snap, alerts, outcomes, _ := checkMetrics(ctx, config, metrics, snapshot)
+
+	// Don't publish a partial snapshot or send alerts when interrupted by a shutdown
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Collection errors are logged, and alerted on once a metric keeps failing
+	alerts = append(alerts, recordCollections(config, outcomes)...)
+
+	// Restart the required processes that are not running
+	if metrics.Has("process") && config.AutoRestart {
+		restartProcesses(ctx, config.ProcessChecks, snap.Processes)
+	}
+
+	snapshot.Merge(snap, metrics)
+	points := metrics.Points(SnapshotMetricPoints(snap))
+
+	// Keep the metric values for the correlation analysis
+	if store != nil {
+		if err := store.RecordMetrics(snap.Timestamp, points); err != nil {
+			log.Printf("Error recording metric history: %v\n", err)
+		}
+	}
+
+	// Keep the values for the next OTLP export
+	if config.OTLPMetrics != nil {
+		RecordOTLPPoints(points)
+	}
+
+	// Export the metrics to Datadog
+	if config.Datadog != nil {
+		hostname, _ := os.Hostname()
+		if err := SendToDatadog(*config.Datadog, DatadogSeries(snap, metrics, hostname, config.Labels)); err != nil {
+			log.Printf("Error sending metrics to Datadog: %v\n", err)
+		}
+	}
+
+	// Export the metrics to Google Cloud Monitoring
+	if config.GCP != nil {
+		if err := WriteToCloudMonitoring(ctx, *config.GCP, points); err != nil {
+			log.Printf("Error writing metrics to Cloud Monitoring: %v\n", err)
+		}
+	}
+
+	// Export the metrics to Azure Monitor
+	if config.Azure != nil {
+		if err := WriteToAzureMonitor(ctx, *config.Azure, points); err != nil {
+			log.Printf("Error writing metrics to Azure Monitor: %v\n", err)
+		}
+	}
+
+	// Export the metrics to AWS CloudWatch
+	if config.CloudWatch != nil {
+		if err := WriteToCloudWatch(ctx, *config.CloudWatch, points); err != nil {
+			log.Printf("Error writing metrics to CloudWatch: %v\n", err)
+		}
+	}
+
+	// Merge alerts of metrics that breached together into a single alert
+	alerts = DetectCorrelation(alerts, config.CorrelationGroups)
+
+	// Format the alerts with the alert_template and queue them to be sent with the others of the same
+	// burst; urgent ones, like uncorrectable memory errors, are sent right away
+	for i := range alerts {
+		alerts[i].Timestamp = snap.Timestamp
+		alerts[i].Severity = GetEffectiveSeverity(alerts[i], config.TimeBasedPriority, snap.Timestamp)
+		alerts[i].Unit = alertUnit(alerts[i].Metric, config.TemperatureUnit)
+		if config.AlertTemplate == "" {
+			continue
+		}
+		message, err := RenderAlertMessage(config.AlertTemplate, alerts[i])
+		if err != nil {
+			log.Printf("%v\n", err)
+			continue
+		}
+		alerts[i].Message = message
+	}
+	var grouped, immediate []AlertEntry
+	for _, alert := range alerts {
+		if alert.Immediate {
+			immediate = append(immediate, alert)
 		} else {
-			fmt.Printf("CPU Clock Speed: %.2f GHz (Safe)\n", cpuInfo.Mhz/1000.0)
+			grouped = append(grouped, alert)
 		}
 	}
+	grouper.Send(immediate)
+	grouper.Add(grouped)
 
-	// Monitor CPU Usage
-	cpuUsage, err := cpu.Percent(0, true)
-	if err != nil {
-		log.Fatalf("Error fetching CPU usage: %v\n", err)
+	// Open OpsGenie alerts for new breaches and close those that recovered
+	if opsgenie != nil {
+		if err := opsgenie.Forward(alerts, metrics); err != nil {
+			log.Printf("Error forwarding alerts to OpsGenie: %v\n", err)
+		}
+	}
+
+	// Tell syslog about the metrics that recovered; the alerts themselves go through the syslog channel
+	if syslogRecoveries != nil {
+		if err := syslogRecoveries.Forward(alerts, metrics); err != nil {
+			log.Printf("Error forwarding recoveries to syslog: %v\n", err)
+		}
+	}
+
+	// Resolve the recorded alerts that cleared since the last check
+	if store != nil {
+		if err := store.ResolveAlerts(snap.Timestamp, alerts, metrics); err != nil {
+			log.Printf("Error recording alert history: %v\n", err)
+		}
+	}
+}
+
+// checkMetrics collects the given metrics once and checks them against the thresholds. previous
+// holds the last snapshot, which rates like the correctable ECC error rate are measured against.
+// It has no side effects: the outcomes of the collections tracked for staleness are returned for
+// the caller to record.
+func checkMetrics(ctx context.Context, config Config, metrics MetricSet, previous *SafeSnapshot) (MetricSnapshot, []AlertEntry, []collectionOutcome, []error) {
+	snap, alerts, outcomes, errs := collectMetrics(ctx, config, metrics, previous)
+	alerts = append(alerts, evaluateMetrics(ctx, config, metrics, snap, previous.Get(), outcomes)...)
+	return snap, alerts, outcomes, errs
+}
+
+// collectMetrics collects the given metrics once into a snapshot. Besides the snapshot it returns
+// the alerts of the services that could not be checked, the outcomes of the collections tracked
+// for staleness and the errors of every failed collection.
+func collectMetrics(ctx context.Context, config Config, metrics MetricSet, previous *SafeSnapshot) (MetricSnapshot, []AlertEntry, []collectionOutcome, []error) {
+	ctx, span := startCollectAllSpan(ctx, metrics)
+	defer span.End()
+
+	var errs []error
+	var outcomes []collectionOutcome
+	failed := func(metric string, err error) {
+		errs = append(errs, fmt.Errorf("%s: %w", metric, err))
+		outcomes = append(outcomes, collectionOutcome{Metric: metric, Err: err})
 	}
-	for i, usage := range cpuUsage {
-		if usage > cpuUsageThreshold {
-			alertMessage += fmt.Sprintf("Alert: CPU Core %d usage is above 80%%: %.2f%%\n", i, usage)
+	succeeded := func(metric string) {
+		outcomes = append(outcomes, collectionOutcome{Metric: metric})
+	}
+
+	var alerts []AlertEntry
+	snap := MetricSnapshot{Timestamp: time.Now(), Thresholds: config.EffectiveThresholds(), TemperatureUnit: config.TemperatureUnit, SocketMaxTempC: config.SocketMaxTempC}
+
+	// The per-socket CPU temperatures and thermal_sensors share one run of 'sensors -j'
+	var sensorTemps map[string]float64
+	var sensorErr error
+	sensorsRead := false
+	readThermalSensors := func(ctx context.Context) (map[string]float64, error) {
+		if !sensorsRead {
+			sensorTemps, sensorErr = GetAllThermalSensors(ctx)
+			sensorsRead = true
+		}
+		return sensorTemps, sensorErr
+	}
+
+	// Monitor CPU Temperature (using sensors command for Linux)
+	if metrics.Has("cpu_temperature") {
+		ctx, span := startCollectSpan(ctx, "cpu_temperature")
+		temps, err := GetCPUTemperature(ctx)
+		if err != nil {
+			failed("cpu_temperature", err)
 		} else {
-			fmt.Printf("CPU Core %d usage: %.2f%% (Safe)\n", i, usage)
+			succeeded("cpu_temperature")
+			snap.CPUTemperature = temps
+		}
+
+		// Monitor Ambient Temperature (using a TEMPer USB thermometer)
+		if config.USBTempSensor {
+			ambient, err := GetUSBTemperatureSensor(ctx)
+			if err != nil {
+				failed("ambient_temperature", err)
+			} else {
+				succeeded("ambient_temperature")
+				snap.AmbientTemperature = &ambient
+			}
+		}
+
+		// On multi-socket servers a single hot socket hides behind the temperature of the others
+		if config.SocketMaxTempC > 0 {
+			temps, err := readThermalSensors(ctx)
+			if err != nil && !errors.Is(err, ErrThermalSensorsNotAvailable) {
+				failed("cpu_temperature.sockets", err)
+			} else if err == nil {
+				succeeded("cpu_temperature.sockets")
+			}
+			if sockets := groupTemperaturesBySocket(temps); len(sockets) > 1 {
+				snap.SocketTemperatures = sockets
+			}
 		}
+		span.End()
+	}
+
+	// Monitor GPU Temperature (macOS, using powermetrics)
+	if metrics.Has("gpu_temperature") && config.GPUTemp {
+		ctx, span := startCollectSpan(ctx, "gpu_temperature")
+		gpuTemp, err := GetMacGPUTemperature(ctx)
+		if err != nil {
+			failed("gpu_temperature", err)
+		} else {
+			succeeded("gpu_temperature")
+			snap.GPUTemperature = &gpuTemp
+		}
+		span.End()
+	}
+
+	// Monitor 1-Wire Temperature Sensors (DS18B20 on a Raspberry Pi GPIO)
+	if metrics.Has("gpio_temperature") {
+		_, span := startCollectSpan(ctx, "gpio_temperature")
+		sensors := config.GPIOTempSensors
+		if len(sensors) == 0 {
+			discovered, err := DiscoverDS18B20Sensors()
+			if err != nil {
+				failed("gpio_temperature", err)
+			}
+			sensors = discovered
+		}
+		for _, sensor := range sensors {
+			name := sensor.Name
+			if name == "" {
+				name = sensor.ID
+			}
+			celsius, err := GetDS18B20Temperature(sensor.ID)
+			if err != nil {
+				failed("gpio_temperature."+name, err)
+				continue
+			}
+			succeeded("gpio_temperature." + name)
+			snap.GPIOTemperatures = append(snap.GPIOTemperatures, GPIOTempStat{ID: sensor.ID, Name: name, Celsius: celsius, MaxTempC: sensor.MaxTempC})
+		}
+		span.End()
+	}
+
+	// Monitor All lm-sensors Temperatures (die, cache, chipset, ...) Against Their Patterns
+	if metrics.Has("thermal_sensors") && len(config.ThermalSensors) > 0 {
+		ctx, span := startCollectSpan(ctx, "thermal_sensors")
+		temps, err := readThermalSensors(ctx)
+		if err != nil && !errors.Is(err, ErrThermalSensorsNotAvailable) {
+			failed("thermal_sensors", err)
+		} else if err == nil {
+			succeeded("thermal_sensors")
+		}
+		for _, name := range sortedSensorNames(temps) {
+			if maxTempC, ok := thermalSensorThreshold(config.ThermalSensors, name); ok {
+				snap.ThermalSensors = append(snap.ThermalSensors, ThermalSensorStat{Name: name, Celsius: temps[name], MaxTempC: maxTempC})
+			}
+		}
+		span.End()
+	}
+
+	// Monitor Fan Speeds (using external sensors command)
+	if metrics.Has("fan_speed") {
+		ctx, span := startCollectSpan(ctx, "fan_speed")
+		fanSpeeds, err := GetFanSpeeds(ctx)
+		if err != nil {
+			failed("fan_speed", err)
+		} else {
+			succeeded("fan_speed")
+			snap.FanSpeeds = fanSpeeds
+		}
+		span.End()
+	}
+
+	// Monitor CPU Clock Speed (current frequency from cpufreq, falling back to CPU Info)
+	if metrics.Has("cpu_clock") {
+		ctx, span := startCollectSpan(ctx, "cpu_clock")
+		clockSpeeds, err := GetCurrentCPUFrequency(ctx)
+		if err != nil {
+			failed("cpu_clock", err)
+		} else {
+			succeeded("cpu_clock")
+		}
+		snap.CPUClockSpeeds = clockSpeeds
+		span.End()
+	}
+
+	// Monitor CPU Usage
+	if metrics.Has("cpu_usage") {
+		ctx, span := startCollectSpan(ctx, "cpu_usage")
+		cpuUsage, err := GetCPUUsage(ctx)
+		if err != nil {
+			failed("cpu_usage", err)
+		} else {
+			succeeded("cpu_usage")
+			snap.CPUUsage = cpuUsage
+		}
+		span.End()
+	}
+
+	// Monitor CPU Power Consumption (RAPL, Linux only)
+	if metrics.Has("cpu_power") {
+		ctx, span := startCollectSpan(ctx, "cpu_power")
+		powerDomains, err := GetRAPLPower(ctx)
+		if err != nil && !errors.Is(err, ErrRAPLNotAvailable) {
+			failed("cpu_power", err)
+		} else if err == nil {
+			succeeded("cpu_power")
+		}
+		snap.CPUPower = powerDomains
+		span.End()
+	}
+
+	// Monitor CPU Thermal Throttling (Linux only)
+	if metrics.Has("cpu_throttle") {
+		ctx, span := startCollectSpan(ctx, "cpu_throttle")
+		throttleStats, err := GetThrottleEvents(ctx)
+		if err != nil && !errors.Is(err, ErrThrottleNotAvailable) {
+			failed("cpu_throttle", err)
+		} else if err == nil {
+			succeeded("cpu_throttle")
+		}
+		snap.CPUThrottle = throttleStats
+		span.End()
+	}
+
+	// Monitor ECC Memory Errors (Linux only)
+	if metrics.Has("edac") {
+		_, span := startCollectSpan(ctx, "edac")
+		edacStats, err := GetEDACStats()
+		if err != nil && !errors.Is(err, ErrEDACNotAvailable) {
+			failed("edac", err)
+		}
+		if err == nil {
+			succeeded("edac")
+			snap.EDAC = &edacStats
+		}
+		span.End()
+	}
+
+	// Monitor the BMC Hardware Sensors over IPMI
+	if metrics.Has("ipmi") && config.IPMISensors {
+		ctx, span := startCollectSpan(ctx, "ipmi")
+		sensors, err := GetIPMISensors(ctx, config.IPMIConfig)
+		if err != nil && !errors.Is(err, ErrIPMINotAvailable) {
+			failed("ipmi", err)
+		} else if err == nil {
+			succeeded("ipmi")
+		}
+		snap.IPMI = sensors
+		span.End()
 	}
 
 	// Monitor Memory Usage
-	memStats, err := mem.VirtualMemory()
-	if err != nil {
-		log.Fatalf("Error fetching memory stats: %v\n", err)
+	if metrics.Has("memory") {
+		ctx, span := startCollectSpan(ctx, "memory")
+		memStats, err := GetMemoryStats(ctx)
+		if err != nil {
+			failed("memory", err)
+		} else {
+			succeeded("memory")
+			snap.MemoryUsedPercent = memStats.UsedPercent
+		}
+
+		// Kernel Same-page Merging, e.g. on KVM hosts that rely on it to overcommit memory
+		if config.KSMMonitoring {
+			ksm, err := GetKSMStats()
+			if err != nil && !errors.Is(err, ErrKSMNotAvailable) {
+				failed("ksm_saved", err)
+			}
+			if err == nil {
+				succeeded("ksm_saved")
+				snap.KSM = &ksm
+			}
+		}
+		span.End()
 	}
-	if memStats.UsedPercent > memUsageThreshold {
-		alertMessage += fmt.Sprintf("Alert: Memory usage is above 80%%: %.2f%%\n", memStats.UsedPercent)
-	} else {
-		fmt.Printf("Memory usage: %.2f%% (Safe)\n", memStats.UsedPercent)
+
+	// Monitor Memory Bandwidth (Linux only, using perf uncore_imc events)
+	if metrics.Has("memory_bandwidth") && config.MemBandwidth {
+		ctx, span := startCollectSpan(ctx, "memory_bandwidth")
+		bandwidth, err := GetMemoryBandwidth(ctx, memBandwidthSampleMs)
+		if err != nil && !errors.Is(err, ErrMemBandwidthNotAvailable) {
+			failed("memory_bandwidth", err)
+		}
+		if err == nil {
+			succeeded("memory_bandwidth")
+			snap.MemBandwidth = &bandwidth
+		}
+		span.End()
+	}
+
+	// Monitor the Huge Page Pool
+	if metrics.Has("hugepages") {
+		_, span := startCollectSpan(ctx, "hugepages")
+		hugePages, err := GetHugePagesStats()
+		if err != nil && !errors.Is(err, ErrHugePagesNotAvailable) {
+			failed("hugepages", err)
+		}
+		if err == nil {
+			succeeded("hugepages")
+			snap.HugePages = &hugePages
+		}
+		span.End()
 	}
 
 	// Monitor Disk Usage
-	diskStats, err := disk.Usage("/")
-	if err != nil {
-		log.Fatalf("Error fetching disk usage: %v\n", err)
+	if metrics.Has("disk") {
+		ctx, span := startCollectSpan(ctx, "disk")
+		diskStats, err := GetDiskUsage(ctx, "/")
+		if err != nil {
+			failed("disk", err)
+		} else {
+			succeeded("disk")
+			snap.DiskUsedPercent = diskStats.UsedPercent
+		}
+		span.End()
 	}
-	if diskStats.UsedPercent > diskUsageThreshold {
-		alertMessage += fmt.Sprintf("Alert: Disk usage is above 50%%: %.2f%%\n", diskStats.UsedPercent)
-	} else {
-		fmt.Printf("Disk usage: %.2f%% (Safe)\n", diskStats.UsedPercent)
+
+	// Monitor Disk Latency (Linux only)
+	if metrics.Has("disk_latency") {
+		ctx, span := startCollectSpan(ctx, "disk_latency")
+		latencyStats, err := GetDiskLatencies(ctx)
+		if err != nil && !errors.Is(err, ErrDiskLatencyNotAvailable) {
+			failed("disk_latency", err)
+		} else if err == nil {
+			succeeded("disk_latency")
+		}
+		snap.DiskLatency = latencyStats
+		span.End()
 	}
 
-	// Send an email if any alert message exists
-	if alertMessage != "" {
-		sendEmail(config, "System Alert: Resource Usage Exceeded", alertMessage)
+	// Monitor tmpfs Usage (Linux only, e.g. /tmp, /run and /dev/shm)
+	if metrics.Has("tmpfs") {
+		_, span := startCollectSpan(ctx, "tmpfs")
+		tmpfsStats, err := GetTmpfsUsage()
+		if err != nil && !errors.Is(err, ErrTmpfsNotAvailable) {
+			failed("tmpfs", err)
+		} else if err == nil {
+			succeeded("tmpfs")
+		}
+		snap.Tmpfs = tmpfsStats
+		span.End()
 	}
-}
 
-// GetCPUTemperature uses the 'sensors' command for Linux to fetch CPU temperature
-//func GetCPUTemperature() (float64, error) {
-//	// Run the 'sensors' command
-//	cmd := exec.Command("osx-cpu-temp") //for mac  brew install osx-cpu-temp
-//
-//	//for linux lm-sensors
-//	//for windows wmic
-//	output, err := cmd.Output()
-//	if err != nil {
-//		return 0, fmt.Errorf("Error fetching CPU temperature: %w", err)
-//	}
-//
-//	// Parse the output to find the temperature
-//	for _, line := range strings.Split(string(output), "\n") {
-//		if strings.Contains(line, "Core 0") {
-//			// Example: Core 0:      +45.0°C  (high = +80.0°C, crit = +100.0°C)
-//			parts := strings.Fields(line)
-//			if len(parts) > 1 {
-//				// Convert temperature to float64
-//				var temp float64
-//				_, err := fmt.Sscanf(parts[1], "%f", &temp)
-//				if err == nil {
-//					return temp, nil
-//				}
-//			}
-//		}
-//	}
-//
-//	return 0, fmt.Errorf("could not find CPU temperature")
-//}
-
-// GetCPUTemperature uses the 'osx-cpu-temp' command for macOS to fetch CPU temperature
-func GetCPUTemperature() (float64, error) {
-	// Run the 'osx-cpu-temp' command
-	cmd := exec.Command("osx-cpu-temp")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, fmt.Errorf("Error fetching CPU temperature: %w", err)
+	// Monitor File and Directory Sizes
+	if metrics.Has("file_size") {
+		_, span := startCollectSpan(ctx, "file_size")
+		for _, check := range config.FileSizeChecks {
+			stat, err := CheckPathSize(check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("file_size %s: %w", check.Path, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "file_size",
+					Message: fmt.Sprintf("Alert: Could not measure the size of %s: %v", check.Path, err),
+				})
+				continue
+			}
+			snap.PathSizes = append(snap.PathSizes, stat)
+		}
+		span.End()
 	}
 
-	// Print the raw output for debugging
-	fmt.Printf("Raw output: %s\n", string(output))
+	// Monitor the Disk Quotas of Users and Groups
+	if metrics.Has("disk_quota") && config.DiskQuotas {
+		ctx, span := startCollectSpan(ctx, "disk_quota")
+		quotas, err := GetDiskQuotas(ctx, "")
+		if err != nil && !errors.Is(err, ErrDiskQuotaNotAvailable) {
+			failed("disk_quota", err)
+		} else if err == nil {
+			succeeded("disk_quota")
+		}
+		for _, quota := range quotas {
+			if !slices.Contains(config.ExcludeUsers, quota.Name) {
+				snap.DiskQuotas = append(snap.DiskQuotas, quota)
+			}
+		}
+		span.End()
+	}
 
-	// Proceed with parsing the output
-	var temp float64
-	_, err = fmt.Sscanf(string(output), "+%f°C", &temp)
-	if err != nil {
-		return 0, fmt.Errorf("Error parsing CPU temperature: %w", err)
+	// Monitor the SELinux and AppArmor Enforcement (Linux)
+	if metrics.Has("mac") && (config.RequireSELinuxEnforcing || config.RequireAppArmorEnabled) {
+		_, span := startCollectSpan(ctx, "mac")
+		macStatus, err := GetMACStatus()
+		if err != nil && !errors.Is(err, ErrMACNotAvailable) {
+			failed("mac", err)
+		} else if err == nil {
+			succeeded("mac")
+			snap.MAC = &macStatus
+		}
+		span.End()
+	}
+
+	// Monitor DNS Resolution
+	if metrics.Has("dns") {
+		ctx, span := startCollectSpan(ctx, "dns")
+		for _, check := range config.DNSChecks {
+			stat, err := CheckDNSResolution(ctx, check.Hostname, check.Server, dnsTimeout)
+			snap.DNS = append(snap.DNS, stat)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("dns %s: %w", check.Hostname, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "dns",
+					Message: fmt.Sprintf("Alert: DNS resolution of %s via %s failed: %v", check.Hostname, dnsServerName(stat.Server), err),
+				})
+			}
+		}
+		span.End()
+	}
+
+	// Monitor the Route to Critical Hosts
+	if metrics.Has("traceroute") {
+		ctx, span := startCollectSpan(ctx, "traceroute")
+		for _, check := range config.TracerouteChecks {
+			stat, err := CheckTraceroute(ctx, check)
+			if err != nil {
+				stat.Error = err.Error()
+				errs = append(errs, fmt.Errorf("traceroute %s: %w", check.Host, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "traceroute",
+					Message: fmt.Sprintf("Alert: Traceroute to %s failed: %v", check.Host, err),
+				})
+			}
+			snap.Traceroute = append(snap.Traceroute, stat)
+		}
+		span.End()
+	}
+
+	// Monitor the Packets Dropped by the Network Interfaces (Linux only), e.g. by full ring buffers
+	if metrics.Has("nic") {
+		ctx, span := startCollectSpan(ctx, "nic")
+		nicStats, err := GetNICPacketStats(ctx)
+		if err != nil && !errors.Is(err, ErrNICStatsNotAvailable) {
+			failed("nic", err)
+		} else if err == nil {
+			succeeded("nic")
+		}
+		snap.NICDrops = nicStats
+		span.End()
+	}
+
+	// Monitor the Packets Dropped by Cilium Network Policies (Kubernetes nodes)
+	if metrics.Has("cilium") && config.CiliumDrops {
+		_, span := startCollectSpan(ctx, "cilium")
+		dropStats, err := GetCiliumDropStats()
+		if err != nil {
+			failed("cilium", err)
+		} else {
+			succeeded("cilium")
+			snap.CiliumDrops = &dropStats
+		}
+		span.End()
+	}
+
+	// Monitor the Certificate Transparency Logs for Certificates Issued Outside the Expected Window
+	if metrics.Has("ct") {
+		ctx, span := startCollectSpan(ctx, "ct")
+		prev := previous.Get()
+		for _, check := range config.CTChecks {
+			info, err := CheckCertTransparency(ctx, check.Domain)
+			if err != nil {
+				failed("ct."+check.Domain, err)
+				// Keep the last successful check, so the next one only alerts on certificates logged since
+				if prevInfo := findCTInfo(prev.CT, check.Domain); prevInfo != nil {
+					snap.CT = append(snap.CT, *prevInfo)
+				}
+				continue
+			}
+			succeeded("ct." + check.Domain)
+			snap.CT = append(snap.CT, info)
+		}
+		span.End()
+	}
+
+	// Monitor Cron Job Heartbeats
+	if metrics.Has("cron") {
+		_, span := startCollectSpan(ctx, "cron")
+		for _, check := range config.CronChecks {
+			stat, err := CheckCronHeartbeat(check)
+			if err != nil {
+				failed("cron."+check.Name, err)
+				continue
+			}
+			succeeded("cron." + check.Name)
+			snap.Cron = append(snap.Cron, stat)
+		}
+		span.End()
+	}
+
+	// Monitor Elasticsearch Cluster Health
+	if metrics.Has("elasticsearch") {
+		ctx, span := startCollectSpan(ctx, "elasticsearch")
+		for _, cluster := range config.ElasticsearchClusters {
+			health, err := GetElasticsearchHealth(ctx, cluster)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("elasticsearch %s: %w", cluster.URL, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "elasticsearch",
+					Message: fmt.Sprintf("Alert: Elasticsearch cluster %s is unreachable: %v", cluster.URL, err),
+				})
+				continue
+			}
+			snap.Elasticsearch = append(snap.Elasticsearch, health)
+		}
+		span.End()
+	}
+
+	// Monitor PostgreSQL Connections and Replication Lag
+	if metrics.Has("postgres") {
+		ctx, span := startCollectSpan(ctx, "postgres")
+		for _, check := range config.PostgresChecks {
+			stat, err := CheckPostgresHealth(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("postgres %s: %w", check.Name, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "postgres",
+					Message: fmt.Sprintf("Alert: PostgreSQL %s is unreachable: %v", check.Name, err),
+				})
+				continue
+			}
+			snap.Postgres = append(snap.Postgres, stat)
+		}
+		span.End()
+	}
+
+	// Monitor Redis Memory and Clients
+	if metrics.Has("redis") {
+		ctx, span := startCollectSpan(ctx, "redis")
+		for _, check := range config.RedisChecks {
+			stat, err := CheckRedisHealth(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("redis %s: %w", check.Addr, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "redis",
+					Message: fmt.Sprintf("Alert: Redis %s is unreachable: %v", check.Addr, err),
+				})
+				continue
+			}
+			snap.Redis = append(snap.Redis, stat)
+		}
+
+		// Sentinel-managed masters
+		for _, check := range config.RedisSentinelChecks {
+			stat, err := CheckSentinelHealth(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("redis sentinel %s: %w", check.MasterName, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "redis",
+					Message: fmt.Sprintf("Alert: Redis Sentinel for master %s is unreachable: %v", check.MasterName, err),
+				})
+				continue
+			}
+			snap.RedisSentinels = append(snap.RedisSentinels, stat)
+		}
+		span.End()
+	}
+
+	// Monitor MongoDB Connections and Replica Set Health
+	if metrics.Has("mongo") {
+		ctx, span := startCollectSpan(ctx, "mongo")
+		for _, check := range config.MongoChecks {
+			stat, err := CheckMongoHealth(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("mongo %s: %w", check.Name, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "mongo",
+					Message: fmt.Sprintf("Alert: MongoDB %s is unreachable: %v", check.Name, err),
+				})
+				continue
+			}
+			snap.Mongo = append(snap.Mongo, stat)
+		}
+		span.End()
+	}
+
+	// Monitor RabbitMQ Queue Depth and Consumers
+	if metrics.Has("rabbitmq") {
+		ctx, span := startCollectSpan(ctx, "rabbitmq")
+		for _, check := range config.RabbitMQChecks {
+			queues, err := CheckRabbitMQQueues(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("rabbitmq %s: %w", check.ManagementURL, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "rabbitmq",
+					Message: fmt.Sprintf("Alert: RabbitMQ %s is unreachable: %v", check.ManagementURL, err),
+				})
+				continue
+			}
+			snap.RabbitMQ = append(snap.RabbitMQ, queues...)
+		}
+		span.End()
+	}
+
+	// Monitor Kafka Consumer Group Lag
+	if metrics.Has("kafka") {
+		ctx, span := startCollectSpan(ctx, "kafka")
+		for _, check := range config.KafkaChecks {
+			lags, err := CheckKafkaLag(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("kafka %s: %w", check.ConsumerGroup, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "kafka",
+					Message: fmt.Sprintf("Alert: Kafka consumer group %s could not be checked: %v", check.ConsumerGroup, err),
+				})
+				continue
+			}
+			snap.Kafka = append(snap.Kafka, lags...)
+		}
+		span.End()
+	}
+
+	// Monitor MySQL Connections and Replication
+	if metrics.Has("mysql") {
+		ctx, span := startCollectSpan(ctx, "mysql")
+		for _, check := range config.MySQLChecks {
+			stat, err := CheckMySQLHealth(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("mysql %s: %w", check.Name, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "mysql",
+					Message: fmt.Sprintf("Alert: MySQL %s is unreachable: %v", check.Name, err),
+				})
+				continue
+			}
+			snap.MySQL = append(snap.MySQL, stat)
+		}
+		span.End()
+	}
+
+	// Monitor AWS RDS Instances Through CloudWatch
+	if metrics.Has("rds") {
+		ctx, span := startCollectSpan(ctx, "rds")
+		for _, check := range config.RDSChecks {
+			stat, err := CheckRDSMetrics(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("rds %s: %w", check.DBInstanceIdentifier, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "rds",
+					Message: fmt.Sprintf("Alert: Could not fetch the metrics of RDS instance %s: %v", check.DBInstanceIdentifier, err),
+				})
+				continue
+			}
+			snap.RDS = append(snap.RDS, stat)
+		}
+		span.End()
+	}
+
+	// Monitor Consul Service Health
+	if metrics.Has("consul") {
+		ctx, span := startCollectSpan(ctx, "consul")
+		for _, check := range config.ConsulChecks {
+			services, err := CheckConsulHealth(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("consul %s: %w", check.Address, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "consul",
+					Message: fmt.Sprintf("Alert: Consul %s is unreachable: %v", check.Address, err),
+				})
+				continue
+			}
+			snap.Consul = append(snap.Consul, services...)
+		}
+		span.End()
 	}
 
-	return temp, nil
+	// Monitor etcd Cluster Health
+	if metrics.Has("etcd") {
+		ctx, span := startCollectSpan(ctx, "etcd")
+		for _, check := range config.EtcdChecks {
+			stat, err := CheckEtcdHealth(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("etcd %s: %w", strings.Join(check.Endpoints, ","), err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "etcd",
+					Message: fmt.Sprintf("Alert: etcd cluster %s is unhealthy: %v", strings.Join(check.Endpoints, ","), err),
+				})
+				continue
+			}
+			snap.Etcd = append(snap.Etcd, stat)
+		}
+		span.End()
+	}
+
+	// Monitor Vault Seal Status and Token Expiry
+	if metrics.Has("vault") {
+		ctx, span := startCollectSpan(ctx, "vault")
+		for _, check := range config.VaultChecks {
+			stat, err := CheckVaultHealth(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("vault %s: %w", check.Address, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "vault",
+					Message: fmt.Sprintf("Alert: Vault %s is unreachable: %v", check.Address, err),
+				})
+				continue
+			}
+			snap.Vault = append(snap.Vault, stat)
+		}
+		span.End()
+	}
+
+	// Monitor HAProxy Frontends and Backends
+	if metrics.Has("haproxy") {
+		ctx, span := startCollectSpan(ctx, "haproxy")
+		for _, check := range config.HAProxyChecks {
+			rows, err := CheckHAProxyBackends(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("haproxy %s: %w", check.StatsURL, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "haproxy",
+					Message: fmt.Sprintf("Alert: HAProxy %s is unreachable: %v", check.StatsURL, err),
+				})
+				continue
+			}
+			snap.HAProxy = append(snap.HAProxy, rows...)
+		}
+		span.End()
+	}
+
+	// Monitor Nginx Connections
+	if metrics.Has("nginx") {
+		ctx, span := startCollectSpan(ctx, "nginx")
+		for _, check := range config.NginxChecks {
+			stat, err := CheckNginxStatus(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("nginx %s: %w", check.StubStatusURL, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "nginx",
+					Message: fmt.Sprintf("Alert: nginx %s is unreachable: %v", check.StubStatusURL, err),
+				})
+				continue
+			}
+			snap.Nginx = append(snap.Nginx, stat)
+		}
+		span.End()
+	}
+
+	// Monitor Apache Workers
+	if metrics.Has("apache") {
+		ctx, span := startCollectSpan(ctx, "apache")
+		for _, check := range config.ApacheChecks {
+			stat, err := CheckApacheStatus(ctx, check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("apache %s: %w", check.StatusURL, err))
+				alerts = append(alerts, AlertEntry{
+					Metric:  "apache",
+					Message: fmt.Sprintf("Alert: Apache %s is unreachable: %v", check.StatusURL, err),
+				})
+				continue
+			}
+			snap.Apache = append(snap.Apache, stat)
+		}
+		span.End()
+	}
+
+	// Monitor Required Processes
+	if metrics.Has("process") && len(config.ProcessChecks) > 0 {
+		ctx, span := startCollectSpan(ctx, "process")
+		results, err := CheckProcesses(ctx, config.ProcessChecks)
+		if err != nil {
+			failed("process", err)
+		} else {
+			succeeded("process")
+		}
+		snap.Processes = results
+		span.End()
+	}
+
+	// Failed collections leave zero values behind that must not be reported as readings
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			if snap.Failed == nil {
+				snap.Failed = make(map[string]bool)
+			}
+			snap.Failed[outcome.Metric] = true
+		}
+	}
 
+	return snap, alerts, outcomes, errs
 }
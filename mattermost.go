@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// mattermostErrorBodySize bounds the error response included in the returned error
+const mattermostErrorBodySize = 512
+
+// MattermostConfig holds the incoming webhook alerts are posted to. Channel, Username and
+// IconEmoji override the defaults of the webhook; Mattermost ignores Username and IconEmoji
+// unless "Enable integrations to override usernames" and "... profile picture icons" are on.
+type MattermostConfig struct {
+	WebhookURL string `json:"webhook_url"`
+	Channel    string `json:"channel"`    // channel name as in its URL, e.g. town-square
+	Username   string `json:"username"`   // e.g. system-monitor
+	IconEmoji  string `json:"icon_emoji"` // e.g. rotating_light; the Slack form :rotating_light: also works
+}
+
+// webhookPayload is the body of a Slack-compatible incoming webhook, which Mattermost accepts as is
+type webhookPayload struct {
+	Text      string `json:"text"`
+	Channel   string `json:"channel,omitempty"`
+	Username  string `json:"username,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"`
+}
+
+// SendMattermostAlert posts message, which may use Markdown, to the Mattermost incoming webhook.
+// Slack expects the icon emoji wrapped in colons, e.g. :rotating_light:, while Mattermost expects
+// the bare emoji name, so the colons of a Slack-style icon_emoji are trimmed.
+func SendMattermostAlert(cfg MattermostConfig, message string) error {
+	body, err := json.Marshal(webhookPayload{
+		Text:      message,
+		Channel:   cfg.Channel,
+		Username:  cfg.Username,
+		IconEmoji: strings.Trim(cfg.IconEmoji, ":"),
+	})
+	if err != nil {
+		return fmt.Errorf("Error encoding Mattermost message: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serviceCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Error creating Mattermost request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Error sending alert to Mattermost: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, mattermostErrorBodySize))
+		return fmt.Errorf("Mattermost returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// mattermostChannel posts the payload with the Message as bold title
+type mattermostChannel struct {
+	config MattermostConfig
+}
+
+func (c mattermostChannel) Name() string { return "Mattermost" }
+
+func (c mattermostChannel) Send(ctx context.Context, payload AlertPayload) error {
+	message := fmt.Sprintf("**%s**\n%s", payload.Message, payload.Description)
+	return SendMattermostAlert(c.config, message)
+}
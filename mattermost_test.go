@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendMattermostAlert(t *testing.T) {
+	var got webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("could not decode webhook payload: %v", err)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := MattermostConfig{
+		WebhookURL: server.URL,
+		Channel:    "ops-alerts",
+		Username:   "system-monitor",
+		IconEmoji:  ":rotating_light:",
+	}
+	if err := SendMattermostAlert(cfg, "Alert: CPU usage is 95.0%"); err != nil {
+		t.Fatalf("SendMattermostAlert: %v", err)
+	}
+
+	want := webhookPayload{
+		Text:      "Alert: CPU usage is 95.0%",
+		Channel:   "ops-alerts",
+		Username:  "system-monitor",
+		IconEmoji: "rotating_light",
+	}
+	if got != want {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+}
+
+func TestSendMattermostAlertError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unable to find the webhook.", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := SendMattermostAlert(MattermostConfig{WebhookURL: server.URL}, "Alert")
+	if err == nil {
+		t.Fatal("SendMattermostAlert succeeded on a 400 response")
+	}
+	if want := "Mattermost returned 400 Bad Request: Unable to find the webhook."; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// memBandwidthSampleMs is how long the memory controller counters are sampled for
+const memBandwidthSampleMs = 1000
+
+// ErrMemBandwidthNotAvailable is returned when perf or the uncore memory controller events are missing
+var ErrMemBandwidthNotAvailable = errors.New("memory bandwidth counters are not available on this system")
+
+// MemBandwidthStat holds the average memory bandwidth over the sample period
+type MemBandwidthStat struct {
+	ReadGBps  float64 `json:"read_gbps"`
+	WriteGBps float64 `json:"write_gbps"`
+}
+
+// TotalGBps returns the combined read and write bandwidth
+func (s MemBandwidthStat) TotalGBps() float64 {
+	return s.ReadGBps + s.WriteGBps
+}
+
+// GetMemoryBandwidth counts the reads and writes of the integrated memory controllers (uncore_imc)
+// for durationMs using 'perf stat' and returns the average bandwidth. perf needs root or a
+// kernel.perf_event_paranoid setting of 0 or lower for system-wide uncore events.
+func GetMemoryBandwidth(ctx context.Context, durationMs int) (MemBandwidthStat, error) {
+	seconds := float64(durationMs) / 1000
+	// -x, prints one CSV line per event on stderr: value,unit,event,...
+	cmd := exec.CommandContext(ctx, "perf", "stat", "-a", "-x,",
+		"-e", "uncore_imc/data_reads/,uncore_imc/data_writes/",
+		"--", "sleep", strconv.FormatFloat(seconds, 'f', 3, 64))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return MemBandwidthStat{}, ctx.Err()
+		}
+		if errors.Is(err, exec.ErrNotFound) || strings.Contains(string(output), "event syntax error") {
+			return MemBandwidthStat{}, ErrMemBandwidthNotAvailable
+		}
+		return MemBandwidthStat{}, fmt.Errorf("Error fetching memory bandwidth: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	records, err := csv.NewReader(strings.NewReader(string(output))).ReadAll()
+	if err != nil {
+		return MemBandwidthStat{}, fmt.Errorf("Error parsing perf output: %w", err)
+	}
+
+	var readBytes, writeBytes float64
+	var found bool
+	for _, record := range records {
+		if len(record) < 3 {
+			continue
+		}
+		value, unit, event := record[0], record[1], record[2]
+		if value == "<not supported>" || value == "<not counted>" {
+			return MemBandwidthStat{}, ErrMemBandwidthNotAvailable
+		}
+		count, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+
+		// perf applies the scale of the uncore events and reports MiB; raw counts are 64-byte cache lines
+		bytes := count * 64
+		if unit == "MiB" {
+			bytes = count * 1024 * 1024
+		}
+		switch {
+		case strings.Contains(event, "data_reads"):
+			readBytes += bytes
+			found = true
+		case strings.Contains(event, "data_writes"):
+			writeBytes += bytes
+			found = true
+		}
+	}
+	if !found {
+		return MemBandwidthStat{}, ErrMemBandwidthNotAvailable
+	}
+
+	return MemBandwidthStat{
+		ReadGBps:  readBytes / 1e9 / seconds,
+		WriteGBps: writeBytes / 1e9 / seconds,
+	}, nil
+}
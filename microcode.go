@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrMicrocodeNotAvailable is returned on systems whose /proc/cpuinfo has no microcode field,
+// like non-x86 CPUs, or that have no /proc/cpuinfo at all
+var ErrMicrocodeNotAvailable = errors.New("the CPU microcode version is not available on this system")
+
+// GetMicrocodeVersion returns the microcode revision of the CPUs from /proc/cpuinfo, e.g. 0xf0.
+// When the cores differ, e.g. during a late microcode load, the oldest revision is returned.
+func GetMicrocodeVersion() (string, error) {
+	info, err := readCPUInfoFields("microcode")
+	if err != nil {
+		return "", err
+	}
+	var oldest string
+	var oldestRevision uint64
+	for _, version := range info["microcode"] {
+		revision, err := parseMicrocodeVersion(version)
+		if err != nil {
+			return "", fmt.Errorf("Error parsing CPU microcode version: %w", err)
+		}
+		if oldest == "" || revision < oldestRevision {
+			oldest, oldestRevision = version, revision
+		}
+	}
+	if oldest == "" {
+		return "", ErrMicrocodeNotAvailable
+	}
+	return oldest, nil
+}
+
+// getCPUModelName returns the model name of the first CPU in /proc/cpuinfo, or "" if unknown
+func getCPUModelName() string {
+	info, err := readCPUInfoFields("model name")
+	if err != nil || len(info["model name"]) == 0 {
+		return ""
+	}
+	return info["model name"][0]
+}
+
+// readCPUInfoFields returns the values of the given /proc/cpuinfo fields, one per CPU
+func readCPUInfoFields(fields ...string) (map[string][]string, error) {
+	file, err := os.Open(procCpuinfoPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrMicrocodeNotAvailable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %s: %w", procCpuinfoPath, err)
+	}
+	defer file.Close()
+
+	values := make(map[string][]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Lines look like "microcode\t: 0xf0"
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		for _, field := range fields {
+			if key == field {
+				values[key] = append(values[key], strings.TrimSpace(value))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Error reading %s: %w", procCpuinfoPath, err)
+	}
+	return values, nil
+}
+
+// parseMicrocodeVersion parses a hex microcode revision with or without the 0x prefix
+func parseMicrocodeVersion(version string) (uint64, error) {
+	hex := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "0x")
+	revision, err := strconv.ParseUint(hex, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid microcode version %q: must be a hex number like 0xf0", version)
+	}
+	return revision, nil
+}
+
+// MicrocodeOutdated reports whether the current microcode revision is older than the required one
+func MicrocodeOutdated(current, required string) (bool, error) {
+	currentRevision, err := parseMicrocodeVersion(current)
+	if err != nil {
+		return false, err
+	}
+	requiredRevision, err := parseMicrocodeVersion(required)
+	if err != nil {
+		return false, err
+	}
+	return currentRevision < requiredRevision, nil
+}
+
+// validateMicrocodeVersion checks the required_microcode_version config
+func validateMicrocodeVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+	if _, err := parseMicrocodeVersion(version); err != nil {
+		return fmt.Errorf("invalid required_microcode_version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// MySQLCheck is a single entry of the mysql_checks config
+type MySQLCheck struct {
+	Name                   string `json:"name"` // shown in alerts instead of the DSN, which may hold a password
+	DSN                    string `json:"dsn"`  // e.g. monitor:secret@tcp(db1:3306)/
+	MaxConnections         int    `json:"max_connections"`
+	MaxSlaveLatencySeconds int    `json:"max_slave_latency_seconds"`
+}
+
+// MySQLStat holds the connection and replication state of a MySQL or MariaDB server
+type MySQLStat struct {
+	Name                string `json:"name"`
+	ThreadsConnected    int    `json:"threads_connected"`
+	IsReplica           bool   `json:"is_replica"`
+	SlaveIORunning      string `json:"slave_io_running,omitempty"`
+	SlaveSQLRunning     string `json:"slave_sql_running,omitempty"`
+	SecondsBehindMaster int64  `json:"seconds_behind_master"` // -1 when NULL, i.e. replication is stopped
+	SlaveStatus         string `json:"-"`                     // full SHOW SLAVE STATUS output, one "Field: value" per line
+}
+
+// CheckMySQLHealth counts the connected threads and, on replicas, reads the replication state
+func CheckMySQLHealth(ctx context.Context, cfg MySQLCheck) (MySQLStat, error) {
+	stat := MySQLStat{Name: cfg.Name, SecondsBehindMaster: -1}
+
+	db, err := sql.Open("mysql", cfg.DSN)
+	if err != nil {
+		return stat, fmt.Errorf("Error opening MySQL connection: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+
+	var variable string
+	err = db.QueryRowContext(ctx, `SHOW STATUS LIKE 'Threads_connected'`).Scan(&variable, &stat.ThreadsConnected)
+	if err != nil {
+		return stat, fmt.Errorf("Error querying Threads_connected: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SHOW SLAVE STATUS`)
+	if err != nil {
+		return stat, fmt.Errorf("Error querying slave status: %w", err)
+	}
+	defer rows.Close()
+
+	// The columns of SHOW SLAVE STATUS differ between MySQL and MariaDB versions, so scan them generically
+	columns, err := rows.Columns()
+	if err != nil {
+		return stat, fmt.Errorf("Error reading slave status columns: %w", err)
+	}
+	if !rows.Next() {
+		// No rows means the server is not a replica
+		return stat, rows.Err()
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return stat, fmt.Errorf("Error reading slave status: %w", err)
+	}
+
+	stat.IsReplica = true
+	var dump strings.Builder
+	for i, column := range columns {
+		fmt.Fprintf(&dump, "%s: %s\n", column, values[i].String)
+		switch column {
+		case "Slave_IO_Running":
+			stat.SlaveIORunning = values[i].String
+		case "Slave_SQL_Running":
+			stat.SlaveSQLRunning = values[i].String
+		case "Seconds_Behind_Master":
+			if values[i].Valid {
+				stat.SecondsBehindMaster, _ = strconv.ParseInt(values[i].String, 10, 64)
+			}
+		}
+	}
+	stat.SlaveStatus = dump.String()
+
+	return stat, nil
+}
@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// NginxCheck is a single entry of the nginx_checks config
+type NginxCheck struct {
+	StubStatusURL        string `json:"stub_status_url"` // e.g. http://web1/nginx_status
+	MaxActiveConnections int    `json:"max_active_connections"`
+}
+
+// NginxStat holds the counters of the ngx_http_stub_status_module page
+type NginxStat struct {
+	URL               string `json:"url"`
+	ActiveConnections int    `json:"active_connections"`
+	Accepts           int64  `json:"accepts"` // cumulative since nginx started
+	Handled           int64  `json:"handled"`
+	Requests          int64  `json:"requests"`
+	Reading           int    `json:"reading"`
+	Writing           int    `json:"writing"`
+	Waiting           int    `json:"waiting"`
+}
+
+// Dropped returns the connections that were accepted but not handled, usually because worker_connections was reached
+func (s NginxStat) Dropped() int64 {
+	return s.Accepts - s.Handled
+}
+
+// CheckNginxStatus fetches and parses the nginx stub_status page
+func CheckNginxStatus(ctx context.Context, cfg NginxCheck) (NginxStat, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.StubStatusURL, nil)
+	if err != nil {
+		return NginxStat{}, fmt.Errorf("Error creating nginx request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent())
+
+	client := &http.Client{Timeout: serviceCheckTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return NginxStat{}, fmt.Errorf("Error fetching nginx status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return NginxStat{}, fmt.Errorf("nginx returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return NginxStat{}, fmt.Errorf("Error reading nginx status: %w", err)
+	}
+
+	stat, err := parseNginxStubStatus(string(body))
+	stat.URL = cfg.StubStatusURL
+	return stat, err
+}
+
+// parseNginxStubStatus parses the stub_status page:
+//
+//	Active connections: 291
+//	server accepts handled requests
+//	 16630948 16630948 31070465
+//	Reading: 6 Writing: 179 Waiting: 106
+func parseNginxStubStatus(page string) (NginxStat, error) {
+	var stat NginxStat
+	lines := strings.Split(strings.TrimSpace(page), "\n")
+	if len(lines) < 4 {
+		return stat, fmt.Errorf("could not parse nginx status: expected 4 lines, got %d", len(lines))
+	}
+
+	if _, err := fmt.Sscanf(lines[0], "Active connections: %d", &stat.ActiveConnections); err != nil {
+		return stat, fmt.Errorf("could not parse nginx active connections: %w", err)
+	}
+	if _, err := fmt.Sscan(lines[2], &stat.Accepts, &stat.Handled, &stat.Requests); err != nil {
+		return stat, fmt.Errorf("could not parse nginx connection counters: %w", err)
+	}
+	if _, err := fmt.Sscanf(strings.TrimSpace(lines[3]), "Reading: %d Writing: %d Waiting: %d", &stat.Reading, &stat.Writing, &stat.Waiting); err != nil {
+		return stat, fmt.Errorf("could not parse nginx connection states: %w", err)
+	}
+	return stat, nil
+}
@@ -0,0 +1,182 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nicSampleInterval is the time between the two readings of the interface counters
+const nicSampleInterval = 1 * time.Second
+
+// procNetDevPath holds the per-interface packet counters
+var procNetDevPath = "/proc/net/dev"
+
+// ErrNICStatsNotAvailable is returned on systems without /proc/net/dev
+var ErrNICStatsNotAvailable = errors.New("network interface counters are not available on this system")
+
+// NICPacketStat holds the packets a network interface dropped over the sample interval
+type NICPacketStat struct {
+	Interface       string  `json:"interface"`
+	RxDroppedPerSec float64 `json:"rx_dropped_per_sec"`
+	TxDroppedPerSec float64 `json:"tx_dropped_per_sec"`
+}
+
+// nicCounters are the cumulative drop counters of an interface in /proc/net/dev
+type nicCounters struct {
+	rxDropped uint64
+	txDropped uint64
+}
+
+// readNICCounters reads the drop counters of every interface except the loopback
+func readNICCounters() (map[string]nicCounters, error) {
+	file, err := os.Open(procNetDevPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNICStatsNotAvailable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %s: %w", procNetDevPath, err)
+	}
+	defer file.Close()
+	return parseNetDev(file)
+}
+
+// parseNetDev parses /proc/net/dev. After two header lines, each line holds an interface and its
+// receive counters (bytes, packets, errs, drop, fifo, frame, compressed, multicast) followed by
+// its transmit counters (bytes, packets, errs, drop, fifo, colls, carrier, compressed).
+func parseNetDev(r io.Reader) (map[string]nicCounters, error) {
+	counters := make(map[string]nicCounters)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		if name == "lo" {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 16 {
+			return nil, fmt.Errorf("could not parse %s counters: expected 16 fields, got %d", name, len(fields))
+		}
+		rxDropped, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s counters: %w", name, err)
+		}
+		txDropped, err := strconv.ParseUint(fields[11], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s counters: %w", name, err)
+		}
+		counters[name] = nicCounters{rxDropped: rxDropped, txDropped: txDropped}
+	}
+	return counters, scanner.Err()
+}
+
+// GetNICPacketStats reads /proc/net/dev twice, nicSampleInterval apart, and returns the drop rate
+// of every interface, sorted by name
+func GetNICPacketStats(ctx context.Context) ([]NICPacketStat, error) {
+	before, err := readNICCounters()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(nicSampleInterval):
+	}
+
+	after, err := readNICCounters()
+	if err != nil {
+		return nil, err
+	}
+	seconds := time.Since(start).Seconds()
+
+	names := make([]string, 0, len(after))
+	for name := range after {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	stats := make([]NICPacketStat, 0, len(names))
+	for _, name := range names {
+		prev, ok := before[name]
+		cur := after[name]
+		// Skip interfaces that appeared during the interval or whose counters were reset
+		if !ok || cur.rxDropped < prev.rxDropped || cur.txDropped < prev.txDropped {
+			continue
+		}
+		stats = append(stats, NICPacketStat{
+			Interface:       name,
+			RxDroppedPerSec: float64(cur.rxDropped-prev.rxDropped) / seconds,
+			TxDroppedPerSec: float64(cur.txDropped-prev.txDropped) / seconds,
+		})
+	}
+	return stats, nil
+}
+
+// nicDetails describes the driver and ring buffers of an interface for alerts, e.g.
+// "driver: ixgbe 5.15.0, ring buffer: RX 512/4096, TX 512/4096". It needs ethtool; whatever
+// can't be read is left out.
+func nicDetails(ctx context.Context, iface string) string {
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+
+	var details []string
+	if output, err := exec.CommandContext(ctx, "ethtool", "-i", iface).Output(); err == nil {
+		info := parseEthtoolFields(string(output))
+		if info["driver"] != "" {
+			details = append(details, strings.TrimSpace("driver: "+info["driver"]+" "+info["version"]))
+		}
+	}
+	if output, err := exec.CommandContext(ctx, "ethtool", "-g", iface).Output(); err == nil {
+		if ring := formatRingBuffer(string(output)); ring != "" {
+			details = append(details, "ring buffer: "+ring)
+		}
+	}
+	return strings.Join(details, ", ")
+}
+
+// parseEthtoolFields parses "key: value" lines like those of 'ethtool -i'
+func parseEthtoolFields(output string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
+
+// formatRingBuffer summarizes the output of 'ethtool -g', which lists the "Pre-set maximums"
+// and then the "Current hardware settings" of the RX and TX rings, as "RX 512/4096, TX 512/4096"
+func formatRingBuffer(output string) string {
+	maximums, current, ok := strings.Cut(output, "Current hardware settings:")
+	if !ok {
+		return ""
+	}
+	maxFields, curFields := parseEthtoolFields(maximums), parseEthtoolFields(current)
+	var rings []string
+	for _, ring := range []string{"RX", "TX"} {
+		if curFields[ring] == "" {
+			continue
+		}
+		if maxFields[ring] != "" {
+			rings = append(rings, fmt.Sprintf("%s %s/%s", ring, curFields[ring], maxFields[ring]))
+		} else {
+			rings = append(rings, ring+" "+curFields[ring])
+		}
+	}
+	return strings.Join(rings, ", ")
+}
@@ -0,0 +1,170 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
+)
+
+// opsGenieSource is shown as the source of the alerts in OpsGenie
+const opsGenieSource = "go-system-monitor"
+
+// OpsGenieConfig holds the OpsGenie integration alerts are forwarded to
+type OpsGenieConfig struct {
+	APIKey   string `json:"api_key"`
+	TeamName string `json:"team_name"` // optional responder team
+}
+
+// AlertPayload is a single alert as created in OpsGenie
+type AlertPayload struct {
+	Alias       string // identifies the alert for deduplication and closing
+	Message     string // title, at most 130 characters
+	Description string
+	Severity    Severity // critical, error, warning or info
+
+	Alerts []AlertEntry // the individual alerts, for channels that send one event per alert
+}
+
+// opsGeniePriority maps the internal severity to an OpsGenie priority
+func opsGeniePriority(severity Severity) alert.Priority {
+	switch severity {
+	case SeverityCritical:
+		return alert.P1
+	case SeverityError:
+		return alert.P2
+	case SeverityWarning:
+		return alert.P3
+	default:
+		return alert.P4
+	}
+}
+
+// CreateOpsGenieAlert opens an alert. OpsGenie deduplicates open alerts with the same alias.
+func CreateOpsGenieAlert(cfg OpsGenieConfig, payload AlertPayload) error {
+	alertClient, err := alert.NewClient(&client.Config{ApiKey: cfg.APIKey})
+	if err != nil {
+		return fmt.Errorf("Error creating OpsGenie client: %w", err)
+	}
+
+	req := &alert.CreateAlertRequest{
+		Message:     payload.Message,
+		Alias:       payload.Alias,
+		Description: payload.Description,
+		Priority:    opsGeniePriority(payload.Severity),
+		Source:      opsGenieSource,
+	}
+	if cfg.TeamName != "" {
+		req.Responders = []alert.Responder{{Type: alert.TeamResponder, Name: cfg.TeamName}}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serviceCheckTimeout)
+	defer cancel()
+	if _, err := alertClient.Create(ctx, req); err != nil {
+		return fmt.Errorf("Error creating OpsGenie alert: %w", err)
+	}
+	return nil
+}
+
+// CloseOpsGenieAlert closes the open alert with the given alias
+func CloseOpsGenieAlert(cfg OpsGenieConfig, alertID string) error {
+	alertClient, err := alert.NewClient(&client.Config{ApiKey: cfg.APIKey})
+	if err != nil {
+		return fmt.Errorf("Error creating OpsGenie client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serviceCheckTimeout)
+	defer cancel()
+	_, err = alertClient.Close(ctx, &alert.CloseAlertRequest{
+		IdentifierType:  alert.ALIAS,
+		IdentifierValue: alertID,
+		Source:          opsGenieSource,
+		Note:            "Metric recovered",
+	})
+	if err != nil {
+		return fmt.Errorf("Error closing OpsGenie alert: %w", err)
+	}
+	return nil
+}
+
+// OpsGenieForwarder keeps one OpsGenie alert per breaching metric open and closes it once the metric recovers
+type OpsGenieForwarder struct {
+	config   OpsGenieConfig
+	hostname string
+	mu       sync.Mutex
+	open     map[string]bool // metrics with an alert opened by this process
+}
+
+// NewOpsGenieForwarder creates a forwarder for the given integration
+func NewOpsGenieForwarder(config OpsGenieConfig) *OpsGenieForwarder {
+	hostname, _ := os.Hostname()
+	return &OpsGenieForwarder{config: config, hostname: hostname, open: make(map[string]bool)}
+}
+
+// alias returns the alias of the alert for metric on this host
+func (f *OpsGenieForwarder) alias(metric string) string {
+	return fmt.Sprintf("%s:%s:%s", opsGenieSource, f.hostname, metric)
+}
+
+// Forward opens an alert for every metric in alerts that has no open alert yet and
+// closes the open alerts of collected metrics that are no longer breaching
+func (f *OpsGenieForwarder) Forward(alerts []AlertEntry, collected MetricSet) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	byMetric := make(map[string][]AlertEntry)
+	for _, entry := range alerts {
+		byMetric[entry.Metric] = append(byMetric[entry.Metric], entry)
+	}
+	metrics := make([]string, 0, len(byMetric))
+	for metric := range byMetric {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	var errs []error
+	for _, metric := range metrics {
+		if f.open[metric] {
+			continue
+		}
+		entries := byMetric[metric]
+		err := CreateOpsGenieAlert(f.config, AlertPayload{
+			Alias:       f.alias(metric),
+			Message:     truncate(fmt.Sprintf("%s: %s", f.hostname, entries[0].Message), 130),
+			Description: FormatAlertMessage(entries),
+			Severity:    highestSeverity(entries),
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		f.open[metric] = true
+	}
+
+	for metric := range f.open {
+		if _, breaching := byMetric[metric]; breaching || !collected.Covers(metric) {
+			continue
+		}
+		if err := CloseOpsGenieAlert(f.config, f.alias(metric)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(f.open, metric)
+	}
+
+	return errors.Join(errs...)
+}
+
+// truncate shortens s to at most n characters
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
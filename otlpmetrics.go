@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// otlpMetricPrefix is prepended to the metric names, as for Datadog
+const otlpMetricPrefix = "system_monitor."
+
+// OTLPMetricsConfig holds the OTLP endpoint metrics are exported to
+type OTLPMetricsConfig struct {
+	Endpoint string            `json:"endpoint"` // e.g. http://otel-collector:4318
+	Protocol string            `json:"protocol"` // http (default) or grpc
+	Headers  map[string]string `json:"headers"`  // sent with every export, e.g. an API key of the backend
+}
+
+// otlpLatest holds the latest points of every metric, observed by the OTLP gauges
+var otlpLatest = struct {
+	sync.Mutex
+	points map[string][]MetricPoint // by metric name
+}{points: make(map[string][]MetricPoint)}
+
+// RecordOTLPPoints makes points the current values of their metrics for the next OTLP export.
+// Metrics without points keep their previous values, as they are collected on their own interval.
+func RecordOTLPPoints(points []MetricPoint) {
+	byName := make(map[string][]MetricPoint)
+	for _, point := range points {
+		byName[point.Name] = append(byName[point.Name], point)
+	}
+	otlpLatest.Lock()
+	defer otlpLatest.Unlock()
+	for name, points := range byName {
+		otlpLatest.points[name] = points
+	}
+}
+
+// newOTLPMetricExporter creates the exporter for the configured protocol
+func newOTLPMetricExporter(ctx context.Context, cfg OTLPMetricsConfig) (sdkmetric.Exporter, error) {
+	switch cfg.Protocol {
+	case "", "http":
+		return otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpointURL(cfg.Endpoint), otlpmetrichttp.WithHeaders(cfg.Headers))
+	case "grpc":
+		return otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithEndpointURL(cfg.Endpoint), otlpmetricgrpc.WithHeaders(cfg.Headers))
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q (expected grpc or http)", cfg.Protocol)
+	}
+}
+
+// StartOTLPMetricsExporter registers every system metric as an OTLP gauge and exports the values
+// passed to RecordOTLPPoints periodically until ctx is done
+func StartOTLPMetricsExporter(ctx context.Context, cfg OTLPMetricsConfig) error {
+	exporter, err := newOTLPMetricExporter(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("could not create OTLP metric exporter: %w", err)
+	}
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithResource(monitorResource()),
+	)
+
+	meter := provider.Meter(tracerName)
+	for _, name := range scheduledMetrics {
+		name := name
+		_, err := meter.Float64ObservableGauge(otlpMetricPrefix+name,
+			metric.WithFloat64Callback(func(_ context.Context, observer metric.Float64Observer) error {
+				otlpLatest.Lock()
+				defer otlpLatest.Unlock()
+				for _, point := range otlpLatest.points[name] {
+					var attrs []attribute.KeyValue
+					for key, value := range point.Labels {
+						attrs = append(attrs, attribute.String(key, value))
+					}
+					observer.Observe(point.Value, metric.WithAttributes(attrs...))
+				}
+				return nil
+			}))
+		if err != nil {
+			return fmt.Errorf("could not register OTLP gauge %s: %w", name, err)
+		}
+	}
+
+	// Export the last values before shutting down
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serviceCheckTimeout)
+		defer cancel()
+		if err := provider.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error exporting metrics to OTLP: %v\n", err)
+		}
+	}()
+	return nil
+}
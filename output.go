@@ -0,0 +1,194 @@
+package monitor
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats supported by FormatSnapshot
+const (
+	OutputJSON  = "json"
+	OutputCSV   = "csv"
+	OutputTable = "table"
+)
+
+// metricRow is a single metric of a snapshot flattened for CSV and table output
+type metricRow struct {
+	Metric string
+	Value  float64
+	Unit   string
+	Status string // ok or alert
+}
+
+// FormatSnapshot writes snap to w in the given format (json, csv or table)
+func FormatSnapshot(snap MetricSnapshot, format string, w io.Writer) error {
+	switch format {
+	case OutputJSON:
+		return json.NewEncoder(w).Encode(snap)
+
+	case OutputCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"timestamp", "metric", "value", "unit", "status"})
+		timestamp := snap.Timestamp.Format(time.RFC3339)
+		for _, row := range snapshotRows(snap) {
+			cw.Write([]string{timestamp, row.Metric, strconv.FormatFloat(row.Value, 'f', 2, 64), row.Unit, row.Status})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case OutputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Metrics at %s\n", snap.Timestamp.Format(time.RFC3339))
+		fmt.Fprintln(tw, "METRIC\tVALUE\tUNIT\tSTATUS")
+		for _, row := range snapshotRows(snap) {
+			fmt.Fprintf(tw, "%s\t%.2f\t%s\t%s\n", row.Metric, row.Value, row.Unit, row.Status)
+		}
+		return tw.Flush()
+
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// MetricPoint is a single metric of a snapshot prepared for export to a monitoring backend
+type MetricPoint struct {
+	Name      string // e.g. cpu_usage
+	Value     float64
+	Unit      string
+	Timestamp time.Time
+	Labels    map[string]string // "instance" of per-core, per-host or per-name metrics
+}
+
+// SnapshotMetricPoints flattens snap into one point per metric. Per-instance metrics like
+// cpu_usage.0 or dns.example.com are split into the name and an "instance" label.
+func SnapshotMetricPoints(snap MetricSnapshot) []MetricPoint {
+	rows := snapshotRows(snap)
+	points := make([]MetricPoint, 0, len(rows))
+	for _, row := range rows {
+		point := MetricPoint{Name: row.Metric, Value: row.Value, Unit: row.Unit, Timestamp: snap.Timestamp}
+		if name, instance, ok := strings.Cut(row.Metric, "."); ok {
+			point.Name = name
+			point.Labels = map[string]string{"instance": instance}
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// snapshotRows flattens snap into one row per metric, evaluated against the thresholds.
+// Metrics whose collection failed have no row rather than a zero reading.
+func snapshotRows(snap MetricSnapshot) []metricRow {
+	rows := collectedRows(snap)
+	if len(snap.Failed) == 0 {
+		return rows
+	}
+	return slices.DeleteFunc(rows, func(row metricRow) bool { return snap.Failed[row.Metric] })
+}
+
+// collectedRows returns the rows of every metric in snap
+func collectedRows(snap MetricSnapshot) []metricRow {
+	tempUnit := snap.TemperatureUnit
+	rows := []metricRow{
+		{"cpu_temperature", tempUnit.FromCelsius(snap.CPUTemperature), tempUnit.Symbol(), status(snap.CPUTemperature > maxTemp || snap.CPUTemperature < minTemp)},
+	}
+	for _, socket := range sortedSockets(snap.SocketTemperatures) {
+		celsius := snap.SocketTemperatures[socket]
+		rows = append(rows, metricRow{fmt.Sprintf("cpu_temperature.socket%d", socket), tempUnit.FromCelsius(celsius), tempUnit.Symbol(), status(snap.SocketMaxTempC > 0 && celsius > snap.SocketMaxTempC)})
+	}
+	if snap.AmbientTemperature != nil {
+		rows = append(rows, metricRow{"ambient_temperature", tempUnit.FromCelsius(*snap.AmbientTemperature), tempUnit.Symbol(), status(*snap.AmbientTemperature > maxAmbientTempC)})
+	}
+	if snap.GPUTemperature != nil {
+		rows = append(rows, metricRow{"gpu_temperature", tempUnit.FromCelsius(*snap.GPUTemperature), tempUnit.Symbol(), status(*snap.GPUTemperature > maxGPUTempC)})
+	}
+	for _, stat := range snap.GPIOTemperatures {
+		rows = append(rows, metricRow{"gpio_temperature." + stat.Name, tempUnit.FromCelsius(stat.Celsius), tempUnit.Symbol(), status(stat.MaxTempC > 0 && stat.Celsius > stat.MaxTempC)})
+	}
+	for _, stat := range snap.ThermalSensors {
+		rows = append(rows, metricRow{"thermal_sensors." + stat.Name, tempUnit.FromCelsius(stat.Celsius), tempUnit.Symbol(), status(stat.MaxTempC > 0 && stat.Celsius > stat.MaxTempC)})
+	}
+	for i, ghz := range snap.CPUClockSpeeds {
+		rows = append(rows, metricRow{fmt.Sprintf("cpu_clock.%d", i), ghz, "GHz", status(ghz < maxClockSpeed)})
+	}
+	for i, usage := range snap.CPUUsage {
+		rows = append(rows, metricRow{fmt.Sprintf("cpu_usage.%d", i), usage, "%", status(usage > snap.Thresholds.CPUUsage)})
+	}
+	for _, domain := range snap.CPUPower {
+		rows = append(rows, metricRow{"cpu_power." + domain.Name, domain.Watts, "W", status(domain.IsPackage() && domain.Watts > maxPackagePowerW)})
+	}
+	for _, stat := range snap.CPUThrottle {
+		rows = append(rows, metricRow{fmt.Sprintf("cpu_throttle.%d", stat.CPU), stat.EventsPerSec, "events/s", status(stat.EventsPerSec > maxThrottleEventsPerSec)})
+	}
+	if snap.EDAC != nil {
+		rows = append(rows, metricRow{"edac.uncorrectable", float64(snap.EDAC.UncorrectableErrors), "errors", status(snap.EDAC.UncorrectableErrors > 0)})
+	}
+	for _, sensor := range snap.IPMI {
+		if sensor.Reading != nil {
+			rows = append(rows, metricRow{"ipmi." + sensor.Name, *sensor.Reading, sensor.Unit, status(sensor.Critical())})
+		}
+	}
+	rows = append(rows,
+		metricRow{"memory", snap.MemoryUsedPercent, "%", status(snap.MemoryUsedPercent > snap.Thresholds.MemoryUsage)},
+		metricRow{"disk", snap.DiskUsedPercent, "%", status(snap.DiskUsedPercent > snap.Thresholds.DiskUsage)},
+	)
+	if snap.MemBandwidth != nil {
+		rows = append(rows, metricRow{"memory_bandwidth", snap.MemBandwidth.TotalGBps(), "GB/s", status(snap.MemBandwidth.TotalGBps() > maxMemBandwidthGBps)})
+	}
+	if snap.KSM != nil {
+		rows = append(rows, metricRow{"ksm_saved", float64(snap.KSM.SavedBytes()) / (1 << 20), "MiB", status(!snap.KSM.Running)})
+	}
+	if snap.HugePages != nil && snap.HugePages.Total > 0 {
+		rows = append(rows, metricRow{"hugepages", snap.HugePages.AvailablePercent(), "%", status(snap.HugePages.AvailablePercent() < minHugePagesFreePercent)})
+	}
+	for _, stat := range snap.DiskLatency {
+		rows = append(rows, metricRow{"disk_latency." + stat.Device, stat.AwaitMs, "ms", status(stat.AwaitMs > maxDiskAwaitMs)})
+	}
+	for _, stat := range snap.Tmpfs {
+		rows = append(rows, metricRow{"tmpfs." + stat.Path, stat.UsedPercent, "%", status(stat.UsedPercent > maxTmpfsPercent)})
+	}
+	for _, stat := range snap.PathSizes {
+		rows = append(rows, metricRow{"file_size." + stat.Path, stat.SizeMB(), "MiB", "ok"})
+	}
+	for _, quota := range snap.DiskQuotas {
+		if quota.SoftLimitKB == 0 && quota.HardLimitKB == 0 {
+			continue
+		}
+		rows = append(rows, metricRow{"disk_quota." + quota.Type + ":" + quota.Name, float64(quota.UsedKB) / 1024, "MiB", status(quota.OverSoftLimit() || quota.OverHardLimit())})
+	}
+	for _, stat := range snap.DNS {
+		// A failed lookup has no IPs; latency thresholds are per check and not part of the snapshot
+		rows = append(rows, metricRow{"dns." + stat.Hostname, float64(stat.Latency) / float64(time.Millisecond), "ms", status(len(stat.IPs) == 0)})
+	}
+	for _, stat := range snap.Traceroute {
+		rows = append(rows, metricRow{"traceroute." + stat.Host, float64(stat.HopCount()), "hops", status(!stat.Reached)})
+	}
+	for _, stat := range snap.NICDrops {
+		dropped := stat.RxDroppedPerSec + stat.TxDroppedPerSec
+		rows = append(rows, metricRow{"nic." + stat.Interface, dropped, "packets/s", status(dropped > maxNICDropsPerSec)})
+	}
+	if snap.CiliumDrops != nil {
+		rows = append(rows, metricRow{"cilium.dropped", snap.CiliumDrops.Total(), "packets", "ok"})
+	}
+	for _, info := range snap.CT {
+		rows = append(rows, metricRow{"ct." + info.Domain, float64(len(info.Recent)), "certs", "ok"})
+	}
+	for _, stat := range snap.Cron {
+		rows = append(rows, metricRow{"cron." + stat.Name, stat.Staleness.Seconds(), "s", status(stat.Stale)})
+	}
+	return rows
+}
+
+// status converts a threshold breach into the status column value
+func status(breached bool) string {
+	if breached {
+		return "alert"
+	}
+	return "ok"
+}
@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// pathSizeCacheTTL is how long a measured path size is reused before the path is walked again
+const pathSizeCacheTTL = 5 * time.Minute
+
+// FileSizeCheck is a single entry of the file_size_checks config
+type FileSizeCheck struct {
+	Path      string `json:"path"`
+	MaxSizeMB int64  `json:"max_size_mb"`
+	Recursive bool   `json:"recursive"` // include the files in subdirectories
+}
+
+// PathSizeStat holds the total size of the files below a path
+type PathSizeStat struct {
+	Path       string    `json:"path"`
+	SizeBytes  int64     `json:"size_bytes"`
+	MeasuredAt time.Time `json:"measured_at"`
+}
+
+// SizeMB returns the size in MiB
+func (s PathSizeStat) SizeMB() float64 {
+	return float64(s.SizeBytes) / (1 << 20)
+}
+
+// pathSizes caches the measured sizes per path, as walking a large directory is expensive
+var pathSizes = struct {
+	sync.Mutex
+	stats map[string]PathSizeStat
+}{stats: make(map[string]PathSizeStat)}
+
+// GetPathSize returns the size of the file at path or the total size of the files in the
+// directory at path. Without recursive, files in subdirectories are not counted.
+// Entries that cannot be read are skipped.
+func GetPathSize(path string, recursive bool) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			if name == path {
+				return err
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if name != path && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Error measuring the size of %s: %w", path, err)
+	}
+	return size, nil
+}
+
+// CheckPathSize returns the size of a file_size_checks entry, measured at most every five minutes
+func CheckPathSize(check FileSizeCheck) (PathSizeStat, error) {
+	key := fmt.Sprintf("%s:%t", check.Path, check.Recursive)
+
+	pathSizes.Lock()
+	defer pathSizes.Unlock()
+	if stat, ok := pathSizes.stats[key]; ok && time.Since(stat.MeasuredAt) < pathSizeCacheTTL {
+		return stat, nil
+	}
+
+	size, err := GetPathSize(check.Path, check.Recursive)
+	if err != nil {
+		return PathSizeStat{Path: check.Path}, err
+	}
+	stat := PathSizeStat{Path: check.Path, SizeBytes: size, MeasuredAt: time.Now()}
+	pathSizes.stats[key] = stat
+	return stat, nil
+}
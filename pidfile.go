@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WritePIDFile writes the process ID to path, e.g. /var/run/monitor.pid. It refuses to if the
+// file holds the PID of another running process, and replaces a stale file. The file is written
+// to a temporary file first and renamed, so readers never see a partial PID.
+func WritePIDFile(path string) error {
+	if pid, err := readPIDFile(path); err == nil && pid != os.Getpid() && processRunning(pid) {
+		return fmt.Errorf("another instance is already running with PID %d (%s)", pid, path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Replacing invalid PID file: %v\n", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not create PID file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+
+	if _, err := fmt.Fprintf(tmp, "%d\n", os.Getpid()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write PID file: %w", err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write PID file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write PID file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not write PID file: %w", err)
+	}
+	return nil
+}
+
+// RemovePIDFile removes the PID file at path if it still holds the PID of this process
+func RemovePIDFile(path string) {
+	pid, err := readPIDFile(path)
+	if err != nil || pid != os.Getpid() {
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		log.Printf("Error removing PID file: %v\n", err)
+	}
+}
+
+// readPIDFile returns the PID stored in the file at path
+func readPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("%s does not hold a PID: %q", path, strings.TrimSpace(string(data)))
+	}
+	return pid, nil
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package monitor
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// processRunning reports whether a process with the given PID exists. Signal 0 only checks
+// for the process; EPERM means it exists but belongs to another user.
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package monitor
+
+import "os"
+
+// processRunning reports whether a process with the given PID exists. On Windows,
+// FindProcess opens the process and fails if there is none.
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"net/http"
+	"time"
+)
+
+// StartProbeServer serves a liveness probe on GET /healthz at addr: 200 OK while monitorFn
+// returns true, 503 Service Unavailable otherwise. Unlike the API server it needs no
+// configuration beyond the address, so it can be started before the monitor is set up.
+//
+// A matching Kubernetes container probe, with probe_addr set to ":8081":
+//
+//	livenessProbe:
+//	  httpGet:
+//	    path: /healthz
+//	    port: 8081
+//	  initialDelaySeconds: 10
+//	  periodSeconds: 30
+//	  failureThreshold: 3
+func StartProbeServer(addr string, monitorFn func() bool) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if !monitorFn() {
+			http.Error(w, "unhealthy", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	return http.ListenAndServe(addr, mux)
+}
+
+// monitorHealthy reports whether the last collection cycle is no older than twice the
+// collection_interval. Until the first cycle completes, the time since startedAt counts.
+func monitorHealthy(config Config, snap MetricSnapshot, startedAt, now time.Time) bool {
+	interval := time.Duration(config.CollectionInterval) * time.Second
+	if interval <= 0 {
+		return !snap.Timestamp.IsZero()
+	}
+	last := snap.Timestamp
+	if last.IsZero() {
+		last = startedAt
+	}
+	return now.Sub(last) <= 2*interval
+}
@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// ProcessCheck is a single entry of the process_checks config
+type ProcessCheck struct {
+	Name           string `json:"name"`            // process name, or a regular expression matching the whole name
+	MinCount       int    `json:"min_count"`       // e.g. 1 for a required daemon
+	MaxCount       int    `json:"max_count"`       // 0 means no upper limit
+	RestartCommand string `json:"restart_command"` // run when too few processes are found and auto_restart is set
+}
+
+// ProcessCheckResult holds the processes found for a ProcessCheck
+type ProcessCheckResult struct {
+	Name  string  `json:"name"`
+	Count int     `json:"count"`
+	PIDs  []int32 `json:"pids,omitempty"`
+	OK    bool    `json:"ok"`
+}
+
+// CheckProcesses counts the running processes whose name matches each check
+func CheckProcesses(ctx context.Context, checks []ProcessCheck) ([]ProcessCheckResult, error) {
+	patterns := make([]*regexp.Regexp, len(checks))
+	for i, check := range checks {
+		pattern, err := regexp.Compile("^(?:" + check.Name + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid process name pattern %q: %w", check.Name, err)
+		}
+		patterns[i] = pattern
+	}
+
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error listing processes: %w", err)
+	}
+
+	results := make([]ProcessCheckResult, len(checks))
+	for i, check := range checks {
+		results[i].Name = check.Name
+	}
+	for _, proc := range procs {
+		// Processes may exit while we iterate
+		name, err := proc.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		for i, pattern := range patterns {
+			if pattern.MatchString(name) {
+				results[i].Count++
+				results[i].PIDs = append(results[i].PIDs, proc.Pid)
+			}
+		}
+	}
+
+	for i, check := range checks {
+		results[i].OK = results[i].Count >= check.MinCount && (check.MaxCount == 0 || results[i].Count <= check.MaxCount)
+	}
+	return results, nil
+}
+
+// RestartProcess runs the restart command of a check through the shell
+func RestartProcess(ctx context.Context, check ProcessCheck) error {
+	output, err := exec.CommandContext(ctx, "sh", "-c", check.RestartCommand).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Error running restart command %q: %w: %s", check.RestartCommand, err, output)
+	}
+	return nil
+}
+
+// restartProcesses runs the restart command of each check that found too few processes;
+// results are those of CheckProcesses for the same checks
+func restartProcesses(ctx context.Context, checks []ProcessCheck, results []ProcessCheckResult) {
+	for i, result := range results {
+		check := checks[i]
+		if check.RestartCommand == "" || result.Count >= check.MinCount {
+			continue
+		}
+		log.Printf("Restarting %s: %s\n", result.Name, check.RestartCommand)
+		if err := RestartProcess(ctx, check); err != nil {
+			log.Printf("Error restarting %s: %v\n", result.Name, err)
+		}
+	}
+}
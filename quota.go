@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrDiskQuotaNotAvailable is returned on systems without the Linux quota tools
+var ErrDiskQuotaNotAvailable = errors.New("disk quota monitoring is only supported on Linux with the quota tools installed")
+
+// QuotaEntry holds the block usage and limits of a user or group on a file system.
+// Limits of 0 mean no limit.
+type QuotaEntry struct {
+	Device       string `json:"device"`
+	Type         string `json:"type"` // user or group
+	Name         string `json:"name"`
+	UsedKB       int64  `json:"used_kb"`
+	SoftLimitKB  int64  `json:"soft_limit_kb"`
+	HardLimitKB  int64  `json:"hard_limit_kb"`
+	GraceSeconds int64  `json:"grace_seconds"` // time left to get below the soft limit; 0 when not over it
+}
+
+// OverSoftLimit reports whether the usage exceeds the soft limit
+func (q QuotaEntry) OverSoftLimit() bool {
+	return q.SoftLimitKB > 0 && q.UsedKB > q.SoftLimitKB
+}
+
+// OverHardLimit reports whether the usage reached the hard limit
+func (q QuotaEntry) OverHardLimit() bool {
+	return q.HardLimitKB > 0 && q.UsedKB >= q.HardLimitKB
+}
+
+// parseRepquota parses the output of 'repquota -u -g -p', which has a section per file system
+// and quota type:
+//
+//	*** Report for user quotas on device /dev/sda1
+//	Block grace time: 7days; Inode grace time: 7days
+//	                        Block limits                File limits
+//	User            used    soft    hard  grace    used  soft  hard  grace
+//	----------------------------------------------------------------------
+//	alice     +-    1200    1000    2000 604800      10     0     0      0
+func parseRepquota(output string) ([]QuotaEntry, error) {
+	var entries []QuotaEntry
+	var device, quotaType string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if header, ok := strings.CutPrefix(line, "*** Report for "); ok {
+			// e.g. "user quotas on device /dev/sda1"
+			fields := strings.Fields(header)
+			if len(fields) < 5 {
+				return nil, fmt.Errorf("could not parse repquota header %q", line)
+			}
+			quotaType, device = fields[0], fields[len(fields)-1]
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if quotaType == "" || len(fields) < 6 || len(fields[1]) != 2 || strings.Trim(fields[1], "+-") != "" {
+			continue
+		}
+		var values [4]int64
+		for i := range values {
+			value, err := strconv.ParseInt(fields[2+i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse repquota line %q: %w", line, err)
+			}
+			values[i] = value
+		}
+		entries = append(entries, QuotaEntry{
+			Device:       device,
+			Type:         quotaType,
+			Name:         fields[0],
+			UsedKB:       values[0],
+			SoftLimitKB:  values[1],
+			HardLimitKB:  values[2],
+			GraceSeconds: values[3],
+		})
+	}
+	return entries, scanner.Err()
+}
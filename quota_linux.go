@@ -0,0 +1,32 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// GetDiskQuotas returns the user and group quotas of device, or of all file systems with quotas
+// enabled when device is empty, using 'repquota' (which must run as root)
+func GetDiskQuotas(ctx context.Context, device string) ([]QuotaEntry, error) {
+	args := []string{"-u", "-g", "-p"}
+	if device == "" {
+		args = append(args, "-a")
+	} else {
+		args = append(args, device)
+	}
+	output, err := exec.CommandContext(ctx, "repquota", args...).Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, ErrDiskQuotaNotAvailable
+		}
+		return nil, fmt.Errorf("Error fetching disk quotas: %w", err)
+	}
+	return parseRepquota(string(output))
+}
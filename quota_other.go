@@ -0,0 +1,10 @@
+//go:build !linux
+
+package monitor
+
+import "context"
+
+// GetDiskQuotas is only supported on Linux
+func GetDiskQuotas(ctx context.Context, device string) ([]QuotaEntry, error) {
+	return nil, ErrDiskQuotaNotAvailable
+}
@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RabbitMQCheck is a single entry of the rabbitmq_checks config
+type RabbitMQCheck struct {
+	ManagementURL string   `json:"url"` // e.g. http://rabbit1:15672
+	Username      string   `json:"username"`
+	Password      string   `json:"password"`
+	MaxQueueDepth int      `json:"max_queue_depth"`
+	Queues        []string `json:"queues"` // queue names to check; empty checks every queue
+}
+
+// QueueStat is a single queue as reported by the management API
+type QueueStat struct {
+	ManagementURL string `json:"url"` // the node the queue was fetched from
+	Name          string `json:"name"`
+	VHost         string `json:"vhost"`
+	MessagesReady int    `json:"messages_ready"`
+	Consumers     int    `json:"consumers"`
+}
+
+// CheckRabbitMQQueues fetches the queues of a RabbitMQ node, limited to cfg.Queues when set
+func CheckRabbitMQQueues(ctx context.Context, cfg RabbitMQCheck) ([]QueueStat, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(cfg.ManagementURL, "/")+"/api/queues", nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating RabbitMQ request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent())
+	req.SetBasicAuth(cfg.Username, cfg.Password)
+
+	client := &http.Client{Timeout: serviceCheckTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching RabbitMQ queues: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RabbitMQ returned %s", resp.Status)
+	}
+
+	var queues []QueueStat
+	if err := json.NewDecoder(resp.Body).Decode(&queues); err != nil {
+		return nil, fmt.Errorf("could not parse RabbitMQ queues: %w", err)
+	}
+	for i := range queues {
+		queues[i].ManagementURL = cfg.ManagementURL
+	}
+	if len(cfg.Queues) == 0 {
+		return queues, nil
+	}
+
+	wanted := make(map[string]bool, len(cfg.Queues))
+	for _, name := range cfg.Queues {
+		wanted[name] = true
+	}
+	var filtered []QueueStat
+	for _, queue := range queues {
+		if wanted[queue.Name] {
+			filtered = append(filtered, queue)
+		}
+	}
+	return filtered, nil
+}
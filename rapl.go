@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// raplSampleInterval is the time between the two energy readings used to compute watts
+const raplSampleInterval = 1 * time.Second
+
+// ErrRAPLNotAvailable is returned when the system does not expose RAPL energy counters
+var ErrRAPLNotAvailable = errors.New("RAPL power monitoring is not available on this system")
+
+// RAPLDomain holds the average power draw of a single RAPL domain
+// (package, core, uncore or DRAM) over the sample interval
+type RAPLDomain struct {
+	Zone  string  `json:"zone"` // e.g. intel-rapl:0 or intel-rapl:0:1
+	Name  string  `json:"name"` // e.g. package-0, core, uncore, dram
+	Watts float64 `json:"watts"`
+}
+
+// IsPackage reports whether the domain covers a whole CPU package
+func (d RAPLDomain) IsPackage() bool {
+	return strings.HasPrefix(d.Name, "package")
+}
+
+// GetRAPLPower reads the RAPL energy counters twice and returns the power draw per domain in watts
+func GetRAPLPower(ctx context.Context) ([]RAPLDomain, error) {
+	// Both top-level zones (intel-rapl:0) and sub-zones (intel-rapl:0:0) are listed by the powercap framework
+	zones, err := filepath.Glob(filepath.Join(sysfsRoot, "class", "powercap", "intel-rapl*"))
+	if err != nil {
+		return nil, fmt.Errorf("Error listing RAPL zones: %w", err)
+	}
+
+	var zoneDirs []string
+	for _, zone := range zones {
+		if _, err := os.Stat(filepath.Join(zone, "energy_uj")); err == nil {
+			zoneDirs = append(zoneDirs, zone)
+		}
+	}
+	if len(zoneDirs) == 0 {
+		return nil, ErrRAPLNotAvailable
+	}
+
+	before := make([]uint64, len(zoneDirs))
+	for i, dir := range zoneDirs {
+		before[i], err = readSysfsUint(filepath.Join(dir, "energy_uj"))
+		if err != nil {
+			return nil, fmt.Errorf("Error reading RAPL energy counter: %w", err)
+		}
+	}
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(raplSampleInterval):
+	}
+
+	elapsed := time.Since(start).Seconds()
+	domains := make([]RAPLDomain, 0, len(zoneDirs))
+	for i, dir := range zoneDirs {
+		after, err := readSysfsUint(filepath.Join(dir, "energy_uj"))
+		if err != nil {
+			return nil, fmt.Errorf("Error reading RAPL energy counter: %w", err)
+		}
+
+		delta := after - before[i]
+		if after < before[i] {
+			// The counter wrapped around; max_energy_range_uj is the wrap point
+			maxRange, err := readSysfsUint(filepath.Join(dir, "max_energy_range_uj"))
+			if err != nil {
+				return nil, fmt.Errorf("Error reading RAPL energy range: %w", err)
+			}
+			delta = maxRange - before[i] + after
+		}
+
+		name, err := os.ReadFile(filepath.Join(dir, "name"))
+		if err != nil {
+			return nil, fmt.Errorf("Error reading RAPL zone name: %w", err)
+		}
+
+		domains = append(domains, RAPLDomain{
+			Zone:  filepath.Base(dir),
+			Name:  strings.TrimSpace(string(name)),
+			Watts: float64(delta) / 1e6 / elapsed,
+		})
+	}
+
+	return domains, nil
+}
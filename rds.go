@@ -0,0 +1,108 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+const (
+	rdsMetricPeriod   = 60               // seconds; RDS publishes its metrics every minute
+	rdsMetricLookback = 10 * time.Minute // the latest datapoint within this window is used
+)
+
+// RDSCheck is a single entry of the rds_checks config. Credentials come from the standard
+// AWS credential chain and need cloudwatch:GetMetricData.
+type RDSCheck struct {
+	Region               string  `json:"region"`
+	DBInstanceIdentifier string  `json:"db_instance_identifier"`
+	MaxCPUPercent        float64 `json:"max_cpu_percent"`
+	MinFreeableMemoryMB  float64 `json:"min_freeable_memory_mb"`
+	MinFreeStorageGB     float64 `json:"min_free_storage_gb"`
+	MaxConnections       float64 `json:"max_connections"`
+	MaxReplicaLagSeconds float64 `json:"max_replica_lag_seconds"`
+}
+
+// RDSStat holds the latest CloudWatch metrics of an RDS instance
+type RDSStat struct {
+	DBInstanceIdentifier string   `json:"db_instance_identifier"`
+	CPUPercent           float64  `json:"cpu_percent"`
+	FreeableMemoryBytes  float64  `json:"freeable_memory_bytes"`
+	FreeStorageBytes     float64  `json:"free_storage_bytes"`
+	Connections          float64  `json:"connections"`
+	ReplicaLagSeconds    *float64 `json:"replica_lag_seconds,omitempty"` // only reported by read replicas
+}
+
+// CheckRDSMetrics fetches CPUUtilization, FreeableMemory, FreeStorageSpace, DatabaseConnections
+// and ReplicaLag of the instance from CloudWatch with a single GetMetricData call
+func CheckRDSMetrics(ctx context.Context, cfg RDSCheck) (RDSStat, error) {
+	stat := RDSStat{DBInstanceIdentifier: cfg.DBInstanceIdentifier}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.Region))
+	if err != nil {
+		return stat, fmt.Errorf("Error loading AWS configuration: %w", err)
+	}
+	client := cloudwatch.NewFromConfig(awsCfg)
+
+	metrics := []struct {
+		id, name string
+	}{
+		{"cpu", "CPUUtilization"},
+		{"memory", "FreeableMemory"},
+		{"storage", "FreeStorageSpace"},
+		{"connections", "DatabaseConnections"},
+		{"lag", "ReplicaLag"},
+	}
+	queries := make([]types.MetricDataQuery, len(metrics))
+	for i, metric := range metrics {
+		queries[i] = types.MetricDataQuery{
+			Id: aws.String(metric.id),
+			MetricStat: &types.MetricStat{
+				Metric: &types.Metric{
+					Namespace:  aws.String("AWS/RDS"),
+					MetricName: aws.String(metric.name),
+					Dimensions: []types.Dimension{{Name: aws.String("DBInstanceIdentifier"), Value: aws.String(cfg.DBInstanceIdentifier)}},
+				},
+				Period: aws.Int32(rdsMetricPeriod),
+				Stat:   aws.String("Average"),
+			},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+	now := time.Now()
+	out, err := client.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+		MetricDataQueries: queries,
+		StartTime:         aws.Time(now.Add(-rdsMetricLookback)),
+		EndTime:           aws.Time(now),
+		ScanBy:            types.ScanByTimestampDescending,
+	})
+	if err != nil {
+		return stat, fmt.Errorf("Error fetching RDS metrics of %s: %w", cfg.DBInstanceIdentifier, err)
+	}
+
+	// Results are newest first; metrics without datapoints in the window are missing
+	latest := make(map[string]float64)
+	for _, result := range out.MetricDataResults {
+		if result.Id != nil && len(result.Values) > 0 {
+			latest[*result.Id] = result.Values[0]
+		}
+	}
+	if _, ok := latest["cpu"]; !ok {
+		return stat, fmt.Errorf("no CloudWatch datapoints for RDS instance %s in the last %s", cfg.DBInstanceIdentifier, rdsMetricLookback)
+	}
+	stat.CPUPercent = latest["cpu"]
+	stat.FreeableMemoryBytes = latest["memory"]
+	stat.FreeStorageBytes = latest["storage"]
+	stat.Connections = latest["connections"]
+	if lag, ok := latest["lag"]; ok {
+		stat.ReplicaLagSeconds = &lag
+	}
+	return stat, nil
+}
@@ -0,0 +1,164 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCheck is a single entry of the redis_checks config
+type RedisCheck struct {
+	// Addr is host:port, or a comma-separated list of sentinel or cluster node addresses
+	Addr       string `json:"addr"`
+	MasterName string `json:"master_name"` // set to connect through Redis Sentinel
+	Password   string `json:"password"`
+	DB         int    `json:"db"`
+
+	MaxMemoryPercent    float64 `json:"max_memory_percent"` // of maxmemory; ignored when maxmemory is unlimited
+	MaxConnectedClients int     `json:"max_connected_clients"`
+	MaxBlockedClients   int     `json:"max_blocked_clients"`
+}
+
+// RedisStat holds the memory and client statistics reported by INFO
+type RedisStat struct {
+	Addr             string `json:"addr"`
+	UsedMemoryBytes  int64  `json:"used_memory_bytes"`
+	MaxMemoryBytes   int64  `json:"max_memory_bytes"` // 0 means no limit
+	ConnectedClients int    `json:"connected_clients"`
+	BlockedClients   int    `json:"blocked_clients"`
+}
+
+// MemoryPercent returns the used memory as a percentage of maxmemory, or 0 without a limit
+func (s RedisStat) MemoryPercent() float64 {
+	if s.MaxMemoryBytes == 0 {
+		return 0
+	}
+	return float64(s.UsedMemoryBytes) / float64(s.MaxMemoryBytes) * 100
+}
+
+// CheckRedisHealth runs INFO memory and INFO clients against a standalone, Sentinel or Cluster deployment.
+// For clusters the statistics are those of the node that served the command.
+func CheckRedisHealth(ctx context.Context, cfg RedisCheck) (RedisStat, error) {
+	stat := RedisStat{Addr: cfg.Addr}
+
+	client := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:       strings.Split(cfg.Addr, ","),
+		MasterName:  cfg.MasterName,
+		Password:    cfg.Password,
+		DB:          cfg.DB,
+		DialTimeout: serviceCheckTimeout,
+		ReadTimeout: serviceCheckTimeout,
+	})
+	defer client.Close()
+
+	info, err := client.Info(ctx, "memory", "clients").Result()
+	if err != nil {
+		return stat, fmt.Errorf("Error running Redis INFO: %w", err)
+	}
+
+	// INFO returns "key:value" lines grouped under "# Section" headers
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "used_memory":
+			stat.UsedMemoryBytes, _ = strconv.ParseInt(value, 10, 64)
+		case "maxmemory":
+			stat.MaxMemoryBytes, _ = strconv.ParseInt(value, 10, 64)
+		case "connected_clients":
+			stat.ConnectedClients, _ = strconv.Atoi(value)
+		case "blocked_clients":
+			stat.BlockedClients, _ = strconv.Atoi(value)
+		}
+	}
+
+	return stat, nil
+}
+
+// SentinelCheck is a single entry of the redis_sentinel_checks config
+type SentinelCheck struct {
+	SentinelAddrs []string `json:"sentinel_addrs"` // host:port of the sentinels; the first that answers is used
+	MasterName    string   `json:"master_name"`
+	Password      string   `json:"password"` // of the sentinels, if they require AUTH
+}
+
+// SentinelReplica is a replica of a master as seen by Sentinel
+type SentinelReplica struct {
+	Addr       string `json:"addr"`
+	Flags      string `json:"flags"`       // e.g. slave or s_down,slave,disconnected
+	LinkStatus string `json:"link_status"` // master-link-status: ok or err
+}
+
+// Disconnected reports whether the replica is down or lost its link to the master
+func (r SentinelReplica) Disconnected() bool {
+	for _, flag := range strings.Split(r.Flags, ",") {
+		if flag == "disconnected" || flag == "s_down" || flag == "o_down" {
+			return true
+		}
+	}
+	return r.LinkStatus != "" && r.LinkStatus != "ok"
+}
+
+// SentinelStat holds the master and replicas of a Sentinel-monitored master
+type SentinelStat struct {
+	MasterName  string            `json:"master_name"`
+	Sentinel    string            `json:"sentinel"` // the sentinel that answered
+	MasterAddr  string            `json:"master_addr"`
+	ConfigEpoch int64             `json:"config_epoch"` // incremented by every failover
+	Replicas    []SentinelReplica `json:"replicas"`
+}
+
+// CheckSentinelHealth asks the first reachable sentinel for the master (SENTINEL MASTER, the
+// single-master form of SENTINEL MASTERS) and its replicas (SENTINEL REPLICAS, formerly
+// SENTINEL SLAVES). Sentinel has no failover counter, so failovers are detected by comparing
+// the config epoch of the master, which every failover increments, between checks.
+func CheckSentinelHealth(ctx context.Context, cfg SentinelCheck) (SentinelStat, error) {
+	stat := SentinelStat{MasterName: cfg.MasterName}
+	if len(cfg.SentinelAddrs) == 0 {
+		return stat, fmt.Errorf("no sentinel_addrs configured for master %s", cfg.MasterName)
+	}
+
+	var errs []error
+	for _, addr := range cfg.SentinelAddrs {
+		client := redis.NewSentinelClient(&redis.Options{
+			Addr:        addr,
+			Password:    cfg.Password,
+			DialTimeout: serviceCheckTimeout,
+			ReadTimeout: serviceCheckTimeout,
+		})
+		master, err := client.Master(ctx, cfg.MasterName).Result()
+		if err != nil {
+			client.Close()
+			errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+			continue
+		}
+		replicas, err := client.Replicas(ctx, cfg.MasterName).Result()
+		client.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+			continue
+		}
+
+		stat.Sentinel = addr
+		stat.MasterAddr = net.JoinHostPort(master["ip"], master["port"])
+		stat.ConfigEpoch, _ = strconv.ParseInt(master["config-epoch"], 10, 64)
+		for _, replica := range replicas {
+			stat.Replicas = append(stat.Replicas, SentinelReplica{
+				Addr:       net.JoinHostPort(replica["ip"], replica["port"]),
+				Flags:      replica["flags"],
+				LinkStatus: replica["master-link-status"],
+			})
+		}
+		return stat, nil
+	}
+	return stat, fmt.Errorf("Error querying Redis Sentinel for master %s: %w", cfg.MasterName, errors.Join(errs...))
+}
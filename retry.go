@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultMaxRetryAttempts is used when max_retry_attempts is not configured
+const defaultMaxRetryAttempts = 3
+
+// maxRetryDelay caps the exponential backoff between attempts
+const maxRetryDelay = time.Minute
+
+// emailRetryDelay is the wait before the first retry of a failed email
+const emailRetryDelay = 2 * time.Second
+
+// RetryWithBackoff calls fn until it succeeds or maxAttempts attempts were made, doubling the
+// wait between attempts starting at initialDelay (capped at maxRetryDelay). It returns the last
+// error, or stops waiting once ctx is done.
+func RetryWithBackoff(ctx context.Context, fn func() error, maxAttempts int, initialDelay time.Duration) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	delay := initialDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt == maxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", maxAttempts, err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("giving up after %d attempts (%v): %w", attempt, ctx.Err(), err)
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
@@ -0,0 +1,216 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scheduledMetrics lists the metrics collected by runChecks. Each can be given its own
+// collection interval in the metrics config; the others are collected every collection_interval.
+var scheduledMetrics = []string{
+	"cpu_temperature", // includes ambient_temperature
+	"gpu_temperature",
+	"gpio_temperature",
+	"thermal_sensors",
+	"fan_speed",
+	"cpu_clock",
+	"cpu_usage",
+	"cpu_power",
+	"cpu_throttle",
+	"edac",
+	"ipmi",
+	"memory",
+	"memory_bandwidth",
+	"hugepages",
+	"disk",
+	"tmpfs",
+	"disk_latency",
+	"file_size",
+	"disk_quota",
+	"mac",
+	"dns",
+	"traceroute",
+	"nic",
+	"cilium",
+	"ct",
+	"cron",
+	"elasticsearch",
+	"postgres",
+	"redis",
+	"mongo",
+	"rabbitmq",
+	"kafka",
+	"mysql",
+	"rds",
+	"consul",
+	"etcd",
+	"vault",
+	"haproxy",
+	"nginx",
+	"apache",
+	"process",
+}
+
+// collectedWith maps metrics that are collected as part of another scheduled metric
+var collectedWith = map[string]string{
+	"ambient_temperature": "cpu_temperature",
+	"ksm_saved":           "memory",
+}
+
+// Duration is a time.Duration read from config as a string like "30s" or "5m"
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON parses a Go duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("could not parse duration %s: expected a string like \"5m\"", data)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("could not parse duration: %w", err)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MetricConfig is a single entry of the metrics config
+type MetricConfig struct {
+	Interval Duration `json:"interval"` // collection interval; defaults to collection_interval
+}
+
+// MetricSet is the set of scheduled metrics collected by a run of runChecks
+type MetricSet map[string]bool
+
+// allMetrics returns a MetricSet of every scheduled metric
+func allMetrics() MetricSet {
+	metrics := make(MetricSet, len(scheduledMetrics))
+	for _, metric := range scheduledMetrics {
+		metrics[metric] = true
+	}
+	return metrics
+}
+
+// Has reports whether the scheduled metric is collected
+func (m MetricSet) Has(metric string) bool {
+	return m[metric]
+}
+
+// Covers reports whether the alert or export metric was collected. Correlated alerts
+// (e.g. cpu_usage+memory) are covered when all of their metrics are.
+func (m MetricSet) Covers(metric string) bool {
+	for _, part := range strings.Split(metric, "+") {
+		if !m[scheduledMetric(part)] {
+			return false
+		}
+	}
+	return true
+}
+
+// scheduledMetric returns the scheduled metric that collects metric. Per-instance
+// names like cpu_usage.0 map to their metric.
+func scheduledMetric(metric string) string {
+	metric, _, _ = strings.Cut(metric, ".")
+	if scheduled, ok := collectedWith[metric]; ok {
+		return scheduled
+	}
+	return metric
+}
+
+// String lists the metrics in the set
+func (m MetricSet) String() string {
+	names := make([]string, 0, len(m))
+	for metric := range m {
+		names = append(names, metric)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Points returns the points of the metrics in the set
+func (m MetricSet) Points(points []MetricPoint) []MetricPoint {
+	var collected []MetricPoint
+	for _, point := range points {
+		if m.Covers(point.Name) {
+			collected = append(collected, point)
+		}
+	}
+	return collected
+}
+
+// validateMetricIntervals rejects unknown metric names and negative intervals in the metrics config
+func validateMetricIntervals(metrics map[string]MetricConfig) error {
+	known := allMetrics()
+	for name, metric := range metrics {
+		if !known.Has(name) {
+			return fmt.Errorf("unknown metric %q in metrics config", name)
+		}
+		if metric.Interval.Duration < 0 {
+			return fmt.Errorf("interval of metric %q must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// metricGroup is a set of metrics collected together on the same interval
+type metricGroup struct {
+	interval time.Duration
+	metrics  MetricSet
+}
+
+// MetricScheduler collects each metric on its own interval. Metrics with an interval in the
+// metrics config are collected in their own goroutine; the others share collection_interval.
+type MetricScheduler struct {
+	groups  []metricGroup
+	collect func(ctx context.Context, metrics MetricSet, next time.Time)
+}
+
+// NewMetricScheduler creates a scheduler for config that calls collect with the due metrics
+// and the time they are due again
+func NewMetricScheduler(config Config, collect func(ctx context.Context, metrics MetricSet, next time.Time)) *MetricScheduler {
+	defaultGroup := metricGroup{
+		interval: time.Duration(config.CollectionInterval) * time.Second,
+		metrics:  make(MetricSet),
+	}
+	s := &MetricScheduler{collect: collect}
+	for _, metric := range scheduledMetrics {
+		if interval := config.Metrics[metric].Interval.Duration; interval > 0 {
+			s.groups = append(s.groups, metricGroup{interval: interval, metrics: MetricSet{metric: true}})
+		} else {
+			defaultGroup.metrics[metric] = true
+		}
+	}
+	if len(defaultGroup.metrics) > 0 {
+		s.groups = append(s.groups, defaultGroup)
+	}
+	return s
+}
+
+// Run collects every group immediately and then on its interval until ctx is done
+func (s *MetricScheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, group := range s.groups {
+		wg.Add(1)
+		go func(group metricGroup) {
+			defer wg.Done()
+			ticker := time.NewTicker(group.interval)
+			defer ticker.Stop()
+			for {
+				s.collect(ctx, group.metrics, time.Now().Add(group.interval))
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(group)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,226 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// selfTestPrefix marks the alerts of the self-test so they can't be mistaken for real ones
+const selfTestPrefix = "[self-test] "
+
+// SelfTestResult is the outcome of one step of the self-test; Err is nil when it passed
+type SelfTestResult struct {
+	Name string
+	Err  error
+}
+
+// selfTestHost is the reserved name used for the artificial service checks of the self-test
+const selfTestHost = "selftest.invalid"
+
+// selfTestConfig returns config with one artificial check of every service, so that each
+// evaluated metric has a check to be compared against
+func selfTestConfig(config Config) Config {
+	url := "http://" + selfTestHost
+	config.RequireSELinuxEnforcing = true
+	if config.SocketMaxTempC == 0 {
+		config.SocketMaxTempC = maxTemp
+	}
+	config.GeoIPDBPath = ""
+	config.FileSizeChecks = []FileSizeCheck{{Path: "/selftest", MaxSizeMB: 1}}
+	config.DNSChecks = []DNSCheck{{Hostname: selfTestHost, MaxLatencyMs: 100}}
+	config.TracerouteChecks = []TracerouteCheck{{Host: selfTestHost}}
+	config.CTChecks = []CTCheck{{Domain: selfTestHost}}
+	config.CronChecks = []CronCheck{{Name: "selftest", MaxStalenessSeconds: 60}}
+	config.ElasticsearchClusters = []ElasticsearchCluster{{URL: url + ":9200"}}
+	config.PostgresChecks = []PostgresCheck{{Name: "selftest", MaxConnections: 100}}
+	config.RedisChecks = []RedisCheck{{Addr: selfTestHost + ":6379", MaxConnectedClients: 100}}
+	config.MongoChecks = []MongoCheck{{Name: "selftest", MaxConnectionsPercent: 80}}
+	config.RabbitMQChecks = []RabbitMQCheck{{ManagementURL: url + ":15672", MaxQueueDepth: 100}}
+	config.KafkaChecks = []KafkaCheck{{ConsumerGroup: "selftest", MaxLagMessages: 100}}
+	config.MySQLChecks = []MySQLCheck{{Name: "selftest", MaxConnections: 100}}
+	config.RDSChecks = []RDSCheck{{DBInstanceIdentifier: "selftest", MaxCPUPercent: 80}}
+	config.EtcdChecks = []EtcdCheck{{Endpoints: []string{url + ":2379"}}}
+	config.VaultChecks = []VaultCheck{{Address: url + ":8200"}}
+	config.HAProxyChecks = []HAProxyCheck{{StatsURL: url + ":8404/stats"}}
+	config.NginxChecks = []NginxCheck{{StubStatusURL: url + "/nginx_status"}}
+	config.ApacheChecks = []ApacheCheck{{StatusURL: url + "/server-status", MaxWorkerUtilization: 0.9}}
+	config.ProcessChecks = []ProcessCheck{{Name: "selftest", MinCount: 1}}
+	return config
+}
+
+// selfTestSnapshots returns the previous and the current snapshot of a run in which every metric
+// of config, a selfTestConfig, breaches its threshold. Rates like the Cilium drop rate are
+// measured between the two.
+func selfTestSnapshots(config Config) (prev, snap MetricSnapshot) {
+	thresholds := config.EffectiveThresholds()
+	now := time.Now()
+	ambient, gpu := maxAmbientTempC+5, maxGPUTempC+5
+	selfTestCerts := []CTCertificate{{ID: 2, Issuer: "CN=selftest", CommonName: selfTestHost, LoggedAt: now}}
+	prev = MetricSnapshot{
+		Timestamp:   now.Add(-time.Minute),
+		CiliumDrops: &CiliumDropStat{Drops: []CiliumDropCount{{Reason: "Policy denied", Direction: "INGRESS"}}, SampledAt: now.Add(-time.Minute)},
+		CT:          []CTInfo{{Domain: selfTestHost, LatestID: 1}},
+	}
+	snap = MetricSnapshot{
+		Timestamp:          now,
+		Thresholds:         thresholds,
+		TemperatureUnit:    config.TemperatureUnit,
+		SocketMaxTempC:     config.SocketMaxTempC,
+		CPUTemperature:     maxTemp + 5,
+		SocketTemperatures: map[int]float64{0: maxTemp + 5, 1: maxTemp + 10},
+		AmbientTemperature: &ambient,
+		GPUTemperature:     &gpu,
+		GPIOTemperatures:   []GPIOTempStat{{ID: "28-selftest", Name: "selftest", Celsius: 50, MaxTempC: 40}},
+		ThermalSensors:     []ThermalSensorStat{{Name: "selftest/Package id 0", Celsius: 100, MaxTempC: 90}},
+		FanSpeeds:          "fan1: 0 RPM",
+		CPUClockSpeeds:     []float64{maxClockSpeed / 2},
+		CPUUsage:           []float64{min(thresholds.CPUUsage+10, 100)},
+		CPUPower:           []RAPLDomain{{Zone: "intel-rapl:0", Name: "package-0", Watts: maxPackagePowerW + 20}},
+		CPUThrottle:        []ThrottleStat{{CPU: 0, EventsPerSec: maxThrottleEventsPerSec * 10}},
+		EDAC:               &EDACStats{UncorrectableErrors: 1, SampledAt: now},
+		IPMI:               []IPMISensor{{Name: "selftest", Unit: "degrees C", Status: "cr"}},
+		MemoryUsedPercent:  min(thresholds.MemoryUsage+10, 100),
+		KSM:                &KSMStat{Running: false},
+		MemBandwidth:       &MemBandwidthStat{ReadGBps: maxMemBandwidthGBps, WriteGBps: maxMemBandwidthGBps},
+		HugePages:          &HugePageStat{Total: 100, Free: 1},
+		DiskUsedPercent:    min(thresholds.DiskUsage+10, 100),
+		DiskLatency:        []DiskLatencyStat{{Device: "selftest", AwaitMs: maxDiskAwaitMs * 2}},
+		Tmpfs:              []TmpfsStat{{Path: "/selftest", UsedPercent: 100}},
+		PathSizes:          []PathSizeStat{{Path: "/selftest", SizeBytes: 2 << 20, MeasuredAt: now}},
+		DiskQuotas:         []QuotaEntry{{Device: "/dev/selftest", Type: "user", Name: "selftest", UsedKB: 2048, HardLimitKB: 1024}},
+		MAC:                &MACStatus{SELinux: "permissive", AppArmor: macEnabled},
+		DNS:                []DNSStat{{Hostname: selfTestHost, Latency: time.Second, IPs: []string{"192.0.2.1"}}},
+		Traceroute:         []TracerouteStat{{Host: selfTestHost, Hops: []HopResult{{TTL: 1}}}},
+		NICDrops:           []NICPacketStat{{Interface: "selftest0", RxDroppedPerSec: maxNICDropsPerSec * 2}},
+		CiliumDrops:        &CiliumDropStat{Drops: []CiliumDropCount{{Reason: "Policy denied", Direction: "INGRESS", Count: maxCiliumDropsPerSec * 120}}, SampledAt: now},
+		CT:                 []CTInfo{{Domain: selfTestHost, Recent: selfTestCerts, LatestID: 2, Unexpired: selfTestCerts}},
+		Cron:               []CronStat{{Name: "selftest", Staleness: time.Hour, Stale: true}},
+		Elasticsearch:      []ESHealth{{URL: config.ElasticsearchClusters[0].URL, ClusterName: "selftest", Status: "red", UnassignedShards: 1}},
+		Postgres:           []PGStat{{Name: "selftest", Connections: 200}},
+		Redis:              []RedisStat{{Addr: config.RedisChecks[0].Addr, ConnectedClients: 200}},
+		Mongo:              []MongoStat{{Name: "selftest", UnhealthyMembers: 1}},
+		RabbitMQ:           []QueueStat{{ManagementURL: config.RabbitMQChecks[0].ManagementURL, Name: "selftest", MessagesReady: 200}},
+		Kafka:              []PartitionLag{{ConsumerGroup: "selftest", Topic: "selftest", Lag: 200}},
+		MySQL:              []MySQLStat{{Name: "selftest", ThreadsConnected: 200}},
+		RDS:                []RDSStat{{DBInstanceIdentifier: "selftest", CPUPercent: 100}},
+		Consul:             []ConsulServiceStat{{Service: "selftest", FailingChecks: []ConsulFailingCheck{{Node: "selftest", CheckName: "selftest", Status: "critical"}}}},
+		Etcd:               []EtcdStat{{Endpoints: []EtcdEndpointStat{{Endpoint: config.EtcdChecks[0].Endpoints[0]}}}},
+		Vault:              []VaultStat{{Address: config.VaultChecks[0].Address, Initialized: true, Sealed: true}},
+		HAProxy:            []BackendStat{{StatsURL: config.HAProxyChecks[0].StatsURL, Proxy: "selftest", Server: "BACKEND", Status: "DOWN"}},
+		Nginx:              []NginxStat{{URL: config.NginxChecks[0].StubStatusURL, Accepts: 10, Handled: 9}},
+		Apache:             []ApacheStat{{URL: config.ApacheChecks[0].StatusURL, BusyWorkers: 10}},
+		Processes:          []ProcessCheckResult{{Name: "selftest"}},
+	}
+	return prev, snap
+}
+
+// RunSelfTest runs the alert pipeline on artificial out-of-threshold values without touching the
+// system: it checks that the threshold evaluation of every metric detects the breach, that the
+// alerts are formatted with the configured alert_template, and that each configured alert channel
+// is reachable. Nothing is sent; the channels are only checked as in the startup validation.
+func RunSelfTest(ctx context.Context, config Config) []SelfTestResult {
+	selfConfig := selfTestConfig(config)
+	prev, snap := selfTestSnapshots(selfConfig)
+
+	// The evaluation must not add details by running commands like ethtool, so its context is done
+	evalCtx, cancelEval := context.WithCancel(ctx)
+	cancelEval()
+	alerts := evaluateMetrics(evalCtx, selfConfig, allMetrics(), snap, prev, nil)
+
+	// Every metric must alert on its artificial value
+	alerted := make(map[string]bool, len(alerts))
+	for i := range alerts {
+		alerted[alerts[i].Metric] = true
+		alerts[i].Message = selfTestPrefix + alerts[i].Message
+		alerts[i].Timestamp = snap.Timestamp
+	}
+	var missed []string
+	for _, metric := range scheduledMetrics {
+		if !alerted[metric] {
+			missed = append(missed, metric)
+		}
+	}
+	for metric := range collectedWith {
+		if !alerted[metric] {
+			missed = append(missed, metric)
+		}
+	}
+	sort.Strings(missed)
+	results := []SelfTestResult{{Name: "thresholds"}}
+	if len(missed) > 0 {
+		results[0].Err = fmt.Errorf("no alert for %s", strings.Join(missed, ", "))
+	}
+
+	// The alerts go through the same correlation, templating and grouping as real ones
+	results = append(results, SelfTestResult{Name: "formatting", Err: checkSelfTestAlerts(config, alerts)})
+
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+	checks := alertChannelChecks(config)
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		results = append(results, SelfTestResult{Name: name + " channel", Err: checks[name](ctx)})
+	}
+	return results
+}
+
+// checkSelfTestAlerts formats the alerts like runChecks and sendAlertGroup and checks the result
+func checkSelfTestAlerts(config Config, alerts []AlertEntry) error {
+	alerts = DetectCorrelation(alerts, config.CorrelationGroups)
+	for i := range alerts {
+		alerts[i].Unit = alertUnit(alerts[i].Metric, config.TemperatureUnit)
+		if config.AlertTemplate == "" {
+			continue
+		}
+		message, err := RenderAlertMessage(config.AlertTemplate, alerts[i])
+		if err != nil {
+			return err
+		}
+		alerts[i].Message = message
+	}
+
+	for _, alert := range alerts {
+		switch {
+		case strings.TrimSpace(alert.Message) == "":
+			return fmt.Errorf("the %s alert renders to an empty message", alert.Metric)
+		case strings.Contains(alert.Message, "<no value>"):
+			return fmt.Errorf("the %s alert refers to an unknown template field: %q", alert.Metric, alert.Message)
+		case strings.Contains(alert.Message, "%!"):
+			return fmt.Errorf("the %s alert has a formatting error: %q", alert.Metric, alert.Message)
+		}
+	}
+
+	group := AlertGroup{Alerts: alerts, Window: alertGroupWindow(config)}
+	payload := AlertPayload{Message: group.Subject(), Description: FormatAlertMessage(group.Alerts), Severity: highestSeverity(group.Alerts), Alerts: group.Alerts}
+	if payload.Message == "" || payload.Description == "" {
+		return fmt.Errorf("the alert email has an empty subject or body")
+	}
+	for _, alert := range alerts {
+		if !strings.Contains(payload.Description, alert.Message) {
+			return fmt.Errorf("the alert email is missing the %s alert", alert.Metric)
+		}
+	}
+	return nil
+}
+
+// PrintSelfTestSummary writes one PASS or FAIL line per result to w and reports whether all passed
+func PrintSelfTestSummary(w io.Writer, results []SelfTestResult) bool {
+	passed := true
+	for _, result := range results {
+		if result.Err != nil {
+			passed = false
+			fmt.Fprintf(w, "FAIL  %s: %v\n", result.Name, result.Err)
+		} else {
+			fmt.Fprintf(w, "PASS  %s\n", result.Name)
+		}
+	}
+	return passed
+}
@@ -0,0 +1,24 @@
+package monitor
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func TestSelfTestEvaluatesEveryMetric(t *testing.T) {
+	orig := StatusOutput
+	StatusOutput = io.Discard
+	t.Cleanup(func() { StatusOutput = orig })
+
+	// The SMTP channel check after these steps fails without a server
+	results := RunSelfTest(context.Background(), Config{AlertTemplate: "{{.Metric}}: {{.Message}}"})
+	if len(results) < 2 || results[0].Name != "thresholds" || results[1].Name != "formatting" {
+		t.Fatalf("expected the thresholds and formatting steps first, got %v", results)
+	}
+	for _, result := range results[:2] {
+		if result.Err != nil {
+			t.Errorf("%s: %v", result.Name, result.Err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+//go:build integration
+
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+)
+
+// mailhog is the MailHog container started by TestMain
+var mailhog struct {
+	smtpPort string
+	apiURL   string
+}
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err == nil {
+		err = pool.Client.Ping()
+	}
+	if err != nil {
+		fmt.Printf("Skipping integration tests: Docker is not available: %v\n", err)
+		os.Exit(0)
+	}
+
+	resource, err := pool.Run("mailhog/mailhog", "latest", nil)
+	if err != nil {
+		log.Fatalf("Could not start MailHog: %v\n", err)
+	}
+	// Remove the container even if the tests hang
+	resource.Expire(300)
+
+	mailhog.smtpPort = resource.GetPort("1025/tcp")
+	mailhog.apiURL = "http://localhost:" + resource.GetPort("8025/tcp") + "/api/v2/messages"
+	if err := pool.Retry(func() error {
+		_, err := fetchMailHogMessages()
+		return err
+	}); err != nil {
+		pool.Purge(resource)
+		log.Fatalf("MailHog did not start: %v\n", err)
+	}
+
+	code := m.Run()
+	pool.Purge(resource)
+	os.Exit(code)
+}
+
+// mailHogMessage is a message of the MailHog v2 API
+type mailHogMessage struct {
+	Raw struct {
+		From string   `json:"From"`
+		To   []string `json:"To"`
+	} `json:"Raw"`
+	Content struct {
+		Headers map[string][]string `json:"Headers"`
+		Body    string              `json:"Body"`
+	} `json:"Content"`
+}
+
+// fetchMailHogMessages returns the messages received by MailHog, newest first
+func fetchMailHogMessages() ([]mailHogMessage, error) {
+	resp, err := http.Get(mailhog.apiURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("MailHog returned %s: %s", resp.Status, body)
+	}
+
+	var result struct {
+		Items []mailHogMessage `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+func TestSendEmailMailHog(t *testing.T) {
+	config := SMTPConfig{
+		SMTPHost:      "localhost",
+		SMTPPort:      mailhog.smtpPort,
+		FromEmail:     "monitor@example.com",
+		EmailPassword: "unused",
+		ToEmail:       "ops@example.com",
+	}
+	client := NewSMTPClient(config, nil)
+	defer client.Close()
+
+	subject := "System Alert: Resource Usage Exceeded"
+	body := "Alert: Disk usage is above 50%: 93.20%"
+	if err := sendEmail(context.Background(), client, subject, body); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := fetchMailHogMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) == 0 {
+		t.Fatal("MailHog received no message")
+	}
+	msg := messages[0]
+
+	if got := msg.Content.Headers["Subject"]; len(got) != 1 || got[0] != subject {
+		t.Errorf("expected subject %q, got %q", subject, got)
+	}
+	if msg.Raw.From != config.FromEmail {
+		t.Errorf("expected sender %q, got %q", config.FromEmail, msg.Raw.From)
+	}
+	if len(msg.Raw.To) != 1 || msg.Raw.To[0] != config.ToEmail {
+		t.Errorf("expected recipient %q, got %q", config.ToEmail, msg.Raw.To)
+	}
+	if !strings.Contains(msg.Content.Body, body) {
+		t.Errorf("expected the body to contain %q, got %q", body, msg.Content.Body)
+	}
+}
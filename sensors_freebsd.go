@@ -0,0 +1,47 @@
+//go:build freebsd
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// cpuTemperatureSysctls are tried in order: the coretemp/amdtemp driver first, then the ACPI thermal zone
+var cpuTemperatureSysctls = []string{"dev.cpu.0.temperature", "hw.acpi.thermal.tz0.temperature"}
+
+// GetCPUTemperature reads the CPU temperature from sysctl on FreeBSD
+func GetCPUTemperature(ctx context.Context) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var lastErr error
+	for _, name := range cpuTemperatureSysctls {
+		// Temperatures are reported in tenths of a Kelvin
+		deciKelvin, err := unix.SysctlUint32(name)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return (float64(deciKelvin) - 2731.5) / 10, nil
+	}
+	return 0, fmt.Errorf("Error fetching CPU temperature: %w (load the coretemp or amdtemp kernel module)", lastErr)
+}
+
+// GetFanSpeeds reads the fan speed of ThinkPads from the acpi_ibm driver. The result uses
+// the 'sensors' output format so it is handled like the Linux fan speeds.
+func GetFanSpeeds(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	rpm, err := unix.SysctlUint32("dev.acpi_ibm.0.fan_speed")
+	if err != nil {
+		// Other machines do not expose their fans through sysctl
+		return "", nil
+	}
+	return fmt.Sprintf("fan1: %d RPM\n", rpm), nil
+}
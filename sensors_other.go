@@ -0,0 +1,84 @@
+//go:build !freebsd
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+)
+
+// GetCPUTemperature uses the 'sensors' command for Linux to fetch CPU temperature
+//func GetCPUTemperature() (float64, error) {
+//	// Run the 'sensors' command
+//	cmd := exec.Command("osx-cpu-temp") //for mac  brew install osx-cpu-temp
+//
+//	//for linux lm-sensors
+//	//for windows wmic
+//	output, err := cmd.Output()
+//	if err != nil {
+//		return 0, fmt.Errorf("Error fetching CPU temperature: %w", err)
+//	}
+//
+//	// Parse the output to find the temperature
+//	for _, line := range strings.Split(string(output), "\n") {
+//		if strings.Contains(line, "Core 0") {
+//			// Example: Core 0:      +45.0°C  (high = +80.0°C, crit = +100.0°C)
+//			parts := strings.Fields(line)
+//			if len(parts) > 1 {
+//				// Convert temperature to float64
+//				var temp float64
+//				_, err := fmt.Sscanf(parts[1], "%f", &temp)
+//				if err == nil {
+//					return temp, nil
+//				}
+//			}
+//		}
+//	}
+//
+//	return 0, fmt.Errorf("could not find CPU temperature")
+//}
+
+// GetCPUTemperature uses the 'osx-cpu-temp' command for macOS to fetch CPU temperature
+func GetCPUTemperature(ctx context.Context) (float64, error) {
+	// Run the 'osx-cpu-temp' command
+	cmd := exec.CommandContext(ctx, "osx-cpu-temp")
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		// Without osx-cpu-temp (e.g. on Linux ARM boards), fall back to the kernel thermal zone
+		if errors.Is(err, exec.ErrNotFound) {
+			if temp, zoneErr := getThermalZoneTemperature(); zoneErr == nil {
+				return temp, nil
+			}
+		}
+		return 0, fmt.Errorf("Error fetching CPU temperature: %w", err)
+	}
+
+	// Print the raw output for debugging
+	fmt.Fprintf(StatusOutput, "Raw output: %s\n", string(output))
+
+	// Proceed with parsing the output
+	var temp float64
+	_, err = fmt.Sscanf(string(output), "+%f°C", &temp)
+	if err != nil {
+		return 0, fmt.Errorf("Error parsing CPU temperature: %w", err)
+	}
+
+	return temp, nil
+
+}
+
+// getThermalZoneTemperature reads the first kernel thermal zone in °C, which on Raspberry Pi
+// and most other ARM boards is the SoC temperature
+func getThermalZoneTemperature() (float64, error) {
+	milliC, err := readSysfsUint(filepath.Join(sysfsRoot, "class", "thermal", "thermal_zone0", "temp"))
+	if err != nil {
+		return 0, fmt.Errorf("Error reading thermal zone temperature: %w", err)
+	}
+	return float64(milliC) / 1000, nil
+}
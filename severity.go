@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity is the urgency of an alert, passed on to the alert channels
+type Severity string
+
+// Alert severities, from the most to the least urgent
+const (
+	SeverityCritical Severity = "critical"
+	SeverityError    Severity = "error"
+	SeverityWarning  Severity = "warning"
+	SeverityInfo     Severity = "info"
+)
+
+// rank orders the severities; unknown ones rank like info
+func (s Severity) rank() int {
+	switch s {
+	case SeverityCritical:
+		return 3
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// PriorityRule overrides the severity of alerts raised during some hours of the week, e.g.
+// {"from_hour": 9, "to_hour": 17, "days": ["mon", "tue", "wed", "thu", "fri"], "severity_override": "info"}
+// to downgrade alerts during business hours, when batch jobs run and someone is watching anyway
+type PriorityRule struct {
+	FromHour         int      `json:"from_hour"` // local hour the rule starts at, 0-23
+	ToHour           int      `json:"to_hour"`   // local hour the rule ends before, 1-24; below from_hour wraps past midnight
+	Days             []string `json:"days"`      // mon to sun; empty is every day
+	SeverityOverride Severity `json:"severity_override"`
+}
+
+// weekdays maps the days of a PriorityRule to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Matches reports whether the rule covers the local time t
+func (r PriorityRule) Matches(t time.Time) bool {
+	t = t.Local()
+	if len(r.Days) > 0 {
+		dayMatches := false
+		for _, day := range r.Days {
+			if weekday, ok := weekdays[strings.ToLower(day)]; ok && weekday == t.Weekday() {
+				dayMatches = true
+			}
+		}
+		if !dayMatches {
+			return false
+		}
+	}
+	hour := t.Hour()
+	if r.FromHour <= r.ToHour {
+		return hour >= r.FromHour && hour < r.ToHour
+	}
+	return hour >= r.FromHour || hour < r.ToHour
+}
+
+// alertSeverity returns the severity of the alert, or the default for threshold alerts
+func alertSeverity(alert AlertEntry) Severity {
+	if alert.Severity == "" {
+		return defaultSeverity
+	}
+	return alert.Severity
+}
+
+// GetEffectiveSeverity returns the severity of the alert at now: that of the first rule matching
+// now, or its own when none does. Critical alerts are never downgraded.
+func GetEffectiveSeverity(alert AlertEntry, rules []PriorityRule, now time.Time) Severity {
+	severity := alertSeverity(alert)
+	if severity == SeverityCritical {
+		return severity
+	}
+	for _, rule := range rules {
+		if rule.Matches(now) {
+			return rule.SeverityOverride
+		}
+	}
+	return severity
+}
+
+// highestSeverity returns the most urgent severity of the alerts, used for a group sent together
+func highestSeverity(alerts []AlertEntry) Severity {
+	highest := defaultSeverity
+	for i, alert := range alerts {
+		if severity := alertSeverity(alert); i == 0 || severity.rank() > highest.rank() {
+			highest = severity
+		}
+	}
+	return highest
+}
+
+// validatePriorityRules checks the time_based_priority config
+func validatePriorityRules(rules []PriorityRule) error {
+	for i, rule := range rules {
+		if rule.FromHour < 0 || rule.FromHour > 23 || rule.ToHour < 1 || rule.ToHour > 24 || rule.FromHour == rule.ToHour {
+			return fmt.Errorf("invalid time_based_priority rule %d: from_hour must be 0-23 and to_hour 1-24, and they must differ", i+1)
+		}
+		for _, day := range rule.Days {
+			if _, ok := weekdays[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("invalid time_based_priority rule %d: unknown day %q, must be mon, tue, wed, thu, fri, sat or sun", i+1, day)
+			}
+		}
+		switch rule.SeverityOverride {
+		case SeverityCritical, SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			return fmt.Errorf("invalid time_based_priority rule %d: severity_override %q must be critical, error, warning or info", i+1, rule.SeverityOverride)
+		}
+	}
+	return nil
+}
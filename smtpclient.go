@@ -0,0 +1,208 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPClient sends emails over persistent SMTP connections instead of dialing
+// the server for every message. At most maxConnections connections are open at
+// once; broken connections are replaced transparently.
+type SMTPClient struct {
+	config SMTPConfig
+	footer *EmailFooter      // appended to every body; nil for none
+	slots  chan struct{}     // one token per connection that may be in use
+	idle   chan *smtp.Client // open connections ready for reuse
+}
+
+// NewSMTPClient creates an SMTPClient for config. Connections are opened lazily on the first Send.
+func NewSMTPClient(config SMTPConfig, footer *EmailFooter) *SMTPClient {
+	maxConnections := config.SMTPMaxConnections
+	if maxConnections <= 0 {
+		maxConnections = 1
+	}
+	return &SMTPClient{
+		config: config,
+		footer: footer,
+		slots:  make(chan struct{}, maxConnections),
+		idle:   make(chan *smtp.Client, maxConnections),
+	}
+}
+
+// Send delivers msg to the configured recipient
+func (c *SMTPClient) Send(msg *EmailMessage) error {
+	c.slots <- struct{}{}
+	defer func() { <-c.slots }()
+
+	withFooter := *msg
+	if c.footer != nil {
+		if footer := c.footer.Render(); footer != "" {
+			withFooter.Body += "\n\n-- \n" + footer
+		}
+	}
+	message, err := withFooter.Bytes(c.config.CC)
+	if err != nil {
+		return err
+	}
+
+	// Reuse an idle connection if the server still answers on it
+	select {
+	case conn := <-c.idle:
+		if err := conn.Noop(); err == nil {
+			if err := c.deliver(conn, message); err == nil {
+				c.idle <- conn
+				return nil
+			}
+		}
+		conn.Close()
+	default:
+	}
+
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	if err := c.deliver(conn, message); err != nil {
+		conn.Close()
+		return err
+	}
+	c.idle <- conn
+	return nil
+}
+
+// Close quits all idle connections
+func (c *SMTPClient) Close() {
+	for {
+		select {
+		case conn := <-c.idle:
+			conn.Quit()
+		default:
+			return
+		}
+	}
+}
+
+// dial opens and authenticates a new connection, with OAuth2 when oauth2_token_file is configured
+func (c *SMTPClient) dial() (*smtp.Client, error) {
+	if c.config.OAuth2TokenFile != "" {
+		return NewOAuth2SMTPClient(c.config)
+	}
+
+	conn, err := dialSMTP(c.config)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok, _ := conn.Extension("AUTH"); ok {
+		auth := smtp.PlainAuth("", c.config.FromEmail, c.config.EmailPassword, c.config.SMTPHost)
+		if err := conn.Auth(auth); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("Error authenticating with SMTP server: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// smtpTimeout bounds connecting to the SMTP server and every read or write on an open connection
+const smtpTimeout = 30 * time.Second
+
+// deadlineConn pushes the deadline of the connection forward before every read and write, so a
+// pooled connection never hangs on an unresponsive server but can stay idle between messages
+type deadlineConn struct {
+	net.Conn
+}
+
+func (c deadlineConn) Read(b []byte) (int, error) {
+	c.SetDeadline(time.Now().Add(smtpTimeout))
+	return c.Conn.Read(b)
+}
+
+func (c deadlineConn) Write(b []byte) (int, error) {
+	c.SetDeadline(time.Now().Add(smtpTimeout))
+	return c.Conn.Write(b)
+}
+
+// dialSMTP connects to the SMTP server, upgrading the connection to TLS when the server supports it
+func dialSMTP(config SMTPConfig) (*smtp.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), smtpTimeout)
+	defer cancel()
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(config.SMTPHost, config.SMTPPort))
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting to SMTP server: %w", err)
+	}
+
+	conn, err := smtp.NewClient(deadlineConn{netConn}, config.SMTPHost)
+	if err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("Error connecting to SMTP server: %w", err)
+	}
+
+	if ok, _ := conn.Extension("STARTTLS"); ok {
+		if err := conn.StartTLS(&tls.Config{ServerName: config.SMTPHost}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("Error starting TLS: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// deliver sends one message over an open connection
+func (c *SMTPClient) deliver(conn *smtp.Client, message []byte) error {
+	if err := conn.Mail(c.config.FromEmail); err != nil {
+		return fmt.Errorf("Error setting sender: %w", err)
+	}
+	if err := conn.Rcpt(c.config.ToEmail); err != nil {
+		return fmt.Errorf("Error setting recipient: %w", err)
+	}
+	// BCC recipients only appear in the envelope, never in the headers
+	for _, rcpt := range append(append([]string{}, c.config.CC...), c.config.BCC...) {
+		if err := conn.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("Error setting recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := conn.Data()
+	if err != nil {
+		return fmt.Errorf("Error starting message: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		w.Close()
+		return fmt.Errorf("Error writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("Error sending message: %w", err)
+	}
+	return nil
+}
+
+// buildEmailMessage formats the subject and Cc headers and body of an alert email
+func buildEmailMessage(subject, body string, cc []string) []byte {
+	headers := "Subject: " + subject + "\n"
+	if len(cc) > 0 {
+		headers += "Cc: " + strings.Join(cc, ", ") + "\n"
+	}
+	return []byte(headers + "\n" + body)
+}
+
+// validateEmailAddresses rejects malformed addresses in the named config list
+func validateEmailAddresses(field string, addresses []string) error {
+	for _, address := range addresses {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return fmt.Errorf("invalid %s address %q: %w", field, address, err)
+		}
+		// Names are not supported since the addresses are also used in the SMTP envelope
+		if parsed.Address != address {
+			return fmt.Errorf("invalid %s address %q: expected a plain address like ops@example.com", field, address)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/smtp"
+	"os"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/microsoft"
+)
+
+// OAuth2 scopes that allow sending mail over SMTP
+const (
+	googleSMTPScope    = "https://mail.google.com/"
+	microsoftSMTPScope = "https://outlook.office.com/SMTP.Send"
+)
+
+// OAuth2TokenFile is the content of the oauth2_token_file: the OAuth2 client and the
+// refresh token obtained when the mailbox owner granted access
+type OAuth2TokenFile struct {
+	Provider     string `json:"provider"`  // google or microsoft
+	TenantID     string `json:"tenant_id"` // Microsoft Entra tenant; defaults to "common"
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// oauth2Config returns the OAuth2 client config of the token file's provider
+func (f OAuth2TokenFile) oauth2Config() (*oauth2.Config, error) {
+	config := &oauth2.Config{ClientID: f.ClientID, ClientSecret: f.ClientSecret}
+	switch f.Provider {
+	case "google":
+		config.Endpoint = google.Endpoint
+		config.Scopes = []string{googleSMTPScope}
+	case "microsoft":
+		tenant := f.TenantID
+		if tenant == "" {
+			tenant = "common"
+		}
+		config.Endpoint = microsoft.AzureADEndpoint(tenant)
+		config.Scopes = []string{microsoftSMTPScope, "offline_access"}
+	default:
+		return nil, fmt.Errorf("unknown OAuth2 provider %q (expected google or microsoft)", f.Provider)
+	}
+	return config, nil
+}
+
+// smtpTokenSources caches a token source per token file so access tokens are reused until they expire
+var smtpTokenSources = struct {
+	sync.Mutex
+	sources map[string]oauth2.TokenSource
+}{sources: make(map[string]oauth2.TokenSource)}
+
+// smtpTokenSource returns the cached token source of the token file at path
+func smtpTokenSource(path string) (oauth2.TokenSource, error) {
+	smtpTokenSources.Lock()
+	defer smtpTokenSources.Unlock()
+	if source, ok := smtpTokenSources.sources[path]; ok {
+		return source, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read OAuth2 token file: %w", err)
+	}
+	var tokenFile OAuth2TokenFile
+	if err := json.Unmarshal(data, &tokenFile); err != nil {
+		return nil, fmt.Errorf("could not parse OAuth2 token file: %w", err)
+	}
+	if tokenFile.RefreshToken == "" {
+		return nil, fmt.Errorf("OAuth2 token file %s has no refresh_token", path)
+	}
+	config, err := tokenFile.oauth2Config()
+	if err != nil {
+		return nil, err
+	}
+
+	source := oauth2.ReuseTokenSource(nil, config.TokenSource(context.Background(), &oauth2.Token{RefreshToken: tokenFile.RefreshToken}))
+	smtpTokenSources.sources[path] = source
+	return source, nil
+}
+
+// NewOAuth2SMTPClient connects to the SMTP server and authenticates with the XOAUTH2
+// mechanism, exchanging the refresh token of cfg.OAuth2TokenFile for an access token
+func NewOAuth2SMTPClient(cfg SMTPConfig) (*smtp.Client, error) {
+	source, err := smtpTokenSource(cfg.OAuth2TokenFile)
+	if err != nil {
+		return nil, err
+	}
+	token, err := source.Token()
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching OAuth2 access token: %w", err)
+	}
+
+	conn, err := dialSMTP(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Auth(&xoauth2Auth{username: cfg.FromEmail, accessToken: token.AccessToken}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Error authenticating with SMTP server: %w", err)
+	}
+	return conn, nil
+}
+
+// xoauth2Auth implements the XOAUTH2 SASL mechanism used by Gmail and Outlook
+type xoauth2Auth struct {
+	username    string
+	accessToken string
+}
+
+// Start sends the initial XOAUTH2 response. The access token is only sent over TLS.
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		return "", nil, errors.New("refusing to send the OAuth2 access token over an unencrypted connection")
+	}
+	return "XOAUTH2", []byte("user=" + a.username + "\x01auth=Bearer " + a.accessToken + "\x01\x01"), nil
+}
+
+// Next answers the error challenge of a rejected token with an empty response, after which
+// the server reports the failure
+func (a *xoauth2Auth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if more {
+		return []byte{}, nil
+	}
+	return nil, nil
+}
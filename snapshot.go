@@ -0,0 +1,200 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// MetricSnapshot holds the values collected during one monitoring cycle
+type MetricSnapshot struct {
+	Timestamp          time.Time            `json:"timestamp"`
+	Thresholds         Thresholds           `json:"-"`                // usage thresholds in effect when the snapshot was taken
+	TemperatureUnit    TempUnit             `json:"-"`                // unit of the temperatures in the output; the fields hold °C
+	SocketMaxTempC     float64              `json:"-"`                // socket_max_temp_c in effect; 0 when the per-socket check is off
+	CollectedAt        map[string]time.Time `json:"-"`                // last collection of each scheduled metric
+	Failed             map[string]bool      `json:"failed,omitempty"` // metrics whose last collection failed, e.g. cpu_temperature or cron.backup
+	CPUTemperature     float64              `json:"cpu_temperature_c"`
+	SocketTemperatures map[int]float64      `json:"cpu_socket_temperatures_c,omitempty"`
+	AmbientTemperature *float64             `json:"ambient_temperature_c,omitempty"`
+	GPUTemperature     *float64             `json:"gpu_temperature_c,omitempty"`
+	GPIOTemperatures   []GPIOTempStat       `json:"gpio_temperatures,omitempty"`
+	ThermalSensors     []ThermalSensorStat  `json:"thermal_sensors,omitempty"`
+	FanSpeeds          string               `json:"fan_speeds,omitempty"`
+	CPUClockSpeeds     []float64            `json:"cpu_clock_speeds_ghz"`
+	CPUUsage           []float64            `json:"cpu_usage_percent"`
+	CPUPower           []RAPLDomain         `json:"cpu_power,omitempty"`
+	CPUThrottle        []ThrottleStat       `json:"cpu_throttle,omitempty"`
+	EDAC               *EDACStats           `json:"edac,omitempty"`
+	IPMI               []IPMISensor         `json:"ipmi,omitempty"`
+	MemoryUsedPercent  float64              `json:"memory_used_percent"`
+	MemBandwidth       *MemBandwidthStat    `json:"memory_bandwidth,omitempty"`
+	HugePages          *HugePageStat        `json:"hugepages,omitempty"`
+	KSM                *KSMStat             `json:"ksm,omitempty"`
+	DiskUsedPercent    float64              `json:"disk_used_percent"`
+	Tmpfs              []TmpfsStat          `json:"tmpfs,omitempty"`
+	DiskLatency        []DiskLatencyStat    `json:"disk_latency,omitempty"`
+	PathSizes          []PathSizeStat       `json:"file_size,omitempty"`
+	DiskQuotas         []QuotaEntry         `json:"disk_quota,omitempty"`
+	MAC                *MACStatus           `json:"mac,omitempty"`
+	DNS                []DNSStat            `json:"dns,omitempty"`
+	Traceroute         []TracerouteStat     `json:"traceroute,omitempty"`
+	NICDrops           []NICPacketStat      `json:"nic_drops,omitempty"`
+	CiliumDrops        *CiliumDropStat      `json:"cilium_drops,omitempty"`
+	CT                 []CTInfo             `json:"ct,omitempty"`
+	Cron               []CronStat           `json:"cron,omitempty"`
+	Elasticsearch      []ESHealth           `json:"elasticsearch,omitempty"`
+	Postgres           []PGStat             `json:"postgres,omitempty"`
+	Redis              []RedisStat          `json:"redis,omitempty"`
+	RedisSentinels     []SentinelStat       `json:"redis_sentinels,omitempty"`
+	Mongo              []MongoStat          `json:"mongo,omitempty"`
+	RabbitMQ           []QueueStat          `json:"rabbitmq,omitempty"`
+	Kafka              []PartitionLag       `json:"kafka,omitempty"`
+	MySQL              []MySQLStat          `json:"mysql,omitempty"`
+	RDS                []RDSStat            `json:"rds,omitempty"`
+	Consul             []ConsulServiceStat  `json:"consul,omitempty"`
+	Vault              []VaultStat          `json:"vault,omitempty"`
+	Etcd               []EtcdStat           `json:"etcd,omitempty"`
+	HAProxy            []BackendStat        `json:"haproxy,omitempty"`
+	Nginx              []NginxStat          `json:"nginx,omitempty"`
+	Apache             []ApacheStat         `json:"apache,omitempty"`
+	Processes          []ProcessCheckResult `json:"processes,omitempty"`
+}
+
+// SafeSnapshot shares the latest MetricSnapshot between the collector and its readers.
+// A snapshot must not be modified after it has been passed to Set; readers then share
+// its slices instead of deep-copying them on every Get.
+type SafeSnapshot struct {
+	mu   sync.RWMutex
+	snap MetricSnapshot
+}
+
+// Set replaces the current snapshot
+func (s *SafeSnapshot) Set(snap MetricSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snap = snap
+}
+
+// Merge replaces the values of the collected metrics in the current snapshot,
+// keeping those of metrics that are scheduled on other intervals
+func (s *SafeSnapshot) Merge(snap MetricSnapshot, collected MetricSet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	merged := s.snap
+	merged.Timestamp = snap.Timestamp
+	merged.Thresholds = snap.Thresholds
+	merged.TemperatureUnit = snap.TemperatureUnit
+	merged.SocketMaxTempC = snap.SocketMaxTempC
+	merged.CollectedAt = make(map[string]time.Time, len(s.snap.CollectedAt)+len(collected))
+	for metric, at := range s.snap.CollectedAt {
+		merged.CollectedAt[metric] = at
+	}
+	merged.Failed = make(map[string]bool, len(s.snap.Failed)+len(snap.Failed))
+	for metric := range s.snap.Failed {
+		if !collected.Has(scheduledMetric(metric)) {
+			merged.Failed[metric] = true
+		}
+	}
+	for metric := range snap.Failed {
+		merged.Failed[metric] = true
+	}
+	for metric := range collected {
+		merged.CollectedAt[metric] = snap.Timestamp
+		switch metric {
+		case "cpu_temperature":
+			merged.CPUTemperature = snap.CPUTemperature
+			merged.SocketTemperatures = snap.SocketTemperatures
+			merged.AmbientTemperature = snap.AmbientTemperature
+		case "gpu_temperature":
+			merged.GPUTemperature = snap.GPUTemperature
+		case "gpio_temperature":
+			merged.GPIOTemperatures = snap.GPIOTemperatures
+		case "thermal_sensors":
+			merged.ThermalSensors = snap.ThermalSensors
+		case "fan_speed":
+			merged.FanSpeeds = snap.FanSpeeds
+		case "cpu_clock":
+			merged.CPUClockSpeeds = snap.CPUClockSpeeds
+		case "cpu_usage":
+			merged.CPUUsage = snap.CPUUsage
+		case "cpu_power":
+			merged.CPUPower = snap.CPUPower
+		case "cpu_throttle":
+			merged.CPUThrottle = snap.CPUThrottle
+		case "edac":
+			merged.EDAC = snap.EDAC
+		case "ipmi":
+			merged.IPMI = snap.IPMI
+		case "memory":
+			merged.MemoryUsedPercent = snap.MemoryUsedPercent
+			merged.KSM = snap.KSM
+		case "memory_bandwidth":
+			merged.MemBandwidth = snap.MemBandwidth
+		case "hugepages":
+			merged.HugePages = snap.HugePages
+		case "disk":
+			merged.DiskUsedPercent = snap.DiskUsedPercent
+		case "tmpfs":
+			merged.Tmpfs = snap.Tmpfs
+		case "disk_latency":
+			merged.DiskLatency = snap.DiskLatency
+		case "file_size":
+			merged.PathSizes = snap.PathSizes
+		case "disk_quota":
+			merged.DiskQuotas = snap.DiskQuotas
+		case "mac":
+			merged.MAC = snap.MAC
+		case "dns":
+			merged.DNS = snap.DNS
+		case "traceroute":
+			merged.Traceroute = snap.Traceroute
+		case "nic":
+			merged.NICDrops = snap.NICDrops
+		case "cilium":
+			merged.CiliumDrops = snap.CiliumDrops
+		case "ct":
+			merged.CT = snap.CT
+		case "cron":
+			merged.Cron = snap.Cron
+		case "elasticsearch":
+			merged.Elasticsearch = snap.Elasticsearch
+		case "postgres":
+			merged.Postgres = snap.Postgres
+		case "redis":
+			merged.Redis = snap.Redis
+			merged.RedisSentinels = snap.RedisSentinels
+		case "mongo":
+			merged.Mongo = snap.Mongo
+		case "rabbitmq":
+			merged.RabbitMQ = snap.RabbitMQ
+		case "kafka":
+			merged.Kafka = snap.Kafka
+		case "mysql":
+			merged.MySQL = snap.MySQL
+		case "rds":
+			merged.RDS = snap.RDS
+		case "consul":
+			merged.Consul = snap.Consul
+		case "vault":
+			merged.Vault = snap.Vault
+		case "etcd":
+			merged.Etcd = snap.Etcd
+		case "haproxy":
+			merged.HAProxy = snap.HAProxy
+		case "nginx":
+			merged.Nginx = snap.Nginx
+		case "apache":
+			merged.Apache = snap.Apache
+		case "process":
+			merged.Processes = snap.Processes
+		}
+	}
+	s.snap = merged
+}
+
+// Get returns the current snapshot
+func (s *SafeSnapshot) Get() MetricSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snap
+}
@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// snmpTrapOIDVar is the varbind carrying the trap OID of SNMPv2c traps (snmpTrapOID.0)
+const snmpTrapOIDVar = "1.3.6.1.6.3.1.1.4.1.0"
+
+// defaultSNMPAlertOIDs are alerted on when no alert_oids are configured
+var defaultSNMPAlertOIDs = []string{
+	"1.3.6.1.6.3.1.1.5.3", // linkDown
+}
+
+// SNMPTrapConfig is the snmp_traps config section
+type SNMPTrapConfig struct {
+	ListenAddr string   `json:"listen_addr"` // e.g. ":162" (binding below 1024 needs root or CAP_NET_BIND_SERVICE)
+	Community  string   `json:"community"`
+	AlertOIDs  []string `json:"alert_oids"` // trap OIDs that trigger an alert; defaults to linkDown
+}
+
+// SNMPTrap is a trap received from a network device
+type SNMPTrap struct {
+	Source    string            `json:"source"`
+	OID       string            `json:"oid"` // trap OID without leading dot
+	Variables map[string]string `json:"variables"`
+	Received  time.Time         `json:"received"`
+}
+
+// StartSNMPTrapReceiver listens for SNMPv1/v2c traps on addr and publishes those
+// sent with the given community to ch. It blocks until the listener fails.
+func StartSNMPTrapReceiver(addr string, community string, ch chan<- SNMPTrap) error {
+	listener := gosnmp.NewTrapListener()
+	listener.Params = gosnmp.Default
+	listener.OnNewTrap = func(packet *gosnmp.SnmpPacket, source *net.UDPAddr) {
+		if packet.Community != community {
+			log.Printf("Ignoring SNMP trap from %s with wrong community\n", source.IP)
+			return
+		}
+		ch <- parseSNMPTrap(packet, source)
+	}
+
+	if err := listener.Listen(addr); err != nil {
+		return fmt.Errorf("Error listening for SNMP traps: %w", err)
+	}
+	return nil
+}
+
+// parseSNMPTrap extracts the trap OID and variables from a trap packet
+func parseSNMPTrap(packet *gosnmp.SnmpPacket, source *net.UDPAddr) SNMPTrap {
+	trap := SNMPTrap{
+		Source:    source.IP.String(),
+		Variables: make(map[string]string),
+		Received:  time.Now(),
+	}
+
+	for _, v := range packet.Variables {
+		name := strings.TrimPrefix(v.Name, ".")
+		value := fmt.Sprint(v.Value)
+		if b, ok := v.Value.([]byte); ok {
+			value = string(b)
+		}
+		if name == snmpTrapOIDVar {
+			trap.OID = strings.TrimPrefix(value, ".")
+			continue
+		}
+		trap.Variables[name] = value
+	}
+
+	// SNMPv1 traps carry the OID in the trap header (RFC 3584 section 3.1)
+	if packet.Version == gosnmp.Version1 {
+		enterprise := strings.TrimPrefix(packet.Enterprise, ".")
+		if packet.GenericTrap == 6 {
+			trap.OID = fmt.Sprintf("%s.0.%d", enterprise, packet.SpecificTrap)
+		} else {
+			trap.OID = fmt.Sprintf("1.3.6.1.6.3.1.1.5.%d", packet.GenericTrap+1)
+		}
+	}
+
+	return trap
+}
+
+// IsAlertOID reports whether traps with this OID should raise an alert
+func (c SNMPTrapConfig) IsAlertOID(oid string) bool {
+	alertOIDs := c.AlertOIDs
+	if len(alertOIDs) == 0 {
+		alertOIDs = defaultSNMPAlertOIDs
+	}
+	for _, alertOID := range alertOIDs {
+		if strings.TrimPrefix(alertOID, ".") == oid {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatSNMPTrapAlert builds the alert body for a received trap
+func FormatSNMPTrapAlert(trap SNMPTrap) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Alert: SNMP trap %s received from %s at %s\n", trap.OID, trap.Source, trap.Received.Format(time.RFC3339))
+
+	names := make([]string, 0, len(trap.Variables))
+	for name := range trap.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %s = %s\n", name, trap.Variables[name])
+	}
+	return b.String()
+}
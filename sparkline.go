@@ -0,0 +1,141 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"math"
+	"time"
+
+	"golang.org/x/image/vector"
+)
+
+const (
+	sparklineWidth     = 200
+	sparklineHeight    = 40
+	sparklineLineWidth = 1.5
+	emailChartWindow   = time.Hour // history shown in the charts of alert emails
+)
+
+var (
+	sparklineBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	sparklineLine       = color.RGBA{0x1f, 0x77, 0xb4, 0xff}
+	sparklineFill       = color.RGBA{0x1f, 0x77, 0xb4, 0x33} // premultiplied
+)
+
+// EmailChart is a sparkline of a metric embedded in an alert email
+type EmailChart struct {
+	Metric    string
+	Unit      string
+	ContentID string // referenced as cid: from the HTML body
+	PNG       []byte
+}
+
+// RenderSparkline renders the values, oldest first, as a PNG line chart scaled to their range
+func RenderSparkline(values []float64, width, height int) ([]byte, error) {
+	if len(values) < 2 {
+		return nil, fmt.Errorf("could not render sparkline: need at least 2 values, got %d", len(values))
+	}
+	if width < 2 || height < 2 {
+		return nil, fmt.Errorf("could not render sparkline: invalid size %dx%d", width, height)
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if hi == lo {
+		// Draw a constant metric as a flat line in the middle
+		lo, hi = lo-1, hi+1
+	}
+
+	// Keep the line inside the image at the extremes
+	pad := float32(sparklineLineWidth)
+	xs := make([]float32, len(values))
+	ys := make([]float32, len(values))
+	for i, v := range values {
+		xs[i] = pad + float32(i)*(float32(width)-2*pad)/float32(len(values)-1)
+		ys[i] = pad + float32((hi-v)/(hi-lo))*(float32(height)-2*pad)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(sparklineBackground), image.Point{}, draw.Src)
+
+	// Shade the area under the line
+	r := vector.NewRasterizer(width, height)
+	r.MoveTo(xs[0], float32(height))
+	for i := range xs {
+		r.LineTo(xs[i], ys[i])
+	}
+	r.LineTo(xs[len(xs)-1], float32(height))
+	r.ClosePath()
+	r.Draw(img, img.Bounds(), image.NewUniform(sparklineFill), image.Point{})
+
+	// Stroke the line as one quad per segment
+	r.Reset(width, height)
+	for i := 1; i < len(xs); i++ {
+		dx, dy := xs[i]-xs[i-1], ys[i]-ys[i-1]
+		length := float32(math.Hypot(float64(dx), float64(dy)))
+		nx, ny := -dy/length*sparklineLineWidth/2, dx/length*sparklineLineWidth/2
+		r.MoveTo(xs[i-1]+nx, ys[i-1]+ny)
+		r.LineTo(xs[i]+nx, ys[i]+ny)
+		r.LineTo(xs[i]-nx, ys[i]-ny)
+		r.LineTo(xs[i-1]-nx, ys[i-1]-ny)
+		r.ClosePath()
+	}
+	r.Draw(img, img.Bounds(), image.NewUniform(sparklineLine), image.Point{})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("could not encode sparkline: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderAlertCharts renders a sparkline of the last hour of every alerting metric with enough history.
+// Per-instance metrics like cpu_usage are charted by their highest instance.
+func renderAlertCharts(history *MetricStore, alerts []AlertEntry, now time.Time) []EmailChart {
+	var charts []EmailChart
+	seen := make(map[string]bool)
+	for _, alert := range alerts {
+		if seen[alert.Metric] {
+			continue
+		}
+		seen[alert.Metric] = true
+
+		samples, err := history.QueryMetric(alert.Metric, now.Add(-emailChartWindow), now)
+		if err != nil {
+			log.Printf("Error reading metric history: %v\n", err)
+			continue
+		}
+		if len(samples) == 0 {
+			continue
+		}
+		h := newMetricHistory(alert.Metric, samples, alert.Threshold)
+		timestamps := h.timestamps()
+		values := make([]float64, len(timestamps))
+		for i, t := range timestamps {
+			values[i] = h.values[t]
+		}
+		if len(values) < 2 {
+			continue
+		}
+
+		chart, err := RenderSparkline(values, sparklineWidth, sparklineHeight)
+		if err != nil {
+			log.Printf("Error rendering chart of %s: %v\n", alert.Metric, err)
+			continue
+		}
+		charts = append(charts, EmailChart{
+			Metric:    alert.Metric,
+			Unit:      h.unit,
+			ContentID: fmt.Sprintf("chart-%d@go-system-monitor", len(charts)),
+			PNG:       chart,
+		})
+	}
+	return charts
+}
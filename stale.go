@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultMaxConsecutiveFailures is used when max_consecutive_failures is not configured
+const defaultMaxConsecutiveFailures = 3
+
+// failureTracker counts the consecutive collection failures of each metric
+type failureTracker struct {
+	mu          sync.Mutex
+	failures    map[string]int
+	lastSuccess map[string]time.Time
+	startedAt   time.Time
+}
+
+// collectionFailures tracks the metrics collected by runChecks
+var collectionFailures = &failureTracker{
+	failures:    make(map[string]int),
+	lastSuccess: make(map[string]time.Time),
+	startedAt:   time.Now(),
+}
+
+// Success resets the failure counter of metric
+func (t *failureTracker) Success(metric string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failures[metric] = 0
+	t.lastSuccess[metric] = at
+}
+
+// Failure increments the failure counter of metric and returns it together with the
+// last successful collection (the monitor start if there was none)
+func (t *failureTracker) Failure(metric string) (int, time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failures[metric]++
+	lastSuccess, ok := t.lastSuccess[metric]
+	if !ok {
+		lastSuccess = t.startedAt
+	}
+	return t.failures[metric], lastSuccess
+}
+
+// Failing reports whether the last collection of metric failed
+func (t *failureTracker) Failing(metric string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failures[metric] > 0
+}
+
+// StaleMetricAlert reports a metric that has not been collected successfully since lastSuccess
+func StaleMetricAlert(metric string, failures int, lastSuccess time.Time, err error) AlertEntry {
+	return AlertEntry{
+		Metric: metric,
+		Value:  float64(failures),
+		Message: fmt.Sprintf("Alert: %s has not been collected for %s (%d consecutive failures): %v",
+			metric, time.Since(lastSuccess).Round(time.Second), failures, err),
+	}
+}
+
+// collectionOutcome is the result of collecting a metric whose failures are tracked
+type collectionOutcome struct {
+	Metric string
+	Err    error // nil when the collection succeeded
+}
+
+// recordCollections records the outcomes of a run in collectionFailures and returns the
+// StaleMetricAlerts of the metrics that keep failing
+func recordCollections(config Config, outcomes []collectionOutcome) []AlertEntry {
+	var alerts []AlertEntry
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			alerts = append(alerts, collectionFailed(config, outcome.Metric, outcome.Err)...)
+		} else {
+			collectionSucceeded(outcome.Metric)
+		}
+	}
+	return alerts
+}
+
+// collectionSucceeded records a successful collection of metric
+func collectionSucceeded(metric string) {
+	collectionFailures.Success(metric, time.Now())
+}
+
+// collectionFailed logs a failed collection of metric and returns a StaleMetricAlert once the metric
+// has failed max_consecutive_failures times in a row or has not been collected for max_staleness_seconds
+func collectionFailed(config Config, metric string, err error) []AlertEntry {
+	// Collections interrupted by a shutdown are not sensor dropouts
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	log.Printf("Error collecting %s: %v\n", metric, err)
+
+	failures, lastSuccess := collectionFailures.Failure(metric)
+	maxFailures := config.MaxConsecutiveFailures
+	if maxFailures <= 0 {
+		maxFailures = defaultMaxConsecutiveFailures
+	}
+	stale := config.MaxStalenessSeconds > 0 && time.Since(lastSuccess) >= time.Duration(config.MaxStalenessSeconds)*time.Second
+	if failures < maxFailures && !stale {
+		return nil
+	}
+	return []AlertEntry{StaleMetricAlert(metric, failures, lastSuccess, err)}
+}
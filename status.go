@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"html/template"
+	"io"
+	"os"
+	"time"
+)
+
+// statusRefreshSeconds is how often the status page reloads itself
+const statusRefreshSeconds = 30
+
+// statusPageTemplate renders the latest snapshot as a table with one status badge per metric
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>{{.Hostname}} - go-system-monitor</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+th, td { padding: 0.3em 1em; border-bottom: 1px solid #ddd; text-align: left; }
+td.value { text-align: right; font-family: monospace; }
+.badge { display: inline-block; padding: 0.1em 0.6em; border-radius: 0.8em; color: #fff; font-size: 0.85em; }
+.ok { background: #2e7d32; }
+.alert { background: #c62828; }
+.stale { background: #f9a825; color: #222; }
+footer { margin-top: 1.5em; color: #777; font-size: 0.85em; }
+</style>
+</head>
+<body>
+<h1>{{.Hostname}}</h1>
+{{if .Rows}}
+<table>
+<tr><th>Metric</th><th>Value</th><th>Unit</th><th>Status</th><th>Last checked</th></tr>
+{{range .Rows}}<tr><td>{{.Metric}}</td><td class="value">{{printf "%.2f" .Value}}</td><td>{{.Unit}}</td><td><span class="badge {{.Status}}">{{.Status}}</span></td><td>{{.CheckedAt}}</td></tr>
+{{end}}</table>
+{{else}}
+<p>No metrics collected yet.</p>
+{{end}}
+<footer>go-system-monitor {{.Version}} &middot; generated {{.GeneratedAt}} &middot; refreshes every {{.Refresh}}s</footer>
+</body>
+</html>
+`))
+
+// statusRow is a metric row of the status page
+type statusRow struct {
+	metricRow
+	CheckedAt string
+}
+
+// statusPage is the data of statusPageTemplate
+type statusPage struct {
+	Hostname    string
+	Version     string
+	GeneratedAt string
+	Refresh     int
+	Rows        []statusRow
+}
+
+// WriteStatusPage renders snap as a human-readable HTML page. Metrics whose last collection
+// failed are shown as stale with the values of their last successful collection.
+func WriteStatusPage(w io.Writer, snap MetricSnapshot) error {
+	hostname, _ := os.Hostname()
+	page := statusPage{
+		Hostname:    hostname,
+		Version:     Version,
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Refresh:     statusRefreshSeconds,
+	}
+	if !snap.Timestamp.IsZero() {
+		for _, row := range snapshotRows(snap) {
+			metric := scheduledMetric(row.Metric)
+			if collectionFailures.Failing(metric) {
+				row.Status = "stale"
+			}
+			checkedAt := snap.Timestamp
+			if at, ok := snap.CollectedAt[metric]; ok {
+				checkedAt = at
+			}
+			page.Rows = append(page.Rows, statusRow{metricRow: row, CheckedAt: checkedAt.Format(time.RFC3339)})
+		}
+	}
+	return statusPageTemplate.Execute(w, page)
+}
@@ -0,0 +1,236 @@
+package monitor
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// defaultSeverity is recorded for threshold alerts
+const defaultSeverity = SeverityWarning
+
+// Delivery status of a recorded alert
+const (
+	AlertStatusSent   = "sent"
+	AlertStatusFailed = "failed" // the alert email could not be delivered
+)
+
+// metricRetention is how long collected metric values are kept for analysis
+const metricRetention = 24 * time.Hour
+
+// metricStoreSchema creates the history tables. Timestamps are Unix milliseconds.
+const metricStoreSchema = `
+CREATE TABLE IF NOT EXISTS alerts (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp   INTEGER NOT NULL,
+	metric      TEXT    NOT NULL,
+	value       REAL    NOT NULL,
+	threshold   REAL    NOT NULL,
+	severity    TEXT    NOT NULL,
+	message     TEXT    NOT NULL,
+	notified    INTEGER NOT NULL,
+	resolved_at INTEGER,
+	status      TEXT    NOT NULL DEFAULT 'sent'
+);
+CREATE INDEX IF NOT EXISTS alerts_timestamp ON alerts (timestamp);
+CREATE TABLE IF NOT EXISTS metrics (
+	timestamp INTEGER NOT NULL,
+	metric    TEXT    NOT NULL,
+	instance  TEXT    NOT NULL,
+	value     REAL    NOT NULL,
+	unit      TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS metrics_metric_timestamp ON metrics (metric, timestamp);
+`
+
+// MetricStore keeps the alert history and recent metric values in a SQLite database
+type MetricStore struct {
+	db *sql.DB
+}
+
+// AlertRecord is an alert event read back from the MetricStore
+type AlertRecord struct {
+	Timestamp  time.Time  `json:"timestamp"`
+	Metric     string     `json:"metric"`
+	Value      float64    `json:"value"`
+	Threshold  float64    `json:"threshold"`
+	Severity   string     `json:"severity"`
+	Message    string     `json:"message"`
+	Notified   bool       `json:"notified"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	Status     string     `json:"status"` // AlertStatusSent or AlertStatusFailed
+}
+
+// MetricSample is a collected metric value read back from the MetricStore
+type MetricSample struct {
+	Timestamp time.Time
+	Instance  string // e.g. the core of cpu_usage; empty for single-value metrics
+	Value     float64
+	Unit      string
+}
+
+// OpenMetricStore opens (and if needed creates) the SQLite history database at path
+func OpenMetricStore(path string) (*MetricStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open history database: %w", err)
+	}
+	// SQLite allows a single writer; serialize access instead of failing with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(metricStoreSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create history tables: %w", err)
+	}
+	// Databases created before delivery statuses were recorded lack the status column
+	if _, err := db.Exec(`ALTER TABLE alerts ADD COLUMN status TEXT NOT NULL DEFAULT 'sent'`); err != nil &&
+		!strings.Contains(err.Error(), "duplicate column") {
+		db.Close()
+		return nil, fmt.Errorf("could not create history tables: %w", err)
+	}
+	return &MetricStore{db: db}, nil
+}
+
+// Close closes the database
+func (s *MetricStore) Close() error {
+	return s.db.Close()
+}
+
+// RecordAlert stores an alert event with the delivery status of its notification
+func (s *MetricStore) RecordAlert(at time.Time, alert AlertEntry, status string) error {
+	_, err := s.db.Exec(
+		`INSERT INTO alerts (timestamp, metric, value, threshold, severity, message, notified, status) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		at.UnixMilli(), alert.Metric, alert.Value, alert.Threshold, string(alertSeverity(alert)), alert.Message, status == AlertStatusSent, status)
+	if err != nil {
+		return fmt.Errorf("could not record alert: %w", err)
+	}
+	return nil
+}
+
+// ResolveAlerts marks the open alerts of every collected metric that is not in active as resolved
+func (s *MetricStore) ResolveAlerts(at time.Time, active []AlertEntry, collected MetricSet) error {
+	rows, err := s.db.Query(`SELECT DISTINCT metric FROM alerts WHERE resolved_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("could not resolve alerts: %w", err)
+	}
+	var open []string
+	for rows.Next() {
+		var metric string
+		if err := rows.Scan(&metric); err != nil {
+			rows.Close()
+			return fmt.Errorf("could not resolve alerts: %w", err)
+		}
+		open = append(open, metric)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not resolve alerts: %w", err)
+	}
+
+	breaching := make(map[string]bool, len(active))
+	for _, alert := range active {
+		breaching[alert.Metric] = true
+	}
+	var resolved []any
+	for _, metric := range open {
+		if !breaching[metric] && collected.Covers(metric) {
+			resolved = append(resolved, metric)
+		}
+	}
+	if len(resolved) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(resolved)), ", ")
+	args := append([]any{at.UnixMilli()}, resolved...)
+	if _, err := s.db.Exec(`UPDATE alerts SET resolved_at = ? WHERE resolved_at IS NULL AND metric IN (`+placeholders+`)`, args...); err != nil {
+		return fmt.Errorf("could not resolve alerts: %w", err)
+	}
+	return nil
+}
+
+// QueryAlerts returns the alert events raised in [from, to), oldest first
+func (s *MetricStore) QueryAlerts(from, to time.Time) ([]AlertRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT timestamp, metric, value, threshold, severity, message, notified, resolved_at, status
+		 FROM alerts WHERE timestamp >= ? AND timestamp < ? ORDER BY timestamp, id`,
+		from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("could not query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var records []AlertRecord
+	for rows.Next() {
+		var (
+			record     AlertRecord
+			timestamp  int64
+			resolvedAt sql.NullInt64
+		)
+		if err := rows.Scan(&timestamp, &record.Metric, &record.Value, &record.Threshold,
+			&record.Severity, &record.Message, &record.Notified, &resolvedAt, &record.Status); err != nil {
+			return nil, fmt.Errorf("could not read alert: %w", err)
+		}
+		record.Timestamp = time.UnixMilli(timestamp)
+		if resolvedAt.Valid {
+			t := time.UnixMilli(resolvedAt.Int64)
+			record.ResolvedAt = &t
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// RecordMetrics stores the metric values of a collection cycle and drops values older than metricRetention
+func (s *MetricStore) RecordMetrics(at time.Time, points []MetricPoint) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not record metrics: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO metrics (timestamp, metric, instance, value, unit) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("could not record metrics: %w", err)
+	}
+	defer stmt.Close()
+	for _, point := range points {
+		if _, err := stmt.Exec(at.UnixMilli(), point.Name, point.Labels["instance"], point.Value, point.Unit); err != nil {
+			return fmt.Errorf("could not record metrics: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM metrics WHERE timestamp < ?`, at.Add(-metricRetention).UnixMilli()); err != nil {
+		return fmt.Errorf("could not prune metrics: %w", err)
+	}
+	return tx.Commit()
+}
+
+// QueryMetric returns the values of metric collected in [from, to), oldest first
+func (s *MetricStore) QueryMetric(metric string, from, to time.Time) ([]MetricSample, error) {
+	rows, err := s.db.Query(
+		`SELECT timestamp, instance, value, unit FROM metrics
+		 WHERE metric = ? AND timestamp >= ? AND timestamp < ? ORDER BY timestamp, instance`,
+		metric, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("could not query metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []MetricSample
+	for rows.Next() {
+		var (
+			sample    MetricSample
+			timestamp int64
+		)
+		if err := rows.Scan(&timestamp, &sample.Instance, &sample.Value, &sample.Unit); err != nil {
+			return nil, fmt.Errorf("could not read metric: %w", err)
+		}
+		sample.Timestamp = time.UnixMilli(timestamp)
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
@@ -0,0 +1,19 @@
+package monitor
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// sysfsRoot is the mount point of sysfs. Tests point it at a fake tree.
+var sysfsRoot = "/sys"
+
+// readSysfsUint reads a single unsigned integer value from a sysfs file
+func readSysfsUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
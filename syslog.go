@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// syslogInfo is the syslog severity of recovery events
+const syslogInfo = 6
+
+// SyslogConfig describes where alert events are forwarded as syslog messages.
+// Leaving Network and Address empty logs to the local syslog daemon (/dev/log).
+type SyslogConfig struct {
+	Network  string `json:"network"`  // tcp or udp for a remote server
+	Address  string `json:"address"`  // e.g. "siem.example.com:514"
+	Facility string `json:"facility"` // e.g. daemon, local0 ... local7; defaults to daemon
+	Priority *int   `json:"priority"` // syslog severity 0 (emerg) to 7 (debug); defaults to 4 (warning)
+	Tag      string `json:"tag"`      // defaults to go-system-monitor
+}
+
+// validateSyslogConfig checks that the syslog priority is a severity the syslog protocol knows
+func validateSyslogConfig(cfg *SyslogConfig) error {
+	if cfg == nil || cfg.Priority == nil {
+		return nil
+	}
+	if *cfg.Priority < 0 || *cfg.Priority > 7 {
+		return fmt.Errorf("invalid syslog priority %d: must be 0 (emerg) to 7 (debug)", *cfg.Priority)
+	}
+	return nil
+}
+
+// SyslogRecoveryForwarder sends a syslog message when a metric that alerted is back within its threshold
+type SyslogRecoveryForwarder struct {
+	config    SyslogConfig
+	mu        sync.Mutex
+	breaching map[string]bool // metrics that alerted in an earlier check
+}
+
+// NewSyslogRecoveryForwarder creates a forwarder for the given syslog target
+func NewSyslogRecoveryForwarder(config SyslogConfig) *SyslogRecoveryForwarder {
+	return &SyslogRecoveryForwarder{config: config, breaching: make(map[string]bool)}
+}
+
+// Forward remembers the breaching metrics of alerts and sends a recovery event, at info severity,
+// for every collected metric that breached before and no longer does
+func (f *SyslogRecoveryForwarder) Forward(alerts []AlertEntry, collected MetricSet) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	breaching := make(map[string]bool, len(alerts))
+	for _, alert := range alerts {
+		breaching[alert.Metric] = true
+		f.breaching[alert.Metric] = true
+	}
+
+	var recovered []string
+	for metric := range f.breaching {
+		if !breaching[metric] && collected.Covers(metric) {
+			recovered = append(recovered, metric)
+		}
+	}
+	sort.Strings(recovered)
+
+	info := syslogInfo
+	config := f.config
+	config.Priority = &info
+	var errs []error
+	for _, metric := range recovered {
+		if err := ForwardToSyslog(config, fmt.Sprintf("Recovered: %s is back within its threshold", metric)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(f.breaching, metric)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package monitor
+
+import "fmt"
+
+// ForwardToSyslog is not supported on this platform
+func ForwardToSyslog(cfg SyslogConfig, message string) error {
+	return fmt.Errorf("syslog forwarding is not supported on this platform")
+}
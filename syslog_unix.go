@@ -0,0 +1,60 @@
+//go:build !windows && !plan9
+
+package monitor
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+)
+
+// syslogFacilities maps config facility names to log/syslog facilities
+var syslogFacilities = map[string]syslog.Priority{
+	"":       syslog.LOG_DAEMON,
+	"kern":   syslog.LOG_KERN,
+	"user":   syslog.LOG_USER,
+	"daemon": syslog.LOG_DAEMON,
+	"auth":   syslog.LOG_AUTH,
+	"syslog": syslog.LOG_SYSLOG,
+	"local0": syslog.LOG_LOCAL0,
+	"local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4,
+	"local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6,
+	"local7": syslog.LOG_LOCAL7,
+}
+
+// ForwardToSyslog sends message to the local or remote syslog target described by cfg
+func ForwardToSyslog(cfg SyslogConfig, message string) error {
+	facility, ok := syslogFacilities[strings.ToLower(cfg.Facility)]
+	if !ok {
+		return fmt.Errorf("unknown syslog facility %q", cfg.Facility)
+	}
+	if err := validateSyslogConfig(&cfg); err != nil {
+		return err
+	}
+	severity := syslog.LOG_WARNING
+	if cfg.Priority != nil {
+		severity = syslog.Priority(*cfg.Priority)
+	}
+	tag := cfg.Tag
+	if tag == "" {
+		tag = "go-system-monitor"
+	}
+
+	// An empty network and address dial the local syslog socket
+	writer, err := syslog.Dial(cfg.Network, cfg.Address, facility|severity, tag)
+	if err != nil {
+		return fmt.Errorf("Error connecting to syslog: %w", err)
+	}
+	defer writer.Close()
+
+	// Syslog messages are single lines
+	message = strings.ReplaceAll(strings.TrimSpace(message), "\n", " | ")
+	if _, err := writer.Write([]byte(message)); err != nil {
+		return fmt.Errorf("Error writing to syslog: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// CPUInfoProvider reads CPU information and usage; tests replace cpuProvider with a mock
+type CPUInfoProvider interface {
+	Info(ctx context.Context) ([]cpu.InfoStat, error)
+	Percent(ctx context.Context, interval time.Duration, percpu bool) ([]float64, error)
+}
+
+// MemoryProvider reads the virtual memory statistics
+type MemoryProvider interface {
+	VirtualMemory(ctx context.Context) (*mem.VirtualMemoryStat, error)
+}
+
+// DiskProvider reads filesystem usage
+type DiskProvider interface {
+	Usage(ctx context.Context, path string) (*disk.UsageStat, error)
+}
+
+// gopsutilProvider implements the providers with gopsutil
+type gopsutilProvider struct{}
+
+func (gopsutilProvider) Info(ctx context.Context) ([]cpu.InfoStat, error) {
+	return cpu.InfoWithContext(ctx)
+}
+
+func (gopsutilProvider) Percent(ctx context.Context, interval time.Duration, percpu bool) ([]float64, error) {
+	return cpu.PercentWithContext(ctx, interval, percpu)
+}
+
+func (gopsutilProvider) VirtualMemory(ctx context.Context) (*mem.VirtualMemoryStat, error) {
+	return mem.VirtualMemoryWithContext(ctx)
+}
+
+func (gopsutilProvider) Usage(ctx context.Context, path string) (*disk.UsageStat, error) {
+	return disk.UsageWithContext(ctx, path)
+}
+
+// The sources of the CPU, memory and disk metrics
+var (
+	cpuProvider    CPUInfoProvider = gopsutilProvider{}
+	memoryProvider MemoryProvider  = gopsutilProvider{}
+	diskProvider   DiskProvider    = gopsutilProvider{}
+)
+
+// GetCPUUsage returns the usage of each CPU core in percent since the previous call
+func GetCPUUsage(ctx context.Context) ([]float64, error) {
+	usage, err := cpuProvider.Percent(ctx, 0, true)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching CPU usage: %w", err)
+	}
+	return usage, nil
+}
+
+// GetMemoryStats returns the virtual memory statistics
+func GetMemoryStats(ctx context.Context) (*mem.VirtualMemoryStat, error) {
+	stats, err := memoryProvider.VirtualMemory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching memory stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetDiskUsage returns the usage of the filesystem mounted at path
+func GetDiskUsage(ctx context.Context, path string) (*disk.UsageStat, error) {
+	stats, err := diskProvider.Usage(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching disk usage: %w", err)
+	}
+	return stats, nil
+}
+
+// CheckCPUUsage collects the usage of each core and alerts on cores above threshold percent
+func CheckCPUUsage(ctx context.Context, threshold float64) ([]float64, []AlertEntry, error) {
+	usage, err := GetCPUUsage(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return usage, cpuUsageAlerts(usage, threshold), nil
+}
+
+// cpuUsageAlerts alerts on the cores whose usage is above threshold percent
+func cpuUsageAlerts(usage []float64, threshold float64) []AlertEntry {
+	var alerts []AlertEntry
+	for i, percent := range usage {
+		if percent > threshold {
+			alerts = append(alerts, AlertEntry{
+				Metric:    "cpu_usage",
+				Value:     percent,
+				Threshold: threshold,
+				Message:   fmt.Sprintf("Alert: CPU Core %d usage is above %.0f%%: %.2f%%", i, threshold, percent),
+			})
+		} else {
+			fmt.Fprintf(StatusOutput, "CPU Core %d usage: %.2f%% (Safe)\n", i, percent)
+		}
+	}
+	return alerts
+}
+
+// CheckMemoryUsage collects the memory usage and alerts when it is above threshold percent
+func CheckMemoryUsage(ctx context.Context, threshold float64) (float64, []AlertEntry, error) {
+	stats, err := GetMemoryStats(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	return stats.UsedPercent, memoryUsageAlerts(stats.UsedPercent, threshold), nil
+}
+
+// memoryUsageAlerts alerts when the memory usage is above threshold percent
+func memoryUsageAlerts(usedPercent, threshold float64) []AlertEntry {
+	if usedPercent > threshold {
+		return []AlertEntry{{
+			Metric:    "memory",
+			Value:     usedPercent,
+			Threshold: threshold,
+			Message:   fmt.Sprintf("Alert: Memory usage is above %.0f%%: %.2f%%", threshold, usedPercent),
+		}}
+	}
+	fmt.Fprintf(StatusOutput, "Memory usage: %.2f%% (Safe)\n", usedPercent)
+	return nil
+}
+
+// CheckDiskUsage collects the usage of the filesystem at path and alerts when it is above threshold percent
+func CheckDiskUsage(ctx context.Context, path string, threshold float64) (float64, []AlertEntry, error) {
+	stats, err := GetDiskUsage(ctx, path)
+	if err != nil {
+		return 0, nil, err
+	}
+	return stats.UsedPercent, diskUsageAlerts(stats.UsedPercent, threshold), nil
+}
+
+// diskUsageAlerts alerts when the disk usage is above threshold percent
+func diskUsageAlerts(usedPercent, threshold float64) []AlertEntry {
+	if usedPercent > threshold {
+		return []AlertEntry{{
+			Metric:    "disk",
+			Value:     usedPercent,
+			Threshold: threshold,
+			Message:   fmt.Sprintf("Alert: Disk usage is above %.0f%%: %.2f%%", threshold, usedPercent),
+		}}
+	}
+	fmt.Fprintf(StatusOutput, "Disk usage: %.2f%% (Safe)\n", usedPercent)
+	return nil
+}
@@ -0,0 +1,187 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+var errMockCollector = errors.New("mock collector failure")
+
+// mockCPU returns fixed per-core usage values
+type mockCPU struct {
+	usage []float64
+	err   error
+}
+
+func (m mockCPU) Info(ctx context.Context) ([]cpu.InfoStat, error) {
+	return nil, m.err
+}
+
+func (m mockCPU) Percent(ctx context.Context, interval time.Duration, percpu bool) ([]float64, error) {
+	return m.usage, m.err
+}
+
+// mockMemory returns a fixed memory usage
+type mockMemory struct {
+	usedPercent float64
+	err         error
+}
+
+func (m mockMemory) VirtualMemory(ctx context.Context) (*mem.VirtualMemoryStat, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &mem.VirtualMemoryStat{UsedPercent: m.usedPercent}, nil
+}
+
+// mockDisk returns a fixed filesystem usage
+type mockDisk struct {
+	usedPercent float64
+	err         error
+}
+
+func (m mockDisk) Usage(ctx context.Context, path string) (*disk.UsageStat, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &disk.UsageStat{Path: path, UsedPercent: m.usedPercent}, nil
+}
+
+// useProviders replaces the metric providers for the duration of the test
+func useProviders(t *testing.T, c CPUInfoProvider, m MemoryProvider, d DiskProvider) {
+	t.Helper()
+
+	origCPU, origMemory, origDisk, origOutput := cpuProvider, memoryProvider, diskProvider, StatusOutput
+	cpuProvider, memoryProvider, diskProvider, StatusOutput = c, m, d, io.Discard
+	t.Cleanup(func() {
+		cpuProvider, memoryProvider, diskProvider, StatusOutput = origCPU, origMemory, origDisk, origOutput
+	})
+}
+
+func TestCPUAlertTriggered(t *testing.T) {
+	useProviders(t, mockCPU{usage: []float64{10, 80.01, 99}}, nil, nil)
+
+	usage, alerts, err := CheckCPUUsage(context.Background(), 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 3 {
+		t.Errorf("expected the usage of 3 cores, got %v", usage)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("expected alerts for 2 cores, got %v", alerts)
+	}
+	if alerts[0].Metric != "cpu_usage" || alerts[0].Value != 80.01 || alerts[0].Threshold != 80 {
+		t.Errorf("unexpected alert %+v", alerts[0])
+	}
+}
+
+func TestCPUAlertNotTriggered(t *testing.T) {
+	// A core exactly at the threshold is still safe
+	useProviders(t, mockCPU{usage: []float64{0, 80}}, nil, nil)
+
+	_, alerts, err := CheckCPUUsage(context.Background(), 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+}
+
+func TestCPUUsageError(t *testing.T) {
+	useProviders(t, mockCPU{err: errMockCollector}, nil, nil)
+
+	_, alerts, err := CheckCPUUsage(context.Background(), 80)
+	if !errors.Is(err, errMockCollector) {
+		t.Fatalf("expected the collector error, got %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("expected no alerts on error, got %v", alerts)
+	}
+}
+
+func TestMemoryAlertTriggered(t *testing.T) {
+	useProviders(t, nil, mockMemory{usedPercent: 80.5}, nil)
+
+	used, alerts, err := CheckMemoryUsage(context.Background(), 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used != 80.5 {
+		t.Errorf("expected 80.5%% used, got %v", used)
+	}
+	if len(alerts) != 1 || alerts[0].Metric != "memory" || alerts[0].Threshold != 80 {
+		t.Errorf("expected a memory alert, got %v", alerts)
+	}
+}
+
+func TestMemoryAlertNotTriggered(t *testing.T) {
+	useProviders(t, nil, mockMemory{usedPercent: 80}, nil)
+
+	_, alerts, err := CheckMemoryUsage(context.Background(), 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+}
+
+func TestMemoryStatsError(t *testing.T) {
+	useProviders(t, nil, mockMemory{err: errMockCollector}, nil)
+
+	if _, _, err := CheckMemoryUsage(context.Background(), 80); !errors.Is(err, errMockCollector) {
+		t.Fatalf("expected the collector error, got %v", err)
+	}
+}
+
+func TestDiskAlertTriggered(t *testing.T) {
+	useProviders(t, nil, nil, mockDisk{usedPercent: 100})
+
+	used, alerts, err := CheckDiskUsage(context.Background(), "/", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used != 100 {
+		t.Errorf("expected 100%% used, got %v", used)
+	}
+	if len(alerts) != 1 || alerts[0].Metric != "disk" || alerts[0].Threshold != 50 {
+		t.Errorf("expected a disk alert, got %v", alerts)
+	}
+}
+
+func TestDiskAlertNotTriggered(t *testing.T) {
+	useProviders(t, nil, nil, mockDisk{usedPercent: 50})
+
+	_, alerts, err := CheckDiskUsage(context.Background(), "/", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+}
+
+func TestDiskUsageError(t *testing.T) {
+	useProviders(t, nil, nil, mockDisk{err: errMockCollector})
+
+	if _, _, err := CheckDiskUsage(context.Background(), "/", 50); !errors.Is(err, errMockCollector) {
+		t.Fatalf("expected the collector error, got %v", err)
+	}
+}
+
+func TestCPUInfoFrequencyError(t *testing.T) {
+	useProviders(t, mockCPU{err: errMockCollector}, nil, nil)
+
+	if _, err := getCPUInfoFrequency(context.Background()); !errors.Is(err, errMockCollector) {
+		t.Fatalf("expected the collector error, got %v", err)
+	}
+}
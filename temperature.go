@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TempUnit is the unit temperatures are reported in. Readings and snapshots are kept in
+// Celsius and only converted for output and alerts; the empty unit is Celsius.
+type TempUnit string
+
+// Temperature units of the temperature_unit config
+const (
+	Celsius    TempUnit = "celsius"
+	Fahrenheit TempUnit = "fahrenheit"
+	Kelvin     TempUnit = "kelvin"
+)
+
+// ConvertTemp converts a temperature between units
+func ConvertTemp(value float64, from, to TempUnit) float64 {
+	if from == to {
+		return value
+	}
+
+	// Convert to Celsius first
+	celsius := value
+	switch from {
+	case Fahrenheit:
+		celsius = (value - 32) * 5 / 9
+	case Kelvin:
+		celsius = value - 273.15
+	}
+
+	switch to {
+	case Fahrenheit:
+		return celsius*9/5 + 32
+	case Kelvin:
+		return celsius + 273.15
+	default:
+		return celsius
+	}
+}
+
+// FromCelsius converts a temperature in Celsius to u
+func (u TempUnit) FromCelsius(celsius float64) float64 {
+	return ConvertTemp(celsius, Celsius, u)
+}
+
+// Symbol returns the suffix of temperatures in u: °C, °F or K
+func (u TempUnit) Symbol() string {
+	switch u {
+	case Fahrenheit:
+		return "°F"
+	case Kelvin:
+		return "K"
+	default:
+		return "°C"
+	}
+}
+
+// Format converts a temperature in Celsius to u and formats it with the given number of
+// decimals and the unit suffix, e.g. 185.00°F
+func (u TempUnit) Format(celsius float64, decimals int) string {
+	return strconv.FormatFloat(u.FromCelsius(celsius), 'f', decimals, 64) + u.Symbol()
+}
+
+// validateTempUnit checks the temperature_unit config
+func validateTempUnit(unit TempUnit) error {
+	switch unit {
+	case "", Celsius, Fahrenheit, Kelvin:
+		return nil
+	default:
+		return fmt.Errorf("invalid temperature_unit %q: must be celsius, fahrenheit or kelvin", unit)
+	}
+}
+
+// normalizeTemperatureThresholds converts the temperature thresholds of the config, which are
+// given in temperature_unit, to Celsius, the unit of the readings. Zero keeps disabling the alert.
+func (config *Config) normalizeTemperatureThresholds() {
+	toCelsius := func(threshold float64) float64 {
+		if threshold == 0 {
+			return 0
+		}
+		return ConvertTemp(threshold, config.TemperatureUnit, Celsius)
+	}
+	for i := range config.GPIOTempSensors {
+		config.GPIOTempSensors[i].MaxTempC = toCelsius(config.GPIOTempSensors[i].MaxTempC)
+	}
+	for i := range config.ThermalSensors {
+		config.ThermalSensors[i].MaxTempC = toCelsius(config.ThermalSensors[i].MaxTempC)
+	}
+	config.SocketMaxTempC = toCelsius(config.SocketMaxTempC)
+}
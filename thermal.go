@@ -0,0 +1,154 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ErrThermalSensorsNotAvailable is returned on systems without lm-sensors
+var ErrThermalSensorsNotAvailable = errors.New("thermal sensors are not available (install lm-sensors)")
+
+// ThermalSensorThreshold is a single entry of the thermal_sensors config. Pattern is matched
+// against the sensor names with path.Match, e.g. "coretemp-*/Core *" or "*/Tccd*".
+type ThermalSensorThreshold struct {
+	Pattern  string  `json:"pattern"`
+	MaxTempC float64 `json:"max_temp_c"`
+}
+
+// Matches reports whether the sensor name matches the pattern
+func (t ThermalSensorThreshold) Matches(name string) bool {
+	matched, _ := path.Match(t.Pattern, name)
+	return matched
+}
+
+// ThermalSensorStat is the reading of an lm-sensors temperature sensor
+type ThermalSensorStat struct {
+	Name     string  `json:"name"`
+	Celsius  float64 `json:"celsius"`
+	MaxTempC float64 `json:"max_temp_c,omitempty"` // of the first matching thermal_sensors entry
+}
+
+// thermalSensorThreshold returns the threshold of the first entry matching the sensor name
+func thermalSensorThreshold(thresholds []ThermalSensorThreshold, name string) (float64, bool) {
+	for _, threshold := range thresholds {
+		if threshold.Matches(name) {
+			return threshold.MaxTempC, true
+		}
+	}
+	return 0, false
+}
+
+// GetAllThermalSensors returns every temperature reported by 'sensors -j' in °C, keyed by
+// "<chip>/<sensor>", e.g. "coretemp-isa-0000/Package id 0" or "k10temp-pci-00c3/Tccd1"
+func GetAllThermalSensors(ctx context.Context) (map[string]float64, error) {
+	output, err := exec.CommandContext(ctx, "sensors", "-j").Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, ErrThermalSensorsNotAvailable
+		}
+		return nil, fmt.Errorf("Error fetching thermal sensors: %w", err)
+	}
+	return parseSensorsJSON(output)
+}
+
+// parseSensorsJSON reads the temperature inputs from the output of 'sensors -j':
+//
+//	{"coretemp-isa-0000": {"Adapter": "ISA adapter", "Core 0": {"temp2_input": 45.000, "temp2_max": 80.000}}}
+func parseSensorsJSON(output []byte) (map[string]float64, error) {
+	var chips map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(output, &chips); err != nil {
+		return nil, fmt.Errorf("Error parsing sensors output: %w", err)
+	}
+
+	temps := make(map[string]float64)
+	for chip, features := range chips {
+		for feature, raw := range features {
+			// Features are objects of subfeatures; "Adapter" is a string
+			var subfeatures map[string]float64
+			if json.Unmarshal(raw, &subfeatures) != nil {
+				continue
+			}
+			for name, value := range subfeatures {
+				if strings.HasPrefix(name, "temp") && strings.HasSuffix(name, "_input") {
+					temps[chip+"/"+feature] = value
+					break
+				}
+			}
+		}
+	}
+	return temps, nil
+}
+
+// sortedSensorNames returns the sensor names of temps in alphabetical order
+func sortedSensorNames(temps map[string]float64) []string {
+	names := make([]string, 0, len(temps))
+	for name := range temps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cpuSensorChips are the lm-sensors drivers of CPU packages; each chip instance is one socket
+var cpuSensorChips = []string{"coretemp-", "k10temp-", "zenpower-"}
+
+// GetTemperatureBySocket returns the hottest temperature of each CPU socket in °C, read with
+// 'sensors -j'. Sockets are numbered by the bus address of their chip, which for Intel
+// coretemp matches the "Package id" of the socket.
+func GetTemperatureBySocket(ctx context.Context) (map[int]float64, error) {
+	temps, err := GetAllThermalSensors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return groupTemperaturesBySocket(temps), nil
+}
+
+// groupTemperaturesBySocket returns the hottest reading of each CPU chip, numbered in the
+// order of the chip names, e.g. coretemp-isa-0000 is socket 0 and coretemp-isa-0001 socket 1
+func groupTemperaturesBySocket(temps map[string]float64) map[int]float64 {
+	sockets := make(map[int]float64)
+	socket := -1
+	lastChip := ""
+	for _, name := range sortedSensorNames(temps) {
+		chip, _, _ := strings.Cut(name, "/")
+		if !isCPUSensorChip(chip) {
+			continue
+		}
+		if chip != lastChip {
+			socket++
+			lastChip = chip
+			sockets[socket] = temps[name]
+		}
+		sockets[socket] = max(sockets[socket], temps[name])
+	}
+	return sockets
+}
+
+// isCPUSensorChip reports whether the lm-sensors chip measures a CPU package
+func isCPUSensorChip(chip string) bool {
+	for _, prefix := range cpuSensorChips {
+		if strings.HasPrefix(chip, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedSockets returns the socket numbers of temps in ascending order
+func sortedSockets(temps map[int]float64) []int {
+	sockets := make([]int, 0, len(temps))
+	for socket := range temps {
+		sockets = append(sockets, socket)
+	}
+	sort.Ints(sockets)
+	return sockets
+}
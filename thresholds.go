@@ -0,0 +1,57 @@
+package monitor
+
+import "fmt"
+
+// Thresholds are the usage limits that depend on what the machine is used for.
+// A zero value in the thresholds config keeps the default of the machine class.
+type Thresholds struct {
+	CPUUsage    float64 `json:"cpu_usage"`    // Max CPU usage per core in %
+	MemoryUsage float64 `json:"memory_usage"` // Max memory usage in %
+	DiskUsage   float64 `json:"disk_usage"`   // Max disk usage in %
+}
+
+// Default thresholds per machine_class:
+//
+//	class      cpu_usage  memory_usage  disk_usage
+//	(none)        80%         80%          50%
+//	web           70%         80%          80%    latency-sensitive, sustained CPU load means requests queue up
+//	database      90%         90%          85%    buffer pools use most of the memory and CPU bursts during queries are normal
+//	batch         95%         85%          85%    jobs are expected to saturate the CPU
+var classThresholds = map[string]Thresholds{
+	"":         {CPUUsage: 80, MemoryUsage: 80, DiskUsage: 50},
+	"web":      {CPUUsage: 70, MemoryUsage: 80, DiskUsage: 80},
+	"database": {CPUUsage: 90, MemoryUsage: 90, DiskUsage: 85},
+	"batch":    {CPUUsage: 95, MemoryUsage: 85, DiskUsage: 85},
+}
+
+// GetDefaultThresholds returns the default thresholds of a machine class, or the
+// generic defaults for an unknown class
+func GetDefaultThresholds(class string) Thresholds {
+	if thresholds, ok := classThresholds[class]; ok {
+		return thresholds
+	}
+	return classThresholds[""]
+}
+
+// EffectiveThresholds returns the class defaults with the thresholds set in the config applied on top
+func (config Config) EffectiveThresholds() Thresholds {
+	thresholds := GetDefaultThresholds(config.MachineClass)
+	if config.Thresholds.CPUUsage > 0 {
+		thresholds.CPUUsage = config.Thresholds.CPUUsage
+	}
+	if config.Thresholds.MemoryUsage > 0 {
+		thresholds.MemoryUsage = config.Thresholds.MemoryUsage
+	}
+	if config.Thresholds.DiskUsage > 0 {
+		thresholds.DiskUsage = config.Thresholds.DiskUsage
+	}
+	return thresholds
+}
+
+// validateMachineClass rejects machine classes without defaults, which are most likely typos
+func validateMachineClass(class string) error {
+	if _, ok := classThresholds[class]; !ok {
+		return fmt.Errorf("unknown machine_class %q (expected web, database or batch)", class)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// throttleSampleInterval is the time between the two counter readings used to compute the event rate
+const throttleSampleInterval = 1 * time.Second
+
+// ErrThrottleNotAvailable is returned when the kernel does not expose thermal throttle counters
+var ErrThrottleNotAvailable = errors.New("CPU thermal throttle counters are not available on this system")
+
+// ThrottleStat holds the thermal throttling activity of a single CPU core
+type ThrottleStat struct {
+	CPU          int     `json:"cpu"`
+	EventsPerSec float64 `json:"events_per_sec"`
+	TotalCount   uint64  `json:"total_count"` // cumulative throttle count since boot
+}
+
+// GetThrottleEvents reads the per-core thermal throttle counters twice and returns the throttle event rate of each core
+func GetThrottleEvents(ctx context.Context) ([]ThrottleStat, error) {
+	paths, err := filepath.Glob(filepath.Join(sysfsRoot, "devices", "system", "cpu", "cpu[0-9]*", "thermal_throttle", "core_throttle_count"))
+	if err != nil {
+		return nil, fmt.Errorf("Error listing thermal throttle counters: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, ErrThrottleNotAvailable
+	}
+
+	before := make([]uint64, len(paths))
+	for i, path := range paths {
+		before[i], err = readSysfsUint(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading thermal throttle counter: %w", err)
+		}
+	}
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(throttleSampleInterval):
+	}
+
+	elapsed := time.Since(start).Seconds()
+	stats := make([]ThrottleStat, 0, len(paths))
+	for i, path := range paths {
+		after, err := readSysfsUint(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading thermal throttle counter: %w", err)
+		}
+
+		// path is /sys/devices/system/cpu/cpuN/thermal_throttle/core_throttle_count
+		cpuDir := filepath.Base(filepath.Dir(filepath.Dir(path)))
+		cpuIndex, err := strconv.Atoi(strings.TrimPrefix(cpuDir, "cpu"))
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing CPU index from %s: %w", path, err)
+		}
+
+		var events uint64
+		if after > before[i] {
+			events = after - before[i]
+		}
+		stats = append(stats, ThrottleStat{
+			CPU:          cpuIndex,
+			EventsPerSec: float64(events) / elapsed,
+			TotalCount:   after,
+		})
+	}
+
+	// Glob sorts lexically (cpu10 before cpu2)
+	sort.Slice(stats, func(i, j int) bool { return stats[i].CPU < stats[j].CPU })
+	return stats, nil
+}
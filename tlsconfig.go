@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSClientConfig holds the certificate files used to connect to a TLS-protected service
+type TLSClientConfig struct {
+	CAFile             string `json:"ca_file"`   // CA bundle to verify the server; empty uses the system roots
+	CertFile           string `json:"cert_file"` // client certificate for mutual TLS
+	KeyFile            string `json:"key_file"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+// Enabled reports whether any TLS setting is configured
+func (c TLSClientConfig) Enabled() bool {
+	return c.CAFile != "" || c.CertFile != "" || c.InsecureSkipVerify
+}
+
+// Load builds a tls.Config from the configured files
+func (c TLSClientConfig) Load() (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrTmpfsNotAvailable is returned on systems without /proc/mounts
+var ErrTmpfsNotAvailable = errors.New("tmpfs usage is not available on this system")
+
+// procMountsPath lists the mounted filesystems. Tests point it at a fake file.
+var procMountsPath = "/proc/mounts"
+
+// TmpfsStat holds the usage of a single tmpfs mount
+type TmpfsStat struct {
+	Path        string  `json:"path"`
+	UsedBytes   uint64  `json:"used_bytes"`
+	TotalBytes  uint64  `json:"total_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// parseTmpfsMounts returns the mount points of the tmpfs entries of a /proc/mounts file
+func parseTmpfsMounts(data string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[2] != "tmpfs" {
+			continue
+		}
+		// Only the last of stacked mounts is visible, and statfs reports it for every entry
+		path := unescapeMountPath(fields[1])
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// unescapeMountPath decodes the octal escapes (e.g. \040 for a space) of /proc/mounts
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+4 <= len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+//go:build linux
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// GetTmpfsUsage returns the usage of every tmpfs mount, e.g. /tmp, /run and /dev/shm
+func GetTmpfsUsage() ([]TmpfsStat, error) {
+	data, err := os.ReadFile(procMountsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrTmpfsNotAvailable
+		}
+		return nil, fmt.Errorf("Error reading mounts: %w", err)
+	}
+
+	var stats []TmpfsStat
+	for _, path := range parseTmpfsMounts(string(data)) {
+		var fs syscall.Statfs_t
+		if err := syscall.Statfs(path, &fs); err != nil {
+			// e.g. /run/user/<uid> of other users
+			if os.IsPermission(err) {
+				continue
+			}
+			return nil, fmt.Errorf("Error fetching tmpfs usage of %s: %w", path, err)
+		}
+		total := fs.Blocks * uint64(fs.Bsize)
+		// A tmpfs mounted with size=0 has no limit
+		if total == 0 {
+			continue
+		}
+		used := (fs.Blocks - fs.Bfree) * uint64(fs.Bsize)
+		stats = append(stats, TmpfsStat{
+			Path:        path,
+			UsedBytes:   used,
+			TotalBytes:  total,
+			UsedPercent: float64(used) / float64(total) * 100,
+		})
+	}
+	return stats, nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package monitor
+
+// GetTmpfsUsage is only supported on Linux
+func GetTmpfsUsage() ([]TmpfsStat, error) {
+	return nil, ErrTmpfsNotAvailable
+}
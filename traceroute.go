@@ -0,0 +1,234 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	defaultTracerouteMaxHops = 30
+	tracerouteHopTimeout     = 2 * time.Second // time to wait for the reply of a single probe
+	tracerouteProbeData      = "go-system-monitor"
+)
+
+// TracerouteCheck is a single entry of the traceroute_checks config
+type TracerouteCheck struct {
+	Host         string `json:"host"`
+	MaxHops      int    `json:"max_hops"`       // defaults to 30
+	MaxExtraHops int    `json:"max_extra_hops"` // alert when the route is longer than the baseline by more than this
+	MaxHopRTTMs  int    `json:"max_hop_rtt_ms"` // alert when any hop answers slower than this
+	BaselineHops int    `json:"baseline_hops"`  // expected hop count; defaults to the hop count of the previous run
+}
+
+// HopResult is a single hop of a traced route. Address is empty when the hop did not answer.
+type HopResult struct {
+	TTL     int           `json:"ttl"`
+	Address string        `json:"address,omitempty"`
+	RTT     time.Duration `json:"rtt"`
+}
+
+// TracerouteStat holds the route to a host
+type TracerouteStat struct {
+	Host    string      `json:"host"`
+	Hops    []HopResult `json:"hops"`
+	Reached bool        `json:"reached"`         // whether the host itself answered within the max hops
+	Error   string      `json:"error,omitempty"` // set when the traceroute failed
+}
+
+// HopCount returns the number of hops to the host, or 0 when it was not reached
+func (s TracerouteStat) HopCount() int {
+	if !s.Reached {
+		return 0
+	}
+	return len(s.Hops)
+}
+
+// SlowestHop returns the answering hop with the highest round-trip time
+func (s TracerouteStat) SlowestHop() (HopResult, bool) {
+	var slowest HopResult
+	found := false
+	for _, hop := range s.Hops {
+		if hop.Address != "" && (!found || hop.RTT > slowest.RTT) {
+			slowest, found = hop, true
+		}
+	}
+	return slowest, found
+}
+
+// Traceroute sends ICMP echo requests to host with increasing TTL values and records the address
+// and round-trip time of every hop until the host answers or maxHops is reached. It needs a raw
+// ICMP socket: run as root or grant the binary CAP_NET_RAW.
+func Traceroute(ctx context.Context, host string, maxHops int, timeout time.Duration) ([]HopResult, error) {
+	if maxHops <= 0 {
+		maxHops = defaultTracerouteMaxHops
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("Error resolving %s: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("Error resolving %s: no addresses", host)
+	}
+	dst := addrs[0]
+	isIPv4 := dst.IP.To4() != nil
+
+	network, address := "ip4:icmp", "0.0.0.0"
+	var echoType, replyType, exceededType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply, ipv4.ICMPTypeTimeExceeded
+	if !isIPv4 {
+		network, address = "ip6:ipv6-icmp", "::"
+		echoType, replyType, exceededType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply, ipv6.ICMPTypeTimeExceeded
+	}
+	proto := echoType.Protocol()
+
+	conn, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return nil, fmt.Errorf("Error opening ICMP socket (run as root or grant CAP_NET_RAW): %w", err)
+		}
+		return nil, fmt.Errorf("Error opening ICMP socket: %w", err)
+	}
+	defer conn.Close()
+
+	id := os.Getpid() & 0xffff
+	var hops []HopResult
+	for ttl := 1; ttl <= maxHops; ttl++ {
+		if err := ctx.Err(); err != nil {
+			return hops, err
+		}
+		if isIPv4 {
+			err = conn.IPv4PacketConn().SetTTL(ttl)
+		} else {
+			err = conn.IPv6PacketConn().SetHopLimit(ttl)
+		}
+		if err != nil {
+			return hops, fmt.Errorf("Error setting the TTL: %w", err)
+		}
+
+		request := icmp.Message{Type: echoType, Body: &icmp.Echo{ID: id, Seq: ttl, Data: []byte(tracerouteProbeData)}}
+		packet, err := request.Marshal(nil)
+		if err != nil {
+			return hops, fmt.Errorf("Error encoding ICMP echo request: %w", err)
+		}
+
+		start := time.Now()
+		if _, err := conn.WriteTo(packet, &net.IPAddr{IP: dst.IP, Zone: dst.Zone}); err != nil {
+			return hops, fmt.Errorf("Error sending ICMP echo request to %s: %w", host, err)
+		}
+
+		hop, reached, err := readHopReply(ctx, conn, proto, replyType, exceededType, id, ttl, start, timeout)
+		if err != nil {
+			return hops, err
+		}
+		hops = append(hops, hop)
+		if reached {
+			return hops, nil
+		}
+	}
+	return hops, nil
+}
+
+// readHopReply waits for the reply to the probe with the given TTL, skipping the ICMP traffic of
+// other processes. A hop that does not answer within timeout is returned without an address.
+func readHopReply(ctx context.Context, conn *icmp.PacketConn, proto int, replyType, exceededType icmp.Type, id, ttl int, start time.Time, timeout time.Duration) (HopResult, bool, error) {
+	hop := HopResult{TTL: ttl}
+	deadline := start.Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return hop, false, fmt.Errorf("Error setting ICMP read deadline: %w", err)
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return hop, false, ctx.Err()
+			}
+			return hop, false, fmt.Errorf("Error reading ICMP reply: %w", err)
+		}
+		rtt := time.Since(start)
+
+		msg, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+		switch {
+		case msg.Type == replyType:
+			if echo, ok := msg.Body.(*icmp.Echo); ok && echo.ID == id && echo.Seq == ttl {
+				hop.Address, hop.RTT = peerIP(peer), rtt
+				return hop, true, nil
+			}
+		case msg.Type == exceededType:
+			if exceeded, ok := msg.Body.(*icmp.TimeExceeded); ok && isOwnProbe(exceeded.Data, proto, id, ttl) {
+				hop.Address, hop.RTT = peerIP(peer), rtt
+				return hop, false, nil
+			}
+		}
+	}
+}
+
+// isOwnProbe reports whether the original datagram quoted in a time exceeded message is the
+// echo request with the given id and sequence number
+func isOwnProbe(quoted []byte, proto, id, seq int) bool {
+	headerLen := ipv6.HeaderLen
+	if proto == ipv4.ICMPTypeEcho.Protocol() {
+		if len(quoted) < ipv4.HeaderLen {
+			return false
+		}
+		headerLen = int(quoted[0]&0x0f) << 2
+	}
+	// The quoted ICMP header is 8 bytes: type, code, checksum, identifier and sequence number
+	if len(quoted) < headerLen+8 {
+		return false
+	}
+	echo := quoted[headerLen:]
+	return int(echo[4])<<8|int(echo[5]) == id && int(echo[6])<<8|int(echo[7]) == seq
+}
+
+// peerIP returns the IP address of the sender of a reply
+func peerIP(addr net.Addr) string {
+	if ipAddr, ok := addr.(*net.IPAddr); ok {
+		return ipAddr.IP.String()
+	}
+	return addr.String()
+}
+
+// CheckTraceroute traces the route of a traceroute_checks entry
+func CheckTraceroute(ctx context.Context, check TracerouteCheck) (TracerouteStat, error) {
+	hops, err := Traceroute(ctx, check.Host, check.MaxHops, tracerouteHopTimeout)
+	stat := TracerouteStat{Host: check.Host, Hops: hops}
+	if len(hops) > 0 {
+		last := hops[len(hops)-1]
+		stat.Reached = last.Address != "" && isDestination(ctx, check.Host, last.Address)
+	}
+	if err != nil {
+		return stat, fmt.Errorf("Error tracing the route to %s: %w", check.Host, err)
+	}
+	return stat, nil
+}
+
+// isDestination reports whether address is one of the addresses of host
+func isDestination(ctx context.Context, host, address string) bool {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if addr.IP.String() == address {
+			return true
+		}
+	}
+	return false
+}
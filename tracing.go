@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans of the monitor
+const tracerName = "go-system-monitor"
+
+// InitTracing exports the spans of every collection cycle to the OTLP (gRPC) endpoint, e.g.
+// http://otel-collector:4317. The returned function flushes the pending spans. Without
+// InitTracing the spans are no-ops.
+func InitTracing(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("could not create OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(monitorResource()),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// monitorResource identifies this monitor instance in exported traces and metrics
+func monitorResource() *resource.Resource {
+	hostname, _ := os.Hostname()
+	return resource.NewSchemaless(
+		attribute.String("service.name", tracerName),
+		attribute.String("service.version", Version),
+		attribute.String("host.name", hostname),
+	)
+}
+
+// startCollectSpan starts the span of collecting a single metric, e.g. monitor.collect.cpu_usage
+func startCollectSpan(ctx context.Context, metric string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "monitor.collect."+metric)
+}
+
+// startCollectAllSpan starts the root span of a collection cycle of the given metrics
+func startCollectAllSpan(ctx context.Context, metrics MetricSet) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "monitor.collect_all",
+		trace.WithAttributes(attribute.String("metrics", metrics.String())))
+}
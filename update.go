@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// updateCheckURL returns the latest released version of this module from the Go module proxy
+const updateCheckURL = "https://proxy.golang.org/github.com/VedantamSravan/go-system-monitor/@latest"
+
+// updateCheckTimeout bounds the request to the module proxy
+const updateCheckTimeout = 5 * time.Second
+
+// CheckForUpdates asks the Go module proxy for the latest release and reports
+// whether it is newer than currentVersion
+func CheckForUpdates(currentVersion string) (string, bool, error) {
+	if !semver.IsValid(currentVersion) {
+		return "", false, fmt.Errorf("Error checking for updates: current version %q is not a semantic version", currentVersion)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, updateCheckURL, nil)
+	if err != nil {
+		return "", false, fmt.Errorf("Error creating update check request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent())
+
+	client := &http.Client{Timeout: updateCheckTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("Error querying module proxy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("Error querying module proxy: %s", resp.Status)
+	}
+
+	var latest struct {
+		Version string `json:"Version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return "", false, fmt.Errorf("Error parsing module proxy response: %w", err)
+	}
+
+	return latest.Version, semver.Compare(latest.Version, currentVersion) > 0, nil
+}
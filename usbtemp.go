@@ -0,0 +1,35 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GetUSBTemperatureSensor returns the ambient temperature in °C from a TEMPer USB
+// thermometer using the 'temper-poll' command (pip install temperusb)
+func GetUSBTemperatureSensor(ctx context.Context) (float64, error) {
+	// Run the 'temper-poll' command; -c prints only the temperature in °C
+	cmd := exec.CommandContext(ctx, "temper-poll", "-c")
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return 0, fmt.Errorf("Error fetching USB temperature: %w", err)
+	}
+
+	// With several devices attached, use the first reading
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("no USB temperature sensor found")
+	}
+
+	temp, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("Error parsing USB temperature: %w", err)
+	}
+	return temp, nil
+}
@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// VaultCheck is a single entry of the vault_checks config
+type VaultCheck struct {
+	Address            string `json:"address"` // e.g. https://vault1:8200; empty uses VAULT_ADDR
+	Token              string `json:"token"`   // token whose expiry is watched, e.g. the one used by the applications
+	MinTokenTTLSeconds int    `json:"min_token_ttl_seconds"`
+}
+
+// VaultStat holds the seal status of a Vault server and the remaining lifetime of the token
+type VaultStat struct {
+	Address     string        `json:"address"`
+	ClusterName string        `json:"cluster_name"`
+	ClusterID   string        `json:"cluster_id"`
+	Version     string        `json:"version"`
+	Initialized bool          `json:"initialized"`
+	Sealed      bool          `json:"sealed"`
+	Standby     bool          `json:"standby"`
+	TokenTTL    time.Duration `json:"token_ttl"` // 0 for tokens that never expire, like root tokens
+}
+
+// CheckVaultHealth reads the seal status from /v1/sys/health and the TTL of the configured
+// token from /v1/auth/token/lookup-self
+func CheckVaultHealth(ctx context.Context, cfg VaultCheck) (VaultStat, error) {
+	stat := VaultStat{Address: cfg.Address}
+
+	vaultConfig := api.DefaultConfig()
+	if cfg.Address != "" {
+		vaultConfig.Address = cfg.Address
+	}
+	vaultConfig.Timeout = serviceCheckTimeout
+	client, err := api.NewClient(vaultConfig)
+	if err != nil {
+		return stat, fmt.Errorf("Error creating Vault client: %w", err)
+	}
+	client.SetToken(cfg.Token)
+
+	ctx, cancel := context.WithTimeout(ctx, serviceCheckTimeout)
+	defer cancel()
+
+	health, err := client.Sys().HealthWithContext(ctx)
+	if err != nil {
+		return stat, fmt.Errorf("Error fetching Vault health: %w", err)
+	}
+	stat.ClusterName = health.ClusterName
+	stat.ClusterID = health.ClusterID
+	stat.Version = health.Version
+	stat.Initialized = health.Initialized
+	stat.Sealed = health.Sealed
+	stat.Standby = health.Standby
+
+	// A sealed Vault cannot look up tokens
+	if cfg.Token == "" || health.Sealed {
+		return stat, nil
+	}
+	secret, err := client.Auth().Token().LookupSelfWithContext(ctx)
+	if err != nil {
+		return stat, fmt.Errorf("Error looking up Vault token: %w", err)
+	}
+	stat.TokenTTL, err = secret.TokenTTL()
+	if err != nil {
+		return stat, fmt.Errorf("Error reading Vault token TTL: %w", err)
+	}
+	return stat, nil
+}
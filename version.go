@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, injected at build time like the Makefile does with
+//
+//	PKG=github.com/VedantamSravan/go-system-monitor
+//	go build -ldflags "-X $PKG.Version=$(git describe --tags) -X $PKG.Commit=$(git rev-parse --short HEAD) -X $PKG.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/go-system-monitor
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// String formats the build info for the --version flag
+func (b BuildInfo) String() string {
+	return fmt.Sprintf("go-system-monitor version %s (commit: %s, built: %s)", b.Version, b.Commit, b.BuildDate)
+}
+
+// GetBuildInfo returns the build metadata, falling back to the VCS revision
+// recorded by the Go toolchain when no commit was injected
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+
+	if info.Commit == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range bi.Settings {
+				if setting.Key == "vcs.revision" && len(setting.Value) >= 7 {
+					info.Commit = setting.Value[:7]
+				}
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+
+	return info
+}
+
+// userAgent is sent with every outbound HTTP request
+func userAgent() string {
+	return "go-system-monitor/" + Version
+}
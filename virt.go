@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// procCpuinfoPath describes the CPUs. Tests point it at a fake file.
+var procCpuinfoPath = "/proc/cpuinfo"
+
+// dockerEnvPath is created by Docker in the root of every container
+var dockerEnvPath = "/.dockerenv"
+
+// dmiHypervisors maps substrings of the DMI product name or system vendor to the hypervisor
+var dmiHypervisors = []struct {
+	Match      string
+	Hypervisor string
+}{
+	{"KVM", "kvm"},
+	{"QEMU", "kvm"},
+	{"VMware", "vmware"},
+	{"Xen", "xen"},
+	{"HVM domU", "xen"},
+	{"Microsoft Corporation", "hyper-v"},
+	{"VirtualBox", "virtualbox"},
+}
+
+// detectVirtNames maps the names printed by systemd-detect-virt where they differ
+var detectVirtNames = map[string]string{
+	"qemu":      "kvm",
+	"microsoft": "hyper-v",
+	"oracle":    "virtualbox",
+}
+
+// VirtInfo describes the virtualization the monitor runs under
+type VirtInfo struct {
+	IsVM           bool   `json:"is_vm"`                     // also true inside a container
+	HypervisorType string `json:"hypervisor_type,omitempty"` // kvm, vmware, xen, hyper-v, virtualbox or docker; empty if unknown
+}
+
+// DetectVirtualization finds out whether the machine is virtual from the hypervisor flag of
+// /proc/cpuinfo and the DMI product name, falling back to systemd-detect-virt to identify the
+// hypervisor. Docker containers are reported with the hypervisor type docker.
+func DetectVirtualization() (VirtInfo, error) {
+	if _, err := os.Stat(dockerEnvPath); err == nil {
+		return VirtInfo{IsVM: true, HypervisorType: "docker"}, nil
+	}
+
+	var info VirtInfo
+	checked := false
+	if flagged, err := cpuinfoHasHypervisorFlag(); err == nil {
+		info.IsVM = flagged
+		checked = true
+	}
+	if hypervisor, err := dmiHypervisor(); err == nil {
+		checked = true
+		if hypervisor != "" {
+			info.IsVM, info.HypervisorType = true, hypervisor
+		}
+	}
+	if info.HypervisorType != "" {
+		return info, nil
+	}
+
+	// The hypervisor flag only exists on x86 and DMI tables are missing on many ARM machines
+	hypervisor, err := systemdDetectVirt()
+	if err != nil {
+		if checked {
+			return info, nil
+		}
+		return info, fmt.Errorf("could not detect virtualization: %w", err)
+	}
+	if hypervisor != "" {
+		info.IsVM, info.HypervisorType = true, hypervisor
+	}
+	return info, nil
+}
+
+// cpuinfoHasHypervisorFlag reports whether the CPU flags include "hypervisor", which
+// x86 hypervisors set for their guests
+func cpuinfoHasHypervisorFlag() (bool, error) {
+	f, err := os.Open(procCpuinfoPath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(key) != "flags" {
+			continue
+		}
+		for _, flag := range strings.Fields(value) {
+			if flag == "hypervisor" {
+				return true, nil
+			}
+		}
+		// All CPUs have the same flags
+		return false, nil
+	}
+	return false, scanner.Err()
+}
+
+// dmiHypervisor identifies the hypervisor from the DMI product name and system vendor,
+// returning an empty string for physical machines
+func dmiHypervisor() (string, error) {
+	var names []string
+	for _, file := range []string{"product_name", "sys_vendor"} {
+		data, err := os.ReadFile(filepath.Join(sysfsRoot, "class", "dmi", "id", file))
+		if err != nil {
+			return "", err
+		}
+		names = append(names, strings.TrimSpace(string(data)))
+	}
+	for _, name := range names {
+		for _, known := range dmiHypervisors {
+			if strings.Contains(name, known.Match) {
+				return known.Hypervisor, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// systemdDetectVirt runs systemd-detect-virt, which returns an empty string on physical machines
+func systemdDetectVirt() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), serviceCheckTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "systemd-detect-virt").Output()
+	name := string(bytes.TrimSpace(output))
+
+	// It exits with status 1 when it prints "none"
+	var exitErr *exec.ExitError
+	if name == "none" && errors.As(err, &exitErr) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Error running systemd-detect-virt: %w", err)
+	}
+	if mapped, ok := detectVirtNames[name]; ok {
+		return mapped, nil
+	}
+	return name, nil
+}
@@ -0,0 +1,163 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	vulnScanTimeout  = 5 * time.Minute // trivy downloads its database on the first run
+	vulnScanCacheTTL = time.Hour       // an image is scanned at most once per hour
+	vulnScanMaxCVEs  = 10              // vulnerabilities listed per image in the alert
+)
+
+// OnCriticalAlert, when set, runs for every critical alert of a group before the group is
+// dispatched. The returned text is appended to the alert email body, which the hook has no other
+// way to reach; an error is logged and the alert is sent without it. Main sets it to the trivy
+// scan when enable_vuln_scan is on.
+var OnCriticalAlert func(ctx context.Context, alert AlertEntry) (string, error)
+
+// trivyReport holds the fields of 'trivy image --format json' used in the summary
+type trivyReport struct {
+	Results []struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// vulnScan is the scan of a single image, shared by the alerts waiting for it
+type vulnScan struct {
+	done      chan struct{} // closed when the scan has finished
+	summary   string
+	err       error
+	scannedAt time.Time
+}
+
+// vulnScans caches the scans per image, since a breaching metric alerts on every check. The lock
+// only guards the map; the scans run without it so one slow image does not hold back the others.
+var vulnScans = struct {
+	sync.Mutex
+	scans map[string]*vulnScan
+}{scans: make(map[string]*vulnScan)}
+
+// NewVulnScanHook returns an OnCriticalAlert hook that scans the container image of the alerting
+// metric with 'trivy image' and returns its high and critical vulnerabilities. images maps metric
+// names to images, e.g. {"redis": "redis:7.2"}; alerts of other metrics are not scanned.
+func NewVulnScanHook(images map[string]string) func(ctx context.Context, alert AlertEntry) (string, error) {
+	return func(ctx context.Context, alert AlertEntry) (string, error) {
+		name, _, _ := strings.Cut(alert.Metric, ".")
+		image, ok := images[name]
+		if !ok {
+			return "", nil
+		}
+
+		vulnScans.Lock()
+		scan := vulnScans.scans[image]
+		if scan == nil || scan.expired() {
+			// Scan the image; alerts of the same image arriving meanwhile wait for this scan
+			scan = &vulnScan{done: make(chan struct{})}
+			vulnScans.scans[image] = scan
+			vulnScans.Unlock()
+
+			scan.summary, scan.err = ScanImage(ctx, image)
+			scan.scannedAt = time.Now()
+			if scan.err != nil {
+				// Failed scans are not cached; the next alert tries again
+				vulnScans.Lock()
+				if vulnScans.scans[image] == scan {
+					delete(vulnScans.scans, image)
+				}
+				vulnScans.Unlock()
+			}
+			close(scan.done)
+			return scan.summary, scan.err
+		}
+		vulnScans.Unlock()
+
+		select {
+		case <-scan.done:
+			return scan.summary, scan.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// expired reports whether the scan has finished longer than vulnScanCacheTTL ago
+func (s *vulnScan) expired() bool {
+	select {
+	case <-s.done:
+		return time.Since(s.scannedAt) >= vulnScanCacheTTL
+	default:
+		return false
+	}
+}
+
+// ScanImage runs 'trivy image' on image and summarizes its HIGH and CRITICAL vulnerabilities
+func ScanImage(ctx context.Context, image string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, vulnScanTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "trivy", "image", "--quiet", "--format", "json",
+		"--severity", "HIGH,CRITICAL", image).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("Error scanning image %s: %w: %s", image, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("Error scanning image %s: %w", image, err)
+	}
+
+	var report trivyReport
+	if err := json.Unmarshal(output, &report); err != nil {
+		return "", fmt.Errorf("Error parsing trivy report of %s: %w", image, err)
+	}
+	return formatTrivyReport(image, report), nil
+}
+
+// formatTrivyReport summarizes the report, e.g.
+//
+//	Vulnerability scan of redis:7.2: 1 CRITICAL, 3 HIGH
+//	  CVE-2023-45853 (CRITICAL) zlib1g 1:1.2.13.dfsg-1, no fix
+func formatTrivyReport(image string, report trivyReport) string {
+	counts := make(map[string]int)
+	var lines []string
+	for _, result := range report.Results {
+		for _, vuln := range result.Vulnerabilities {
+			counts[vuln.Severity]++
+			if len(lines) >= vulnScanMaxCVEs {
+				continue
+			}
+			fix := "fixed in " + vuln.FixedVersion
+			if vuln.FixedVersion == "" {
+				fix = "no fix"
+			}
+			lines = append(lines, fmt.Sprintf("  %s (%s) %s %s, %s",
+				vuln.VulnerabilityID, vuln.Severity, vuln.PkgName, vuln.InstalledVersion, fix))
+		}
+	}
+	if len(counts) == 0 {
+		return fmt.Sprintf("Vulnerability scan of %s: no HIGH or CRITICAL vulnerabilities", image)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Vulnerability scan of %s: %d CRITICAL, %d HIGH", image, counts["CRITICAL"], counts["HIGH"])
+	for _, line := range lines {
+		b.WriteString("\n" + line)
+	}
+	if total := counts["CRITICAL"] + counts["HIGH"]; total > len(lines) {
+		fmt.Fprintf(&b, "\n  ... and %d more", total-len(lines))
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/daemon"
+)
+
+// notifySystemd sends a state like READY=1 to systemd. Outside of a systemd service
+// (no NOTIFY_SOCKET) it does nothing.
+func notifySystemd(state string) {
+	if _, err := daemon.SdNotify(false, state); err != nil {
+		log.Printf("Error notifying systemd: %v\n", err)
+	}
+}
+
+// watchdogInterval returns how often the systemd watchdog is notified: watchdog_interval_seconds,
+// or half of the service's WatchdogSec. It returns 0 when the watchdog is not enabled.
+func watchdogInterval(config Config) time.Duration {
+	if config.WatchdogInterval > 0 {
+		return time.Duration(config.WatchdogInterval) * time.Second
+	}
+	timeout, err := daemon.SdWatchdogEnabled(false)
+	if err != nil {
+		log.Printf("Error reading the systemd watchdog timeout: %v\n", err)
+		return 0
+	}
+	return timeout / 2
+}
+
+// StartWatchdog notifies the systemd watchdog every interval until ctx is done, so systemd
+// restarts the monitor if it hangs
+func StartWatchdog(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			notifySystemd(daemon.SdNotifyWatchdog)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}